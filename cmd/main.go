@@ -9,6 +9,26 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := receiver.Migrate(context.Background(), os.Args[2:]); err != nil {
+				log.Fatalf("Failed to migrate: %v", err)
+			}
+			return
+		case "replay":
+			if err := receiver.Replay(context.Background(), os.Args[2:]); err != nil {
+				log.Fatalf("Failed to replay: %v", err)
+			}
+			return
+		case "ddl":
+			if err := receiver.DumpDDL(os.Args[2:]); err != nil {
+				log.Fatalf("Failed to dump ddl: %v", err)
+			}
+			return
+		}
+	}
+
 	err := receiver.Run(context.Background())
 	if err != nil {
 		log.Fatalf("Failed to run application: %v", err)
@@ -8,6 +8,7 @@ import (
 	"log"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
@@ -15,10 +16,12 @@ import (
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/onoffmetric"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/profile"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/tracefixture"
 	"github.com/CloudDetail/apo-receiver/pkg/config"
 	"github.com/CloudDetail/apo-receiver/pkg/global"
 	"github.com/CloudDetail/apo-receiver/pkg/metrics"
 	metricModel "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 
 	apmclient "github.com/CloudDetail/apo-module/apm/client/v1"
 	apmmodel "github.com/CloudDetail/apo-module/apm/model/v1"
@@ -27,35 +30,54 @@ import (
 )
 
 var (
-	ErrNoSampledTrace error = errors.New("no sampled service is found")
+	ErrNoSampledTrace error = report.NewDropError(report.DropReasonNoSampledTrace, "no sampled service is found")
+	ErrShutdown       error = report.NewDropError(report.DropReasonShutdown, "receiver is shutting down")
+	ErrTaskShed       error = report.NewDropError(report.DropReasonTaskShed, "analyzer task queue is full")
 	TraceExpireTime         = time.Minute
 	MetricExpireTime        = time.Minute
+	// SpanTraceDedupSeconds is the window to skip the duplicate span traces, 0 disables it.
+	SpanTraceDedupSeconds int64 = 600
 )
 
+// workerQueueSize is the tasks dispatched to one worker at most, including the processing one.
+const workerQueueSize = 2
+
 type ReportAnalyzer struct {
 	signals         *profile.SingalsCache
 	waitMap         sync.Map
 	checkMissMap    sync.Map // <traceId, traceApmType>
 	taskPool        *taskPool
-	delayPeriod     int64
-	retryTimes      int
-	missTopTime     int64
+	queueDepths     [taskPriorityCount]int // the depths last updated to the gauge
+	policies        *apmPolicies
+	serviceCache    *serviceCache // nil when replaying, the services are always queried
+	apmGuard        *apmGuard
 	threadCount     int
 	minuteTaskCount int
-	taskIndex       int
 	muatedRatio     int
 	profileDuration int64
 	mutateNodeMode  string
 	topologyPeriod  uint64
 	externalFactory *external.ExternalFactory
 	taskChans       []chan *traceTask
+	workerLoads     []atomic.Int32 // <worker, tasks dispatched and not processed>
+	dispatchChan    chan struct{}
 	stopChan        chan bool
+	stopSubscribe   context.CancelFunc
+	subscribeGroup  sync.WaitGroup
+	replay          *report.ReplayResult // set when replaying, the reports are recorded instead of stored
+	routineGroup    sync.WaitGroup
 }
 
 func NewReportAnalyzer(cfg *config.AnalyzerConfig, signals *profile.SingalsCache) *ReportAnalyzer {
 	taskChans := make([]chan *traceTask, 0)
 	for i := 0; i < cfg.ThreadCount; i++ {
-		taskChans = append(taskChans, make(chan *traceTask))
+		taskChans = append(taskChans, make(chan *traceTask, workerQueueSize))
+	}
+
+	if cfg.SpanTraceDedupSeconds > 0 {
+		SpanTraceDedupSeconds = cfg.SpanTraceDedupSeconds
+	} else if cfg.SpanTraceDedupSeconds < 0 {
+		SpanTraceDedupSeconds = 0
 	}
 
 	topologyPeriod := cfg.TopologyPeriod
@@ -64,75 +86,202 @@ func NewReportAnalyzer(cfg *config.AnalyzerConfig, signals *profile.SingalsCache
 	}
 	return &ReportAnalyzer{
 		signals:         signals,
-		taskPool:        newTaskPool(cfg.RetryDuration),
-		delayPeriod:     cfg.DelayDuration,
-		retryTimes:      cfg.RetryTimes,
-		missTopTime:     cfg.MissTopTime,
+		taskPool:        newTaskPool(cfg.RetryDuration, cfg.TaskQueueSize),
+		policies:        newApmPolicies(cfg),
+		serviceCache:    newServiceCache(cfg.ApmQuery),
+		apmGuard:        newApmGuard(cfg.ApmQuery, cfg.ThreadCount),
 		threadCount:     cfg.ThreadCount,
 		minuteTaskCount: 0,
-		taskIndex:       0,
 		muatedRatio:     cfg.RatioThreshold,
 		profileDuration: int64(cfg.SegmentSize / 2),
 		mutateNodeMode:  cfg.MuateNodeMode,
 		topologyPeriod:  topologyPeriod * 1000000000,
 		externalFactory: external.NewExternalFactory(cfg.HttpParser),
 		taskChans:       taskChans,
+		workerLoads:     make([]atomic.Int32, cfg.ThreadCount),
+		dispatchChan:    make(chan struct{}, 1),
 		stopChan:        make(chan bool),
 	}
 }
 
 func (analyzer *ReportAnalyzer) Start() {
+	analyzer.recoverJournal()
 	for i, taskChan := range analyzer.taskChans {
 		// go routine Pool
+		analyzer.routineGroup.Add(1)
 		go analyzer.analyze(i, taskChan)
 	}
+	analyzer.routineGroup.Add(1)
 	go analyzer.checkTask()
-	go global.CACHE.SubscribeReportTraceId(analyzer)
+
+	subscribeCtx, stopSubscribe := context.WithCancel(context.Background())
+	analyzer.stopSubscribe = stopSubscribe
+	analyzer.subscribeGroup.Add(1)
+	go func() {
+		defer analyzer.subscribeGroup.Done()
+		global.CACHE.SubscribeReportTraceId(subscribeCtx, analyzer)
+	}()
 }
 
-func (analyzer *ReportAnalyzer) Stop() {
+// Stop analyzes the waiting traces and drains the pending tasks until ctx is done,
+// the tasks left are kept in journal to recover after restart, or recorded as drop reports if the journal is not kept.
+func (analyzer *ReportAnalyzer) Stop(ctx context.Context) {
+	// No more traces are consumed while draining, those notified later are left to other receivers.
+	if analyzer.stopSubscribe != nil {
+		analyzer.stopSubscribe()
+		analyzer.subscribeGroup.Wait()
+	}
 	close(analyzer.stopChan)
+	analyzer.routineGroup.Wait()
+	// Keep the tasks dispatched but not processed to drain.
+	for _, taskChan := range analyzer.taskChans {
+		for len(taskChan) > 0 {
+			analyzer.taskPool.restoreTasks([]*traceTask{<-taskChan})
+		}
+	}
+
+	waitCount := 0
+	analyzer.waitMap.Range(func(k, v interface{}) bool {
+		if global.CACHE.IsLocal() {
+			analyzer.Consume(k.(string))
+		} else {
+			// Left to other receivers.
+			global.CACHE.NotifyReportTraceId(k.(string))
+		}
+		analyzer.deleteWait(k.(string))
+		waitCount++
+		return true
+	})
+	log.Printf("[Stop Analyzer] Report %d waiting traces", waitCount)
+
+	timer := time.NewTicker(1 * time.Second)
+	defer timer.Stop()
+	for {
+		tasks := analyzer.taskPool.getToProcessTasks(time.Now().Unix())
+		if left := analyzer.processTasks(ctx, tasks); len(left) > 0 {
+			analyzer.dropTasks(left)
+		}
+		if analyzer.taskPool.isEmpty() {
+			log.Println("[Stop Analyzer] All tasks are drained")
+			return
+		}
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			analyzer.dropTasks(analyzer.taskPool.getAllTasks())
+			return
+		}
+	}
+}
+
+// processTasks processes the tasks in parallel, and returns the tasks not processed before ctx is done.
+func (analyzer *ReportAnalyzer) processTasks(ctx context.Context, tasks []*traceTask) []*traceTask {
+	taskChan := make(chan *traceTask)
+	var wg sync.WaitGroup
+	for i := 0; i < analyzer.threadCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskChan {
+				analyzer.processTask(task)
+			}
+		}()
+	}
+	defer func() {
+		close(taskChan)
+		wg.Wait()
+	}()
+
+	for i, task := range tasks {
+		select {
+		case taskChan <- task:
+		case <-ctx.Done():
+			return tasks[i:]
+		}
+	}
+	return nil
 }
 
-func (analyzer *ReportAnalyzer) StoreEvent(eventJson string) {
-	agentEvent := &model.AgentEvent{}
-	if err := json.Unmarshal([]byte(eventJson), agentEvent); err != nil {
-		log.Printf("[x Parse Agent Event] Error: %s", err.Error())
+func (analyzer *ReportAnalyzer) dropTasks(tasks []*traceTask) {
+	if len(tasks) == 0 {
 		return
 	}
+	if global.CACHE.IsJournalKept() {
+		// The tasks are journaled when added, and recovered by the next receiver.
+		log.Printf("[Stop Analyzer] Keep %d tasks not drained in journal", len(tasks))
+		return
+	}
+	log.Printf("[x Stop Analyzer] Drop %d tasks not drained", len(tasks))
+	for _, task := range tasks {
+		analyzer.recordDropReport(task.traces, ErrShutdown, task.reportType)
+		analyzer.finishTask(task)
+	}
+}
+
+// PendingTasks returns the tasks waiting to be analyzed, including those to retry.
+func (analyzer *ReportAnalyzer) PendingTasks() int {
+	return analyzer.taskPool.size()
+}
+
+func (analyzer *ReportAnalyzer) StoreEvent(agentEvent *model.AgentEvent) {
 	fillK8sMetadataInEvent(agentEvent)
 
-	global.CLICK_HOUSE.StoreAgentEvent(agentEvent)
+	global.SINK.StoreAgentEvent(agentEvent)
 }
 
-func (analyzer *ReportAnalyzer) StoreAppInfo(appInfoJson string) {
-	appInfo := &appinfo.AppInfo{}
-	if err := json.Unmarshal([]byte(appInfoJson), appInfo); err != nil {
-		log.Printf("[x Parse Agent Event] Error: %s", err.Error())
-		return
+// StoreLog correlates the log with trace by its attributes and fills the k8s metadata.
+func (analyzer *ReportAnalyzer) StoreLog(logRecord *grpc_model.LogRecord) {
+	if logRecord.Timestamp == 0 {
+		logRecord.Timestamp = uint64(time.Now().UnixNano())
+	}
+	if logRecord.TraceId == "" {
+		logRecord.TraceId = getLogAttribute(logRecord, "trace_id", "traceId", "trace.id")
+	}
+	if logRecord.SpanId == "" {
+		logRecord.SpanId = getLogAttribute(logRecord, "span_id", "spanId", "span.id")
+	}
+	fillK8sMetadataInLog(logRecord)
+	if logRecord.ServiceName == "" {
+		logRecord.ServiceName = logRecord.ResourceAttributes["service.name"]
 	}
-	fillK8sMetadataInApp(appInfo)
 
-	global.CLICK_HOUSE.StoreAppInfo(appInfo)
+	global.SINK.StoreLog(logRecord)
 }
 
-func (analyzer *ReportAnalyzer) CacheMetric(metricJson string) {
-	onOffMetricGroup := &model.OnOffMetricGroup{}
-	if err := json.Unmarshal([]byte(metricJson), onOffMetricGroup); err != nil {
-		log.Printf("[x Parse OnOff Metric] Error: %s", err.Error())
-		return
+func getLogAttribute(logRecord *grpc_model.LogRecord, keys ...string) string {
+	for _, key := range keys {
+		if value := logRecord.LogAttributes[key]; value != "" {
+			return value
+		}
 	}
-	global.CACHE.StoreMetric(onOffMetricGroup, metricJson)
+	return ""
 }
 
-func (analyzer *ReportAnalyzer) CacheTrace(traceJson string) {
-	trace := &model.Trace{Labels: &model.TraceLabels{ThresholdMultiple: 1.0}}
-	if err := json.Unmarshal([]byte(traceJson), trace); err != nil {
-		log.Printf("[x Parse Trace] Error: %s", err.Error())
-		return
+// StoreK8sEvent normalizes the k8s event as log, eg. pod restarts, OOMKills and scheduling events.
+func (analyzer *ReportAnalyzer) StoreK8sEvent(k8sEvent *grpc_model.K8SEvent) {
+	global.SINK.StoreK8sEvent(toK8sEventLog(k8sEvent))
+}
+
+// StoreAppInfo registers and stores the app info.
+func (analyzer *ReportAnalyzer) StoreAppInfo(appInfo *appinfo.AppInfo) {
+	fillK8sMetadataInApp(appInfo)
+
+	global.APP_REGISTRY.Register(appInfo)
+	global.SINK.StoreAppInfo(appInfo)
+}
+
+// CacheMetric caches the onoff metric, metricJson is empty when the metric is not received as json.
+func (analyzer *ReportAnalyzer) CacheMetric(onOffMetricGroup *model.OnOffMetricGroup, metricJson string) {
+	if metricJson == "" && !global.CACHE.IsLocal() {
+		jsonBytes, _ := json.Marshal(onOffMetricGroup)
+		metricJson = string(jsonBytes)
 	}
+	global.CACHE.StoreMetric(onOffMetricGroup, metricJson)
+}
 
-	if fillK8sMetadataInSpanTrace(trace) {
+// CacheTrace stores the trace and waits for its top span, traceJson is empty when the trace is not received as json.
+func (analyzer *ReportAnalyzer) CacheTrace(trace *model.Trace, traceJson string) {
+	if fillK8sMetadataInSpanTrace(trace) || traceJson == "" {
 		jsonValue := ""
 		if !global.CACHE.IsLocal() {
 			jsonBytes, _ := json.Marshal(trace)
@@ -144,15 +293,16 @@ func (analyzer *ReportAnalyzer) CacheTrace(traceJson string) {
 	}
 
 	traceLabel := trace.Labels
-	if analyzer.missTopTime > 0 {
+	policy := analyzer.policies.get(traceLabel.ApmType)
+	if policy.missTopTime > 0 {
 		if traceLabel.TopSpan {
 			// When top is collected by one collector, mark the flag to -1.
 			global.CACHE.RecordTraceTime(traceLabel.TraceId, -1)
 		} else {
 			timeNano := time.Now().UnixNano()
-			analyzer.checkMissMap.Store(traceLabel.TraceId, &traceApmType{
+			analyzer.storeCheckMiss(traceLabel.TraceId, &traceApmType{
 				apmType:       traceLabel.ApmType,
-				expireTime:    time.Now().Unix() + analyzer.missTopTime,
+				expireTime:    time.Now().Unix() + policy.missTopTime,
 				checkNanoTime: timeNano,
 			})
 			flag := global.CACHE.GetTraceTime(traceLabel.TraceId)
@@ -168,12 +318,13 @@ func (analyzer *ReportAnalyzer) CacheTrace(traceJson string) {
 	}
 
 	// Wait delay_duration.
-	analyzer.waitMap.Store(traceLabel.TraceId, time.Now().Unix()+analyzer.getWaitTime(traceLabel.ApmType))
+	analyzer.storeWait(traceLabel.TraceId, time.Now().Unix()+policy.waitTime)
 }
 
 func (analyzer *ReportAnalyzer) Consume(traceId string) {
 	traces := getTracesFromCache(traceId)
-	if analyzer.missTopTime <= 0 && traces.RootTrace == nil {
+	policy := analyzer.policies.getByTraces(traces)
+	if policy.missTopTime <= 0 && traces.RootTrace == nil {
 		log.Printf("[x Miss RootTrace] TraceId: %s", traceId)
 		return
 	}
@@ -189,15 +340,15 @@ func (analyzer *ReportAnalyzer) Consume(traceId string) {
 		return
 	}
 
-	ignoreRetry := traces.Traces[0].Labels.ApmType == "cw"
+	ignoreRetry := !policy.allowRetry
 	if traces.HasSlow {
-		analyzer.taskPool.addTask(newSlowTraceTask(traces, ignoreRetry))
+		analyzer.addTask(newSlowTraceTask(traces, ignoreRetry))
 	}
 	if traces.HasError {
-		analyzer.taskPool.addTask(newErrorTraceTask(traces, ignoreRetry))
+		analyzer.addTask(newErrorTraceTask(traces, ignoreRetry))
 	}
 	if !traces.HasSlow && !traces.HasError && traces.UnSentTraceCount > 0 {
-		analyzer.taskPool.addTask(newNormalTraceTask(traces, ignoreRetry))
+		analyzer.addTask(newNormalTraceTask(traces, ignoreRetry))
 	}
 }
 
@@ -240,21 +391,15 @@ func mergeTraces(oldTraces *model.Traces, newTraces *model.Traces) {
 	}
 }
 
-func (analyzer *ReportAnalyzer) getWaitTime(apmType string) int64 {
-	if apmType == "nbs3" || apmType == "cw" {
-		// Trace will send every 60 seconds.
-		// It's uncertain which second will be collected, we will wait it for 60 seconds.
-		return 60
-	}
-	return analyzer.delayPeriod
-}
-
 func (analyzer *ReportAnalyzer) analyze(index int, taskChan chan *traceTask) {
+	defer analyzer.routineGroup.Done()
 	for {
 		select {
 		case task := <-taskChan:
 			log.Printf("[Channel - %d] Analyze Trace %s, TraceNum: %d, RetryTime: %d", index+1, task.traces.TraceId, task.traces.GetTraceCount(), task.retryTimes)
 			analyzer.processTask(task)
+			analyzer.workerLoads[index].Add(-1)
+			analyzer.notifyDispatch()
 		case <-analyzer.stopChan:
 			return
 		}
@@ -270,20 +415,32 @@ func (analyzer *ReportAnalyzer) processTask(task *traceTask) {
 		if traceCount > traces.GetTraceCount() || metricCount > traces.MetricCount {
 			// Update New Traces.
 			mergeTraces(task.traces, getTracesFromCache(traces.TraceId))
+			analyzer.serviceCache.invalidate(traces.TraceId)
 		}
 	}
+	if tracefixture.RecorderInstance != nil {
+		tracefixture.RecorderInstance.RecordDataGroups(task.traces, global.CACHE.GetMetrics(task.traces.TraceId))
+	}
 	retry, err := analyzer.buildReport(context.Background(), task.traces, task.reportType)
 	if err != nil {
+		var circuitErr *circuitOpenError
+		if retry && !task.ignoreRetry && errors.As(err, &circuitErr) {
+			// Apm adapter is not queried while the circuit is open.
+			analyzer.deferTask(task, circuitErr.retryTime)
+			return
+		}
 		if retry {
-			if !task.ignoreRetry && task.retryTimes < analyzer.retryTimes {
-				analyzer.taskPool.retryTask(task)
-			} else {
-				recordDropReport(task.traces, err, task.reportType)
+			policy := analyzer.policies.getByTraces(task.traces)
+			if !task.ignoreRetry && task.retryTimes < policy.retryTimes {
+				analyzer.retryTask(task, policy.retryBackoff)
+				return
 			}
+			analyzer.recordDropReport(task.traces, err, task.reportType)
 		} else {
-			recordDropReport(task.traces, err, task.reportType)
+			analyzer.recordDropReport(task.traces, err, task.reportType)
 		}
 	}
+	analyzer.finishTask(task)
 }
 
 func sendProfiledSpanTrace(trace *model.Trace) {
@@ -341,7 +498,7 @@ func (analyzer *ReportAnalyzer) buildSingleErrorReport(ctx context.Context, serv
 			return analyzer.generateErrorReport(ctx, apmType, traces, spanTrace)
 		}
 	}
-	return true, fmt.Errorf("entry[%s-%s] is not collected by apo", entryTrace.Labels.ServiceName, entryTrace.Labels.Url)
+	return true, report.NewDropError(report.DropReasonEntryNotCollected, "entry[%s-%s] is not collected by apo", entryTrace.Labels.ServiceName, entryTrace.Labels.Url)
 }
 
 func (analyzer *ReportAnalyzer) buildMultiErrorReports(ctx context.Context, serviceNodes []*apmmodel.OtelServiceNode, traces *model.Traces) (retry bool, err error) {
@@ -356,7 +513,7 @@ func (analyzer *ReportAnalyzer) buildMultiErrorReports(ctx context.Context, serv
 
 	spanTraces := apmclient.NewNodeSpanTraces(apmType, serviceNodes, traces)
 	if len(spanTraces.Traces) == 0 {
-		return true, fmt.Errorf("trace[%s] is not found in Apm System", traces.TraceId)
+		return true, report.NewDropError(report.DropReasonApmTraceNotFound, "trace[%s] is not found in Apm System", traces.TraceId)
 	}
 
 	for _, spanTrace := range spanTraces.Traces {
@@ -375,8 +532,8 @@ func (analyzer *ReportAnalyzer) generateErrorReport(ctx context.Context, apmType
 		for spanId, errorNode := range apmErrorTree.NodeMap {
 			if errorNode.IsError && errorNode.IsSampled {
 				if node := spanTrace.GetServiceNode(spanId); node != nil {
-					if err := global.TRACE_CLIENT.FillMutatedSpan(ctx, traces.RootTrace.Labels.ClusterID, apmType, traces.TraceId, node); err != nil {
-						return true, err
+					if err := analyzer.fillMutatedSpan(ctx, apmType, traces, node); err != nil {
+						return true, report.WrapDropError(report.DropReasonApmQueryFailed, err)
 					}
 					errorNode.ErrorSpans = apmclient.GetErrorSpans(node)
 				}
@@ -385,14 +542,14 @@ func (analyzer *ReportAnalyzer) generateErrorReport(ctx context.Context, apmType
 	}
 	mutatedTrace, err := apmErrorTree.GetRootCauseErrorNode(traces.TraceId)
 	if err != nil {
-		return false, err
+		return false, report.WrapDropError(report.DropReasonNoMutatedNode, err)
 	}
 
 	if !mutatedTrace.IsProfiled {
-		return false, fmt.Errorf("error instance(%s) is not profiled", mutatedTrace.Id)
+		return false, report.NewDropError(report.DropReasonInstanceNotProfiled, "error instance(%s) is not profiled", mutatedTrace.Id)
 	}
 
-	storeTraces(traces)
+	analyzer.storeTraces(traces)
 	log.Printf("[Write Error Report] Trace: %s", traces.TraceId)
 
 	data := &report.ErrorReportData{
@@ -426,12 +583,40 @@ func (analyzer *ReportAnalyzer) generateErrorReport(ctx context.Context, apmType
 		data.CauseMessage = ""
 	}
 	errorReport := report.NewErrorReport(apmErrorTree.Root.StartTime, traces.TraceId, apmErrorTree.Root.TotalTime, data)
-	global.CLICK_HOUSE.StoreErrorReport(errorReport)
+	analyzer.storeErrorReport(errorReport)
 
 	return false, nil
 }
 
-func storeTraces(traces *model.Traces) {
+func (analyzer *ReportAnalyzer) storeNodeReport(nodeReport *report.NodeReport) {
+	if analyzer.replay != nil {
+		analyzer.replay.NodeReports = append(analyzer.replay.NodeReports, nodeReport)
+		return
+	}
+	global.SINK.StoreNodeReport(nodeReport)
+}
+
+func (analyzer *ReportAnalyzer) storeErrorReport(errorReport *report.ErrorReport) {
+	if analyzer.replay != nil {
+		analyzer.replay.ErrorReports = append(analyzer.replay.ErrorReports, errorReport)
+		return
+	}
+	global.SINK.StoreErrorReport(errorReport)
+}
+
+func (analyzer *ReportAnalyzer) storeRelation(relation *report.Relation) {
+	if analyzer.replay != nil {
+		analyzer.replay.Relations = append(analyzer.replay.Relations, relation)
+		return
+	}
+	global.SINK.StoreRelation(relation)
+}
+
+// storeTraces skips the replayed traces, which are already stored.
+func (analyzer *ReportAnalyzer) storeTraces(traces *model.Traces) {
+	if analyzer.replay != nil {
+		return
+	}
 	for _, trace := range traces.Traces {
 		storeTrace(trace)
 	}
@@ -440,8 +625,19 @@ func storeTraces(traces *model.Traces) {
 func storeTrace(trace *model.Trace) {
 	if !trace.IsSent {
 		trace.MarkSent()
-		global.CLICK_HOUSE.StoreTraceGroup(trace)
+		StoreSpanTrace(trace)
+	}
+}
+
+// StoreSpanTrace stores the span trace unless it is stored in SpanTraceDedupSeconds,
+// the same span may be sent by several agents or stored by several receivers.
+func StoreSpanTrace(trace *model.Trace) {
+	if SpanTraceDedupSeconds > 0 && trace.Labels.ApmSpanId != "" &&
+		!global.CACHE.MarkSpanTraceSent(trace.Labels.TraceId, trace.Labels.ApmSpanId, SpanTraceDedupSeconds) {
+		metrics.UpdateMetric(metricModel.MetricSpanTraceDuplicateCount, []string{trace.Labels.ApmType}, 1)
+		return
 	}
+	global.SINK.StoreTraceGroup(trace)
 }
 
 func (analyzer *ReportAnalyzer) buildSlowReports(ctx context.Context, traces *model.Traces) (retry bool, err error) {
@@ -460,7 +656,7 @@ func (analyzer *ReportAnalyzer) buildSlowReports(ctx context.Context, traces *mo
 func (analyzer *ReportAnalyzer) buildSingleSlowReport(ctx context.Context, serviceNodes []*apmmodel.OtelServiceNode, traces *model.Traces) (bool, error) {
 	entryTrace := traces.RootTrace.Labels
 	if uint64(entryTrace.ThresholdValue) >= entryTrace.Duration {
-		return false, fmt.Errorf("entry service(%s) duration(%d) is less than threshold(%s(%s)=%f)",
+		return false, report.NewDropError(report.DropReasonBelowThreshold, "entry service(%s) duration(%d) is less than threshold(%s(%s)=%f)",
 			entryTrace.ServiceName, entryTrace.Duration, entryTrace.ThresholdType, entryTrace.ThresholdRange,
 			entryTrace.ThresholdValue)
 	}
@@ -479,7 +675,7 @@ func (analyzer *ReportAnalyzer) buildSingleSlowReport(ctx context.Context, servi
 	// [FIX Arms] Drop sampled duration rate < 50% entry duration
 	if maxSampledTrace.Duration*2 < traces.RootTrace.Labels.Duration {
 		rate := uint64(maxSampledTrace.Duration * 100.0 / entryTrace.Duration)
-		return false, fmt.Errorf("top Sampled service(%s) duration(%d) has not enough rate(%d) with service(%s) duration(%d)",
+		return false, report.NewDropError(report.DropReasonNotEnoughSampledRate, "top Sampled service(%s) duration(%d) has not enough rate(%d) with service(%s) duration(%d)",
 			maxSampledTrace.ServiceName, maxSampledTrace.Duration, rate, entryTrace.ServiceName, entryTrace.Duration)
 	}
 
@@ -498,7 +694,7 @@ func (analyzer *ReportAnalyzer) buildSingleSlowReport(ctx context.Context, servi
 			return analyzer.generateSlowReport(ctx, apmType, traces, spanTrace)
 		}
 	}
-	return true, fmt.Errorf("entry[%s-%s] is not collected by apo", entryTrace.ServiceName, entryTrace.Url)
+	return true, report.NewDropError(report.DropReasonEntryNotCollected, "entry[%s-%s] is not collected by apo", entryTrace.ServiceName, entryTrace.Url)
 }
 
 func (analyzer *ReportAnalyzer) buildMultiSlowReports(ctx context.Context, serviceNodes []*apmmodel.OtelServiceNode, traces *model.Traces) (retry bool, err error) {
@@ -513,7 +709,7 @@ func (analyzer *ReportAnalyzer) buildMultiSlowReports(ctx context.Context, servi
 
 	spanTraces := apmclient.NewNodeSpanTraces(apmType, serviceNodes, traces)
 	if len(spanTraces.Traces) == 0 {
-		return true, fmt.Errorf("trace[%s] is not found in Apm System", traces.TraceId)
+		return true, report.NewDropError(report.DropReasonApmTraceNotFound, "trace[%s] is not found in Apm System", traces.TraceId)
 	}
 
 	for _, spanTrace := range spanTraces.Traces {
@@ -535,13 +731,13 @@ func (analyzer *ReportAnalyzer) generateSlowReport(ctx context.Context, apmType
 	apmTraceTree := apmclient.ConvertSlowTree(spanTrace)
 	mutatedTrace, err := apmTraceTree.GetMutatedTraceNode(traces.TraceId, analyzer.muatedRatio, analyzer.mutateNodeMode)
 	if err != nil {
-		return false, err
+		return false, report.WrapDropError(report.DropReasonNoMutatedNode, err)
 	}
 
 	// [FIX Arms] Add Spans for Clients and Excpetions
 	if global.TRACE_CLIENT.NeedGetDetailSpan(ctx, apmType) {
-		if err := global.TRACE_CLIENT.FillMutatedSpan(ctx, traces.RootTrace.Labels.ClusterID, apmType, traces.TraceId, spanTrace.GetServiceNode(mutatedTrace.SpanId)); err != nil {
-			return true, err
+		if err := analyzer.fillMutatedSpan(ctx, apmType, traces, spanTrace.GetServiceNode(mutatedTrace.SpanId)); err != nil {
+			return true, report.WrapDropError(report.DropReasonApmQueryFailed, err)
 		}
 	}
 
@@ -556,19 +752,21 @@ func (analyzer *ReportAnalyzer) generateSlowReport(ctx context.Context, apmType
 				needProfile = true
 			}
 		}
-		analyzer.signals.AddSignal(entryTrace.ServiceName, entryTrace.Url, foundTrace, needProfile)
+		if analyzer.replay == nil {
+			analyzer.signals.AddSignal(entryTrace.ServiceName, entryTrace.Url, foundTrace, needProfile)
+		}
 
 		if !foundTraceLabels.IsSampled {
-			return false, fmt.Errorf("instance(%s) is not sampled", foundTrace.GetInstanceId())
+			return false, report.NewDropError(report.DropReasonInstanceNotSampled, "instance(%s) is not sampled", foundTrace.GetInstanceId())
 		}
 		if !foundTraceLabels.IsProfiled {
-			return false, fmt.Errorf("instance(%s) is not profiled", foundTrace.GetInstanceId())
+			return false, report.NewDropError(report.DropReasonInstanceNotProfiled, "instance(%s) is not profiled", foundTrace.GetInstanceId())
 		}
 
 		mutatedType = foundTrace.MutatedType
-		storeTraces(traces)
+		analyzer.storeTraces(traces)
 	} else {
-		return false, fmt.Errorf("instance(%s) is not monited", mutatedTrace.Id)
+		return false, report.NewDropError(report.DropReasonInstanceNotMonitored, "instance(%s) is not monited", mutatedTrace.Id)
 	}
 
 	log.Printf("[Write Slow Report] Trace: %s", traces.TraceId)
@@ -599,7 +797,7 @@ func (analyzer *ReportAnalyzer) generateSlowReport(ctx context.Context, apmType
 	}
 
 	nodeReport := report.NewNodeReport(apmTraceTree.Root.StartTime, traces.TraceId, apmTraceTree.Root.TotalTime, data)
-	global.CLICK_HOUSE.StoreNodeReport(nodeReport)
+	analyzer.storeNodeReport(nodeReport)
 	return false, nil
 }
 
@@ -609,10 +807,10 @@ func (analyzer *ReportAnalyzer) buildRelations(ctx context.Context, traces *mode
 		return nil, nil
 	}
 	entryTraceLabels := entryTrace.Labels
-	if traces.RootTrace != nil {
+	if traces.RootTrace != nil && analyzer.replay == nil {
 		key := analyzer.getRelationKey(entryTraceLabels.ServiceName, entryTraceLabels.Url, entryTraceLabels.StartTime, false)
 		if global.CACHE.GetRelationTraceId(key) != "" {
-			storeTraces(traces)
+			analyzer.storeTraces(traces)
 			return nil, nil
 		}
 	}
@@ -622,28 +820,25 @@ func (analyzer *ReportAnalyzer) buildRelations(ctx context.Context, traces *mode
 		return nil, err
 	}
 
-	if analyzer.missTopTime == 0 {
-		foundRoot := false
-		for _, serviceNode := range serviceNodes {
-			for _, entrySpan := range serviceNode.EntrySpans {
-				if entrySpan.PSpanId == "" {
-					foundRoot = true
-				}
-			}
-		}
-		if !foundRoot {
-			return serviceNodes, fmt.Errorf("no matched entry span is found in Apm System")
+	if analyzer.policies.get(entryTraceLabels.ApmType).missTopTime == 0 {
+		if !hasRootSpan(serviceNodes) {
+			return serviceNodes, report.NewDropError(report.DropReasonApmTraceNotFound, "no matched entry span is found in Apm System")
 		}
 	}
 
 	topology := report.NewTopology(entryTraceLabels.ApmType, serviceNodes, traces.GetSpanIdTraceMap(), analyzer.externalFactory)
 	for _, topologyNode := range topology.Nodes {
+		if analyzer.replay != nil {
+			// All the relations are replayed, regardless of those built in topology period.
+			analyzer.storeRelation(report.NewRelation(traces.TraceId, topologyNode))
+			continue
+		}
 		key := analyzer.getRelationKey(topologyNode.ServiceName, topologyNode.Url, topologyNode.StartTime, topologyNode.TopNode)
 		if global.CACHE.GetRelationTraceId(key) == "" {
 			global.CACHE.StoreRelationTraceId(key, traces.TraceId)
-			global.CLICK_HOUSE.StoreRelation(report.NewRelation(traces.TraceId, topologyNode))
+			analyzer.storeRelation(report.NewRelation(traces.TraceId, topologyNode))
 
-			storeTraces(traces)
+			analyzer.storeTraces(traces)
 		}
 	}
 	return serviceNodes, nil
@@ -653,48 +848,60 @@ func (analyzer *ReportAnalyzer) getRelationKey(serviceName, url string, timestam
 	return fmt.Sprintf("%s-%s-%d-%t", serviceName, url, timestamp/analyzer.topologyPeriod, vnode)
 }
 
-func recordDropReport(traces *model.Traces, err error, reportType report.ReportType) {
+func (analyzer *ReportAnalyzer) recordDropReport(traces *model.Traces, err error, reportType report.ReportType) {
 	log.Printf("[x Build Report] TraceId: %s, Error: %s", traces.TraceId, err.Error())
 	if reportType == report.ErrorReportType {
-		dropReport := report.NewDropErrorReport(report.CameraErrorReport, traces.GetQueryTrace(), err.Error())
-		global.CLICK_HOUSE.StoreErrorReport(dropReport)
+		dropReport := report.NewDropErrorReport(report.CameraErrorReport, traces.GetQueryTrace(), err)
+		global.SINK.StoreErrorReport(dropReport)
+		countDropReport(reportType, dropReport.Data.DropReasonCode, dropReport.Data.EntryService)
 	} else if reportType == report.SlowReportType {
-		dropReport := report.NewDropReport(report.CameraNodeReport, traces.GetQueryTrace(), err.Error())
-		global.CLICK_HOUSE.StoreNodeReport(dropReport)
+		dropReport := report.NewDropReport(report.CameraNodeReport, traces.GetQueryTrace(), err)
+		global.SINK.StoreNodeReport(dropReport)
+		countDropReport(reportType, dropReport.Data.DropReasonCode, dropReport.Data.EntryService)
 	} else if reportType == report.NormalReportType {
-		storeTraces(traces)
+		analyzer.storeTraces(traces)
+	}
+}
+
+func countDropReport(reportType report.ReportType, reason report.DropReason, entryService string) {
+	metrics.UpdateMetric(metricModel.MetricDropReportCount, []string{reportType.String(), string(reason), entryService}, 1)
+}
+
+// updateQueueDepths adds the change of depth since last update to the gauge of each priority.
+func (analyzer *ReportAnalyzer) updateQueueDepths() {
+	for priority, depth := range analyzer.taskPool.depths() {
+		if delta := depth - analyzer.queueDepths[priority]; delta != 0 {
+			metrics.UpdateMetric(metricModel.MetricAnalyzerTaskQueueDepth, []string{taskPriorityName(priority)}, float64(delta))
+			analyzer.queueDepths[priority] = depth
+		}
 	}
 }
 
 func (analyzer *ReportAnalyzer) checkTask() {
+	defer analyzer.routineGroup.Done()
 	timer := time.NewTicker(1 * time.Second)
 	currentMinute := time.Now().Minute()
 	for {
 		select {
 		case <-timer.C:
 			checkTime := time.Now().Unix()
-			tasks := analyzer.taskPool.getToProcessTasks(checkTime)
-			for _, task := range tasks {
-				analyzer.taskChans[analyzer.taskIndex] <- task
-				if analyzer.taskIndex == analyzer.threadCount-1 {
-					analyzer.taskIndex = 0
-				} else {
-					analyzer.taskIndex += 1
-				}
-				analyzer.minuteTaskCount += 1
-			}
+			analyzer.dispatchTasks(checkTime)
+			analyzer.updateQueueDepths()
 			newMinute := time.Now().Minute()
 			if newMinute != currentMinute {
 				log.Printf("[Minute Execute Task] %d", analyzer.minuteTaskCount)
 				currentMinute = newMinute
 				analyzer.minuteTaskCount = 0
+				// The receivers stopped recently are not expired when this receiver starts.
+				analyzer.recoverJournal()
+				analyzer.serviceCache.expire(checkTime)
 			}
 
 			analyzer.waitMap.Range(func(k, v interface{}) bool {
 				expireTime := v.(int64)
 				if expireTime < checkTime {
 					global.CACHE.NotifyReportTraceId(k.(string))
-					analyzer.waitMap.Delete(k)
+					analyzer.deleteWait(k.(string))
 				}
 				return true
 			})
@@ -704,12 +911,14 @@ func (analyzer *ReportAnalyzer) checkTask() {
 				if traceValue.expireTime < checkTime {
 					traceId := k.(string)
 					if global.CACHE.GetTraceTime(traceId) == traceValue.checkNanoTime {
-						analyzer.waitMap.Store(traceId, checkTime+analyzer.getWaitTime(traceValue.apmType))
+						analyzer.storeWait(traceId, checkTime+analyzer.policies.get(traceValue.apmType).waitTime)
 					}
-					analyzer.checkMissMap.Delete(k)
+					analyzer.deleteCheckMiss(traceId)
 				}
 				return true
 			})
+		case <-analyzer.dispatchChan:
+			analyzer.dispatchTasks(time.Now().Unix())
 		case <-analyzer.stopChan:
 			timer.Stop()
 			return
@@ -717,8 +926,56 @@ func (analyzer *ReportAnalyzer) checkTask() {
 	}
 }
 
+// dispatchTasks dispatches the tasks by priority to the least loaded workers until all workers are full,
+// so that a slow task never blocks the dispatching.
+func (analyzer *ReportAnalyzer) dispatchTasks(checkTime int64) {
+	for {
+		index := analyzer.getLeastLoadedWorker()
+		if index < 0 {
+			return
+		}
+		task := analyzer.taskPool.popTask(checkTime)
+		if task == nil {
+			return
+		}
+		analyzer.workerLoads[index].Add(1)
+		analyzer.taskChans[index] <- task
+		analyzer.minuteTaskCount += 1
+	}
+}
+
+// getLeastLoadedWorker returns -1 if all the workers are full.
+func (analyzer *ReportAnalyzer) getLeastLoadedWorker() int {
+	index, minLoad := -1, int32(workerQueueSize)
+	for i := range analyzer.workerLoads {
+		if load := analyzer.workerLoads[i].Load(); load < minLoad {
+			index, minLoad = i, load
+		}
+	}
+	return index
+}
+
+func (analyzer *ReportAnalyzer) notifyDispatch() {
+	select {
+	case analyzer.dispatchChan <- struct{}{}:
+	default:
+	}
+}
+
+// queryServices returns the services cached if they are queried by other report type or the last retry.
 func (analyzer *ReportAnalyzer) queryServices(ctx context.Context, apmType string, traceId string, rootTrace *model.TraceLabels) ([]*apmmodel.OtelServiceNode, error) {
-	serviceNodes, err := global.TRACE_CLIENT.QueryServices(ctx, rootTrace.ClusterID, apmType, traceId, rootTrace)
+	if serviceNodes, found := analyzer.serviceCache.get(traceId, apmType); found {
+		return serviceNodes, nil
+	}
+	var serviceNodes []*apmmodel.OtelServiceNode
+	err := analyzer.apmGuard.call(ctx, apmMethodQueryServices, apmType, func() (err error) {
+		serviceNodes, err = global.TRACE_CLIENT.QueryServices(ctx, rootTrace.ClusterID, apmType, traceId, rootTrace)
+		return err
+	})
+	err = report.WrapDropError(report.DropReasonApmQueryFailed, err)
+	if err == nil {
+		analyzer.serviceCache.store(traceId, apmType, serviceNodes)
+	}
 	// Record Metric
 	metrics.UpdateMetric(metricModel.MetricAdapterApmTraceCount, []string{
 		rootTrace.NodeName,
@@ -731,79 +988,251 @@ func (analyzer *ReportAnalyzer) queryServices(ctx context.Context, apmType strin
 	return serviceNodes, err
 }
 
+func (analyzer *ReportAnalyzer) fillMutatedSpan(ctx context.Context, apmType string, traces *model.Traces, node *apmmodel.OtelServiceNode) error {
+	return analyzer.apmGuard.call(ctx, apmMethodFillMutatedSpan, apmType, func() error {
+		return global.TRACE_CLIENT.FillMutatedSpan(ctx, traces.RootTrace.Labels.ClusterID, apmType, traces.TraceId, node)
+	})
+}
+
 type traceApmType struct {
 	apmType       string
 	expireTime    int64
 	checkNanoTime int64
 }
 
+// The priorities of tasks, error reports are built first and normal tasks are shed first.
+const (
+	errorTaskPriority = iota
+	slowTaskPriority
+	normalTaskPriority
+	taskPriorityCount
+)
+
+func taskPriority(reportType report.ReportType) int {
+	switch reportType {
+	case report.ErrorReportType:
+		return errorTaskPriority
+	case report.SlowReportType:
+		return slowTaskPriority
+	default:
+		return normalTaskPriority
+	}
+}
+
+func taskPriorityName(priority int) string {
+	switch priority {
+	case errorTaskPriority:
+		return "error"
+	case slowTaskPriority:
+		return "slow"
+	default:
+		return "normal"
+	}
+}
+
 type taskPool struct {
 	taskLock    sync.RWMutex
-	todoTasks   []*traceTask
+	todoTasks   [taskPriorityCount][]*traceTask
 	retryTasks  []*traceTask
 	checkPeriod int64
+	maxSize     int
 }
 
-func newTaskPool(checkPeriod int64) *taskPool {
+func newTaskPool(checkPeriod int64, maxSize int) *taskPool {
 	retryPeriod := checkPeriod
 	if retryPeriod == 0 {
 		retryPeriod = 5
 	}
-	return &taskPool{
-		todoTasks:   make([]*traceTask, 0),
+	if maxSize <= 0 {
+		maxSize = 10000
+	}
+	pool := &taskPool{
 		retryTasks:  make([]*traceTask, 0),
 		checkPeriod: retryPeriod,
+		maxSize:     maxSize,
+	}
+	for i := range pool.todoTasks {
+		pool.todoTasks[i] = make([]*traceTask, 0)
 	}
+	return pool
 }
 
-func (pool *taskPool) addTask(task *traceTask) {
+// addTask returns the shed task when the pool is full, which is the newest task of lower priority or the task itself.
+func (pool *taskPool) addTask(task *traceTask) *traceTask {
 	pool.taskLock.Lock()
 	defer pool.taskLock.Unlock()
 
 	log.Printf("[Add %s Task] %s, TraceNum: %d", task.reportType.String(), task.traces.TraceId, task.traces.GetTraceCount())
-	pool.todoTasks = append(pool.todoTasks, task)
+	shed := pool.shedTask(task)
+	if shed != task {
+		priority := taskPriority(task.reportType)
+		pool.todoTasks[priority] = append(pool.todoTasks[priority], task)
+	}
+	return shed
 }
 
-func (pool *taskPool) retryTask(task *traceTask) {
+// retryTask returns the shed task as addTask, the task is retried after backoff seconds or checkPeriod if it is not set.
+func (pool *taskPool) retryTask(task *traceTask, backoff int64) *traceTask {
+	if backoff <= 0 {
+		backoff = pool.checkPeriod
+	}
+	return pool.scheduleTask(task, time.Now().Unix()+backoff, true)
+}
+
+// deferTask checks the task again at checkTime, which is not counted as a retry.
+func (pool *taskPool) deferTask(task *traceTask, checkTime int64) *traceTask {
+	return pool.scheduleTask(task, checkTime, false)
+}
+
+func (pool *taskPool) scheduleTask(task *traceTask, checkTime int64, retry bool) *traceTask {
 	pool.taskLock.Lock()
 	defer pool.taskLock.Unlock()
 
-	task.retryTimes += 1
-	task.checkTime = time.Now().Unix() + pool.checkPeriod
-	pool.retryTasks = append(pool.retryTasks, task)
+	if retry {
+		task.retryTimes += 1
+	}
+	task.checkTime = checkTime
+	shed := pool.shedTask(task)
+	if shed != task {
+		// Keep the retry tasks sorted by check time.
+		index := len(pool.retryTasks)
+		for index > 0 && pool.retryTasks[index-1].checkTime > task.checkTime {
+			index--
+		}
+		pool.retryTasks = append(pool.retryTasks, nil)
+		copy(pool.retryTasks[index+1:], pool.retryTasks[index:])
+		pool.retryTasks[index] = task
+	}
+	return shed
 }
 
+func (pool *taskPool) shedTask(task *traceTask) *traceTask {
+	if pool.getSize() < pool.maxSize {
+		return nil
+	}
+	priority := taskPriority(task.reportType)
+	for lower := normalTaskPriority; lower > priority; lower-- {
+		if size := len(pool.todoTasks[lower]); size > 0 {
+			shed := pool.todoTasks[lower][size-1]
+			pool.todoTasks[lower] = pool.todoTasks[lower][:size-1]
+			return shed
+		}
+		for i := len(pool.retryTasks) - 1; i >= 0; i-- {
+			if shed := pool.retryTasks[i]; taskPriority(shed.reportType) == lower {
+				pool.retryTasks = append(pool.retryTasks[:i], pool.retryTasks[i+1:]...)
+				return shed
+			}
+		}
+	}
+	return task
+}
+
+// restoreTasks keeps the tasks taken but not processed ahead of the others.
+func (pool *taskPool) restoreTasks(tasks []*traceTask) {
+	pool.taskLock.Lock()
+	defer pool.taskLock.Unlock()
+
+	for i := len(tasks) - 1; i >= 0; i-- {
+		priority := taskPriority(tasks[i].reportType)
+		pool.todoTasks[priority] = append([]*traceTask{tasks[i]}, pool.todoTasks[priority]...)
+	}
+}
+
+func (pool *taskPool) size() int {
+	pool.taskLock.RLock()
+	defer pool.taskLock.RUnlock()
+
+	return pool.getSize()
+}
+
+func (pool *taskPool) getSize() int {
+	size := len(pool.retryTasks)
+	for _, tasks := range pool.todoTasks {
+		size += len(tasks)
+	}
+	return size
+}
+
+func (pool *taskPool) isEmpty() bool {
+	return pool.size() == 0
+}
+
+// depths returns the tasks of each priority, including those to retry.
+func (pool *taskPool) depths() [taskPriorityCount]int {
+	pool.taskLock.RLock()
+	defer pool.taskLock.RUnlock()
+
+	var depths [taskPriorityCount]int
+	for priority, tasks := range pool.todoTasks {
+		depths[priority] = len(tasks)
+	}
+	for _, task := range pool.retryTasks {
+		depths[taskPriority(task.reportType)]++
+	}
+	return depths
+}
+
+// getAllTasks takes all the tasks including those not reach the retry time.
+func (pool *taskPool) getAllTasks() []*traceTask {
+	pool.taskLock.Lock()
+	defer pool.taskLock.Unlock()
+
+	tasks := make([]*traceTask, 0, pool.getSize())
+	for priority := range pool.todoTasks {
+		tasks = append(tasks, pool.todoTasks[priority]...)
+		pool.todoTasks[priority] = pool.todoTasks[priority][0:0]
+	}
+	tasks = append(tasks, pool.retryTasks...)
+	pool.retryTasks = pool.retryTasks[0:0]
+	return tasks
+}
+
+// getToProcessTasks takes the tasks reach the check time by priority.
 func (pool *taskPool) getToProcessTasks(checkTime int64) []*traceTask {
 	pool.taskLock.Lock()
 	defer pool.taskLock.Unlock()
 
+	pool.moveRetryTasks(checkTime)
 	var tasks []*traceTask = make([]*traceTask, 0)
-	size := len(pool.todoTasks)
-	if size > 0 {
-		tasks = append(tasks, pool.todoTasks...)
-		pool.todoTasks = pool.todoTasks[0:0]
-	}
-
-	if len(pool.retryTasks) > 0 {
-		index := 0
-		for i, task := range pool.retryTasks {
-			if task.checkTime <= checkTime {
-				index = i + 1
-			} else {
-				break
-			}
+	for priority := range pool.todoTasks {
+		tasks = append(tasks, pool.todoTasks[priority]...)
+		pool.todoTasks[priority] = pool.todoTasks[priority][0:0]
+	}
+	if len(tasks)+len(pool.retryTasks) > 0 {
+		log.Printf("[Process %d Task] Left %d Tasks", len(tasks), len(pool.retryTasks))
+	}
+	return tasks
+}
+
+// popTask takes the task of the highest priority which reaches the check time, nil if there is none.
+func (pool *taskPool) popTask(checkTime int64) *traceTask {
+	pool.taskLock.Lock()
+	defer pool.taskLock.Unlock()
+
+	pool.moveRetryTasks(checkTime)
+	for priority, tasks := range pool.todoTasks {
+		if len(tasks) > 0 {
+			pool.todoTasks[priority] = tasks[1:]
+			return tasks[0]
 		}
-		if index > 0 {
-			size += index
-			tasks = append(tasks, pool.retryTasks[0:index]...)
-			pool.retryTasks = pool.retryTasks[index:]
+	}
+	return nil
+}
+
+func (pool *taskPool) moveRetryTasks(checkTime int64) {
+	index := 0
+	for i, task := range pool.retryTasks {
+		if task.checkTime <= checkTime {
+			index = i + 1
+		} else {
+			break
 		}
 	}
-	if size+len(pool.retryTasks) > 0 {
-		log.Printf("[Process %d Task] Left %d Tasks", size, len(pool.retryTasks))
+	for _, task := range pool.retryTasks[0:index] {
+		priority := taskPriority(task.reportType)
+		pool.todoTasks[priority] = append(pool.todoTasks[priority], task)
 	}
-
-	return tasks
+	pool.retryTasks = pool.retryTasks[index:]
 }
 
 type traceTask struct {
@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+var allowRetryFalse = false
+
+// defaultApmPolicyConfigs keeps the behaviors of apm types which are not configured in apm_policies.
+var defaultApmPolicyConfigs = map[string]*config.ApmPolicyConfig{
+	// Trace will send every 60 seconds.
+	// It's uncertain which second will be collected, we will wait it for 60 seconds.
+	"nbs3": {WaitTime: 60},
+	"cw":   {WaitTime: 60, AllowRetry: &allowRetryFalse},
+}
+
+// apmPolicy is the wait and retry policy of one apm type.
+type apmPolicy struct {
+	waitTime     int64
+	retryTimes   int
+	retryBackoff int64
+	missTopTime  int64
+	allowRetry   bool
+}
+
+type apmPolicies struct {
+	defaultPolicy *apmPolicy
+	policies      map[string]*apmPolicy
+}
+
+func newApmPolicies(cfg *config.AnalyzerConfig) *apmPolicies {
+	defaultPolicy := &apmPolicy{
+		waitTime:     cfg.DelayDuration,
+		retryTimes:   cfg.RetryTimes,
+		retryBackoff: cfg.RetryDuration,
+		missTopTime:  cfg.MissTopTime,
+		allowRetry:   true,
+	}
+	policies := make(map[string]*apmPolicy)
+	for apmType, policyCfg := range defaultApmPolicyConfigs {
+		policies[apmType] = defaultPolicy.override(policyCfg)
+	}
+	// The configured policy is layered over the default one, the fields not set are kept.
+	for apmType, policyCfg := range cfg.ApmPolicies {
+		if policyCfg == nil {
+			continue
+		}
+		if builtin, found := policies[apmType]; found {
+			policies[apmType] = builtin.override(policyCfg)
+		} else {
+			policies[apmType] = defaultPolicy.override(policyCfg)
+		}
+	}
+	return &apmPolicies{
+		defaultPolicy: defaultPolicy,
+		policies:      policies,
+	}
+}
+
+func (policy *apmPolicy) override(policyCfg *config.ApmPolicyConfig) *apmPolicy {
+	result := *policy
+	if policyCfg.WaitTime > 0 {
+		result.waitTime = policyCfg.WaitTime
+	}
+	if policyCfg.RetryTimes > 0 {
+		result.retryTimes = policyCfg.RetryTimes
+	}
+	if policyCfg.RetryBackoff > 0 {
+		result.retryBackoff = policyCfg.RetryBackoff
+	}
+	if policyCfg.MissTopTime > 0 {
+		result.missTopTime = policyCfg.MissTopTime
+	} else if policyCfg.MissTopTime < 0 {
+		result.missTopTime = 0
+	}
+	if policyCfg.AllowRetry != nil {
+		result.allowRetry = *policyCfg.AllowRetry
+	}
+	return &result
+}
+
+// get returns the analyzer settings if apmType is not configured.
+func (policies *apmPolicies) get(apmType string) *apmPolicy {
+	if policy, found := policies.policies[apmType]; found {
+		return policy
+	}
+	return policies.defaultPolicy
+}
+
+// getByTraces uses the apm type of the first trace.
+func (policies *apmPolicies) getByTraces(traces *model.Traces) *apmPolicy {
+	if len(traces.Traces) == 0 {
+		return policies.defaultPolicy
+	}
+	return policies.get(traces.Traces[0].Labels.ApmType)
+}
@@ -0,0 +1,32 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+func TestApmPolicies(t *testing.T) {
+	allowRetry := true
+	policies := newApmPolicies(&config.AnalyzerConfig{
+		DelayDuration: 5,
+		RetryDuration: 5,
+		RetryTimes:    3,
+		MissTopTime:   30,
+		ApmPolicies: map[string]*config.ApmPolicyConfig{
+			"cw":         {AllowRetry: &allowRetry},
+			"skywalking": {WaitTime: 10, RetryTimes: 5, RetryBackoff: 20, MissTopTime: -1},
+		},
+	})
+
+	// Not configured.
+	assert.Equal(t, &apmPolicy{waitTime: 5, retryTimes: 3, retryBackoff: 5, missTopTime: 30, allowRetry: true}, policies.get("arms"))
+	// Default policy.
+	assert.Equal(t, &apmPolicy{waitTime: 60, retryTimes: 3, retryBackoff: 5, missTopTime: 30, allowRetry: true}, policies.get("nbs3"))
+	// Layered over the default policy.
+	assert.Equal(t, &apmPolicy{waitTime: 60, retryTimes: 3, retryBackoff: 5, missTopTime: 30, allowRetry: true}, policies.get("cw"))
+	// Overridden by config.
+	assert.Equal(t, &apmPolicy{waitTime: 10, retryTimes: 5, retryBackoff: 20, missTopTime: 0, allowRetry: true}, policies.get("skywalking"))
+}
@@ -0,0 +1,306 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	metricModel "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+
+	apmmodel "github.com/CloudDetail/apo-module/apm/model/v1"
+)
+
+const (
+	apmMethodQueryServices   = "QueryServices"
+	apmMethodFillMutatedSpan = "FillMutatedSpan"
+)
+
+// serviceCache keeps the services of traces queried from apm adapter, which are shared by report types and retries.
+// Only the complete services with root span are cached, the incomplete ones are queried again when retry.
+// The entry expired first is evicted when maxEntries are cached.
+type serviceCache struct {
+	ttl        int64
+	maxEntries int
+	lock       sync.Mutex
+	entries    map[string]*serviceCacheEntry // <traceId, entry>
+}
+
+type serviceCacheEntry struct {
+	apmType    string
+	nodes      []byte // json of nodes, the nodes are decoded for each hit as they are changed by analyzer.
+	expireTime int64
+}
+
+// newServiceCache returns nil if cache is disabled.
+func newServiceCache(cfg *config.ApmQueryConfig) *serviceCache {
+	ttl, maxEntries := int64(60), 10000
+	if cfg != nil {
+		if cfg.CacheSeconds != 0 {
+			ttl = cfg.CacheSeconds
+		}
+		if cfg.CacheEntries > 0 {
+			maxEntries = cfg.CacheEntries
+		}
+	}
+	if ttl < 0 {
+		return nil
+	}
+	return &serviceCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*serviceCacheEntry),
+	}
+}
+
+func (cache *serviceCache) get(traceId string, apmType string) ([]*apmmodel.OtelServiceNode, bool) {
+	if cache == nil {
+		return nil, false
+	}
+	cache.lock.Lock()
+	entry, found := cache.entries[traceId]
+	cache.lock.Unlock()
+
+	hit := false
+	nodes := make([]*apmmodel.OtelServiceNode, 0)
+	if found && entry.apmType == apmType && entry.expireTime >= time.Now().Unix() {
+		if err := json.Unmarshal(entry.nodes, &nodes); err != nil {
+			log.Printf("[x Apm Service Cache] TraceId: %s, Error: %s", traceId, err.Error())
+		} else {
+			hit = true
+		}
+	}
+	metrics.UpdateMetric(metricModel.MetricApmQueryCacheCount, []string{apmType, strconv.FormatBool(hit)}, 1)
+	if !hit {
+		return nil, false
+	}
+	return nodes, true
+}
+
+func (cache *serviceCache) store(traceId string, apmType string, nodes []*apmmodel.OtelServiceNode) {
+	if cache == nil || !hasRootSpan(nodes) {
+		return
+	}
+	nodesJson, err := json.Marshal(nodes)
+	if err != nil {
+		log.Printf("[x Apm Service Cache] TraceId: %s, Error: %s", traceId, err.Error())
+		return
+	}
+	now := time.Now().Unix()
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if _, found := cache.entries[traceId]; !found && len(cache.entries) >= cache.maxEntries {
+		cache.evict(now)
+	}
+	cache.entries[traceId] = &serviceCacheEntry{
+		apmType:    apmType,
+		nodes:      nodesJson,
+		expireTime: now + cache.ttl,
+	}
+}
+
+// evict removes the expired entries, or the entry expired first if none is expired.
+func (cache *serviceCache) evict(now int64) {
+	oldestTraceId, oldestExpireTime := "", int64(0)
+	for traceId, entry := range cache.entries {
+		if entry.expireTime < now {
+			delete(cache.entries, traceId)
+		} else if oldestTraceId == "" || entry.expireTime < oldestExpireTime {
+			oldestTraceId, oldestExpireTime = traceId, entry.expireTime
+		}
+	}
+	if len(cache.entries) >= cache.maxEntries {
+		delete(cache.entries, oldestTraceId)
+	}
+}
+
+// invalidate removes the services of trace when new spans are merged.
+func (cache *serviceCache) invalidate(traceId string) {
+	if cache == nil {
+		return
+	}
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	delete(cache.entries, traceId)
+}
+
+func (cache *serviceCache) expire(checkTime int64) {
+	if cache == nil {
+		return
+	}
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	for traceId, entry := range cache.entries {
+		if entry.expireTime < checkTime {
+			delete(cache.entries, traceId)
+		}
+	}
+}
+
+func hasRootSpan(nodes []*apmmodel.OtelServiceNode) bool {
+	for _, serviceNode := range nodes {
+		for _, entrySpan := range serviceNode.EntrySpans {
+			if entrySpan.PSpanId == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apmGuard limits the concurrent queries sent to apm adapter,
+// and rejects the queries of apm type while its circuit is open.
+type apmGuard struct {
+	semaphore        chan struct{}
+	failureThreshold int
+	openDuration     int64
+	lock             sync.Mutex
+	circuits         map[string]*circuitBreaker // <apmType, circuit>
+}
+
+// circuitBreaker is opened after failureThreshold failures in a row,
+// one query is tried after openDuration and the circuit is closed if it succeeds.
+type circuitBreaker struct {
+	failures  int
+	openUntil int64
+	probing   bool
+}
+
+func newApmGuard(cfg *config.ApmQueryConfig, threadCount int) *apmGuard {
+	maxConcurrency, failureThreshold, openDuration := threadCount, 5, int64(30)
+	if cfg != nil {
+		if cfg.MaxConcurrency > 0 {
+			maxConcurrency = cfg.MaxConcurrency
+		}
+		if cfg.FailureThreshold != 0 {
+			failureThreshold = cfg.FailureThreshold
+		}
+		if cfg.OpenSeconds > 0 {
+			openDuration = cfg.OpenSeconds
+		}
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+	return &apmGuard{
+		semaphore:        make(chan struct{}, maxConcurrency),
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		circuits:         make(map[string]*circuitBreaker),
+	}
+}
+
+// circuitOpenError is returned when the query is rejected by the open circuit,
+// the task is checked again after retryTime without counting a retry.
+type circuitOpenError struct {
+	retryTime int64
+	err       error
+}
+
+func (e *circuitOpenError) Error() string {
+	return e.err.Error()
+}
+
+func (e *circuitOpenError) Unwrap() error {
+	return e.err
+}
+
+// call runs query if the circuit of apmType is not open, the query is called directly if guard is nil.
+func (guard *apmGuard) call(ctx context.Context, method string, apmType string, query func() error) error {
+	if guard == nil {
+		return query()
+	}
+	if allowed, retryTime := guard.allow(apmType); !allowed {
+		metrics.UpdateMetric(metricModel.MetricApmCircuitRejectedCount, []string{apmType}, 1)
+		return &circuitOpenError{
+			retryTime: retryTime,
+			err:       report.NewDropError(report.DropReasonApmCircuitOpen, "%s of %s is rejected as the circuit is open", method, apmType),
+		}
+	}
+
+	select {
+	case guard.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		guard.abandon(apmType)
+		return report.WrapDropError(report.DropReasonApmQueryFailed, ctx.Err())
+	}
+	startTime := time.Now()
+	err := query()
+	<-guard.semaphore
+
+	metrics.UpdateMetric(metricModel.MetricApmQueryDuration, []string{method, apmType, strconv.FormatBool(err != nil)}, float64(time.Since(startTime).Nanoseconds()))
+	if err != nil && (errors.Is(err, context.Canceled) || ctx.Err() != nil) {
+		// Cancelled by caller, eg. shutdown or the deadline of replay, which is not a failure of apm adapter.
+		guard.abandon(apmType)
+	} else {
+		guard.record(apmType, err == nil)
+	}
+	return err
+}
+
+// allow returns false and the time to query again if the circuit is open.
+func (guard *apmGuard) allow(apmType string) (bool, int64) {
+	if guard.failureThreshold < 0 {
+		return true, 0
+	}
+	guard.lock.Lock()
+	defer guard.lock.Unlock()
+	circuit, found := guard.circuits[apmType]
+	if !found || circuit.openUntil == 0 {
+		return true, 0
+	}
+	now := time.Now().Unix()
+	if now < circuit.openUntil {
+		return false, circuit.openUntil
+	}
+	if circuit.probing {
+		// Wait for the result of probe.
+		return false, now + 1
+	}
+	circuit.probing = true
+	return true, 0
+}
+
+// abandon ends the query without result, the next query is tried if it was the probe.
+func (guard *apmGuard) abandon(apmType string) {
+	if guard.failureThreshold < 0 {
+		return
+	}
+	guard.lock.Lock()
+	defer guard.lock.Unlock()
+	if circuit, found := guard.circuits[apmType]; found {
+		circuit.probing = false
+	}
+}
+
+func (guard *apmGuard) record(apmType string, success bool) {
+	if guard.failureThreshold < 0 {
+		return
+	}
+	guard.lock.Lock()
+	defer guard.lock.Unlock()
+	circuit, found := guard.circuits[apmType]
+	if !found {
+		circuit = &circuitBreaker{}
+		guard.circuits[apmType] = circuit
+	}
+	if success {
+		if circuit.openUntil > 0 {
+			log.Printf("[Apm Circuit] %s is closed", apmType)
+		}
+		circuit.failures, circuit.openUntil, circuit.probing = 0, 0, false
+		return
+	}
+	circuit.failures++
+	if circuit.probing || circuit.failures >= guard.failureThreshold {
+		circuit.openUntil = time.Now().Unix() + guard.openDuration
+		circuit.probing = false
+		log.Printf("[x Apm Circuit] %s is opened for %ds after %d failures", apmType, guard.openDuration, circuit.failures)
+	}
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+
+	apmmodel "github.com/CloudDetail/apo-module/apm/model/v1"
+)
+
+func TestServiceCache(t *testing.T) {
+	cache := newServiceCache(nil)
+	incompleteNodes := []*apmmodel.OtelServiceNode{
+		{EntrySpans: []*apmmodel.OtelSpan{{SpanId: "2", PSpanId: "1"}}},
+	}
+	cache.store("trace1", "skywalking", incompleteNodes)
+	_, found := cache.get("trace1", "skywalking")
+	assert.False(t, found, "incomplete services are not cached")
+
+	nodes := []*apmmodel.OtelServiceNode{
+		{EntrySpans: []*apmmodel.OtelSpan{{SpanId: "1"}}},
+	}
+	cache.store("trace1", "skywalking", nodes)
+	cachedNodes, found := cache.get("trace1", "skywalking")
+	assert.True(t, found)
+	assert.Equal(t, nodes, cachedNodes)
+	assert.NotSame(t, nodes[0], cachedNodes[0])
+	_, found = cache.get("trace1", "arms")
+	assert.False(t, found)
+
+	cache.invalidate("trace1")
+	_, found = cache.get("trace1", "skywalking")
+	assert.False(t, found)
+
+	assert.Nil(t, newServiceCache(&config.ApmQueryConfig{CacheSeconds: -1}))
+
+	boundedCache := newServiceCache(&config.ApmQueryConfig{CacheEntries: 2})
+	boundedCache.store("trace1", "skywalking", nodes)
+	boundedCache.store("trace2", "skywalking", nodes)
+	boundedCache.entries["trace2"].expireTime -= 10
+	boundedCache.store("trace3", "skywalking", nodes)
+	assert.Len(t, boundedCache.entries, 2)
+	_, found = boundedCache.get("trace2", "skywalking")
+	assert.False(t, found, "entry expired first is evicted")
+}
+
+func TestApmGuardCircuit(t *testing.T) {
+	guard := newApmGuard(&config.ApmQueryConfig{FailureThreshold: 2, OpenSeconds: 30}, 1)
+	queryErr := errors.New("timeout")
+	calls := 0
+	failedQuery := func() error {
+		calls++
+		return queryErr
+	}
+	assert.Equal(t, queryErr, guard.call(context.Background(), apmMethodQueryServices, "skywalking", failedQuery))
+	assert.Equal(t, queryErr, guard.call(context.Background(), apmMethodQueryServices, "skywalking", failedQuery))
+
+	err := guard.call(context.Background(), apmMethodQueryServices, "skywalking", failedQuery)
+	assert.Equal(t, report.DropReasonApmCircuitOpen, report.DropReasonOf(err))
+	var circuitErr *circuitOpenError
+	if assert.ErrorAs(t, err, &circuitErr) {
+		assert.Equal(t, guard.circuits["skywalking"].openUntil, circuitErr.retryTime)
+	}
+	assert.Equal(t, 2, calls)
+	// The circuits are kept by apm type.
+	assert.NoError(t, guard.call(context.Background(), apmMethodQueryServices, "arms", func() error { return nil }))
+
+	// One query is tried after the circuit is opened for OpenSeconds.
+	guard.circuits["skywalking"].openUntil = 1
+	assert.NoError(t, guard.call(context.Background(), apmMethodQueryServices, "skywalking", func() error { return nil }))
+	assert.NoError(t, guard.call(context.Background(), apmMethodQueryServices, "skywalking", func() error { return nil }))
+
+	// The queries cancelled by caller are not counted as failures.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelledQuery := func() error { return ctx.Err() }
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, guard.call(ctx, apmMethodQueryServices, "arms", cancelledQuery), context.Canceled)
+	}
+	assert.Zero(t, guard.circuits["arms"].failures)
+	guard.circuits["arms"].openUntil = 1
+	guard.call(context.Background(), apmMethodQueryServices, "arms", func() error { return context.Canceled })
+	assert.False(t, guard.circuits["arms"].probing, "next query is tried after the probe is cancelled")
+}
@@ -0,0 +1,18 @@
+package appinfo
+
+const (
+	HEART_FLAG_ALIVE = 0
+	HEART_FLAG_MISS  = 1
+	HEART_FLAG_DEAD  = 2
+)
+
+// Heartbeat is the latest state of an app, the newest UpdateTime wins when persisted.
+type Heartbeat struct {
+	NodeIp     string `json:"node_ip"`
+	NodeName   string `json:"node_name"`
+	HostPid    uint32 `json:"host_pid"`
+	StartTime  uint64 `json:"start_time"`
+	HeartTime  uint64 `json:"heart_time"` // Second
+	HeartFlag  uint32 `json:"heart_flag"`
+	UpdateTime uint64 `json:"update_time"` // Millisecond
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+	"github.com/CloudDetail/metadata/model/cache"
+)
+
+const (
+	k8sEventTypeNormal  = "Normal"
+	k8sEventTypeWarning = "Warning"
+
+	// Same with the SeverityNumber of OTel, INFO and WARN.
+	severityNumberInfo = 9
+	severityNumberWarn = 13
+)
+
+// toK8sEventLog normalizes the k8s event as OTel log, the attributes follow the k8s events receiver of OTel collector.
+func toK8sEventLog(event *grpc_model.K8SEvent) *grpc_model.LogRecord {
+	timestamp := event.Timestamp
+	if timestamp == 0 {
+		timestamp = event.FirstTimestamp
+	}
+	if timestamp == 0 {
+		timestamp = uint64(time.Now().UnixNano())
+	}
+	logRecord := &grpc_model.LogRecord{
+		Timestamp:    timestamp,
+		SeverityText: event.Type,
+		Body:         event.Message,
+		ResourceAttributes: map[string]string{
+			"k8s.object.kind":        event.ObjectKind,
+			"k8s.object.name":        event.ObjectName,
+			"k8s.object.uid":         event.ObjectUid,
+			"k8s.object.fieldpath":   event.ObjectFieldPath,
+			"k8s.object.api_version": event.ObjectApiVersion,
+			"k8s.namespace.name":     event.ObjectNamespace,
+		},
+		LogAttributes: map[string]string{
+			"k8s.event.name":             event.Name,
+			"k8s.event.uid":              event.Uid,
+			"k8s.event.reason":           event.Reason,
+			"k8s.event.count":            strconv.Itoa(int(event.Count)),
+			"k8s.event.source.component": event.SourceComponent,
+			"k8s.event.source.host":      event.SourceHost,
+		},
+	}
+	switch event.Type {
+	case k8sEventTypeNormal:
+		logRecord.SeverityNumber = severityNumberInfo
+	case k8sEventTypeWarning:
+		logRecord.SeverityNumber = severityNumberWarn
+	}
+	if event.FirstTimestamp > 0 {
+		logRecord.LogAttributes["k8s.event.start_time"] = time.Unix(0, int64(event.FirstTimestamp)).UTC().Format(time.RFC3339)
+	}
+	if event.ClusterId != "" {
+		logRecord.ResourceAttributes["k8s.cluster.uid"] = event.ClusterId
+	}
+	if event.SourceHost != "" {
+		logRecord.ResourceAttributes["k8s.node.name"] = event.SourceHost
+	}
+	fillK8sMetadataInK8sEvent(event, logRecord.ResourceAttributes)
+	return logRecord
+}
+
+// fillK8sMetadataInK8sEvent fills the workload and node of pod, so that the event can be correlated with the reports.
+func fillK8sMetadataInK8sEvent(event *grpc_model.K8SEvent, attributes map[string]string) {
+	if event.ObjectKind != "Pod" || event.ObjectName == "" {
+		return
+	}
+	attributes["k8s.pod.name"] = event.ObjectName
+	attributes["k8s.pod.uid"] = event.ObjectUid
+	if pod, find := cache.Querier.GetPodByNSAndName(event.ClusterId, event.ObjectNamespace, event.ObjectName); find {
+		if nodeName := pod.NodeName(); nodeName != "" {
+			attributes["k8s.node.name"] = nodeName
+		}
+		owners := pod.GetOwnerReferences(true)
+		if len(owners) > 0 {
+			attributes["k8s."+strings.ToLower(owners[0].Kind)+".name"] = owners[0].Name
+		}
+	}
+}
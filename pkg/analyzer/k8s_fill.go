@@ -1,8 +1,11 @@
 package analyzer
 
 import (
+	"strings"
+
 	"github.com/CloudDetail/apo-module/model/v1"
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 	"github.com/CloudDetail/metadata/model/cache"
 )
 
@@ -50,3 +53,28 @@ func fillK8sMetadataInApp(appInfo *appinfo.AppInfo) {
 		}
 	}
 }
+
+// fillK8sMetadataInLog fills the resource attributes by OTel semantic conventions, eg. k8s.pod.name.
+func fillK8sMetadataInLog(logRecord *grpc_model.LogRecord) {
+	if logRecord.ResourceAttributes == nil {
+		logRecord.ResourceAttributes = make(map[string]string)
+	}
+	attributes := logRecord.ResourceAttributes
+	containerId := logRecord.ContainerId
+	if containerId == "" {
+		containerId = attributes["container.id"]
+	} else if _, ok := attributes["container.id"]; !ok {
+		attributes["container.id"] = containerId
+	}
+	if containerId == "" || attributes["k8s.pod.name"] != "" {
+		return
+	}
+	if pod, find := cache.Querier.GetPodByContainerId("", containerId); find {
+		attributes["k8s.pod.name"] = pod.Name
+		attributes["k8s.namespace.name"] = pod.NS()
+		owners := pod.GetOwnerReferences(true)
+		if len(owners) > 0 {
+			attributes["k8s."+strings.ToLower(owners[0].Kind)+".name"] = owners[0].Name
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/external"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+const (
+	ReplayOutputStdout = "stdout"
+	ReplayOutputShadow = "shadow"
+)
+
+// ReplayerInstance is nil if no queryable sink is configured.
+var ReplayerInstance *Replayer
+
+// Replayer rebuilds the slow / error reports from the stored span traces,
+// which is used to check the analysis again eg. with another mutate_node_mode.
+// The results are never written to the production tables.
+type Replayer struct {
+	querier         sink.Querier
+	store           sink.ReplayStore
+	muatedRatio     int
+	mutateNodeMode  string
+	policies        *apmPolicies
+	externalFactory *external.ExternalFactory
+}
+
+// NewReplayer creates the replayer, store is nil if the results can not be written to shadow table.
+func NewReplayer(cfg *config.AnalyzerConfig, querier sink.Querier, store sink.ReplayStore) *Replayer {
+	return &Replayer{
+		querier:         querier,
+		store:           store,
+		muatedRatio:     cfg.RatioThreshold,
+		mutateNodeMode:  cfg.MuateNodeMode,
+		policies:        newApmPolicies(cfg),
+		externalFactory: external.NewExternalFactory(cfg.HttpParser),
+	}
+}
+
+// ReplayTrace replays the trace, the mutate_node_mode of config is used if mutateNodeMode is empty.
+func (replayer *Replayer) ReplayTrace(ctx context.Context, traceId string, mutateNodeMode string) ([]*report.ReplayResult, error) {
+	traces, err := replayer.querier.QueryTraces(ctx, traceId)
+	if err != nil {
+		return nil, err
+	}
+	return replayer.Replay(ctx, traces, mutateNodeMode), nil
+}
+
+// ReplayTime replays at most limit traces which have slow or error span in [startTime, endTime).
+func (replayer *Replayer) ReplayTime(ctx context.Context, startTime time.Time, endTime time.Time, limit int, mutateNodeMode string) ([]*report.ReplayResult, error) {
+	tracesList, err := replayer.querier.QueryTracesByTime(ctx, startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*report.ReplayResult, 0)
+	for _, traces := range tracesList {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, replayer.Replay(ctx, traces, mutateNodeMode)...)
+	}
+	return results, nil
+}
+
+// Replay builds the slow and error reports of traces, one result for each type.
+func (replayer *Replayer) Replay(ctx context.Context, traces *model.Traces, mutateNodeMode string) []*report.ReplayResult {
+	if mutateNodeMode == "" {
+		mutateNodeMode = replayer.mutateNodeMode
+	}
+	results := make([]*report.ReplayResult, 0)
+	if len(traces.Traces) == 0 {
+		return results
+	}
+	if traces.HasSlow {
+		results = append(results, replayer.replay(ctx, traces, report.SlowReportType, mutateNodeMode))
+	}
+	if traces.HasError {
+		results = append(results, replayer.replay(ctx, traces, report.ErrorReportType, mutateNodeMode))
+	}
+	return results
+}
+
+func (replayer *Replayer) replay(ctx context.Context, traces *model.Traces, reportType report.ReportType, mutateNodeMode string) *report.ReplayResult {
+	result := report.NewReplayResult(traces.TraceId, reportType, mutateNodeMode)
+	// The analyzer is created for each replay, so that the results are not mixed.
+	analyzer := &ReportAnalyzer{
+		policies:        replayer.policies,
+		muatedRatio:     replayer.muatedRatio,
+		mutateNodeMode:  mutateNodeMode,
+		externalFactory: replayer.externalFactory,
+		replay:          result,
+	}
+	if retry, err := analyzer.buildReport(ctx, traces, reportType); err != nil {
+		result.Drop(err, retry)
+	}
+	return result
+}
+
+// ParseReplayWindow parses the window in RFC3339, end is now if it is empty.
+func ParseReplayWindow(start string, end string) (startTime time.Time, endTime time.Time, err error) {
+	if startTime, err = time.Parse(time.RFC3339, start); err != nil {
+		return startTime, endTime, fmt.Errorf("invalid start time %q: %w", start, err)
+	}
+	endTime = time.Now()
+	if end != "" {
+		if endTime, err = time.Parse(time.RFC3339, end); err != nil {
+			return startTime, endTime, fmt.Errorf("invalid end time %q: %w", end, err)
+		}
+	}
+	if !startTime.Before(endTime) {
+		return startTime, endTime, fmt.Errorf("start time %q is not before end time", start)
+	}
+	return startTime, endTime, nil
+}
+
+// Store writes the results to the shadow table replay_report.
+func (replayer *Replayer) Store(ctx context.Context, results []*report.ReplayResult) error {
+	if replayer.store == nil {
+		return sink.ErrNoReplayStore
+	}
+	return replayer.store.StoreReplayResults(ctx, results)
+}
@@ -13,6 +13,9 @@ const (
 
 	OriginxAgentEvent = "originx_agent_event"
 	OriginxAppInfo    = "originx_app_info"
+
+	LogGroup      = "log_group"
+	K8sEventGroup = "k8s_event_group"
 )
 
 type ReportType int
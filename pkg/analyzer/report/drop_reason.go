@@ -0,0 +1,65 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DropReason is the code why the slow / error report is dropped, stored as drop_reason_code.
+type DropReason string
+
+const (
+	DropReasonUnknown              DropReason = "unknown"
+	DropReasonBelowThreshold       DropReason = "below_threshold"
+	DropReasonNoSampledTrace       DropReason = "no_sampled_trace"
+	DropReasonNotEnoughSampledRate DropReason = "not_enough_sampled_rate"
+	DropReasonEntryNotCollected    DropReason = "entry_not_collected"
+	DropReasonApmQueryFailed       DropReason = "apm_query_failed"
+	DropReasonApmCircuitOpen       DropReason = "apm_circuit_open"
+	DropReasonApmTraceNotFound     DropReason = "apm_trace_not_found"
+	DropReasonNoMutatedNode        DropReason = "no_mutated_node"
+	DropReasonInstanceNotSampled   DropReason = "instance_not_sampled"
+	DropReasonInstanceNotProfiled  DropReason = "instance_not_profiled"
+	DropReasonInstanceNotMonitored DropReason = "instance_not_monitored"
+	DropReasonShutdown             DropReason = "shutdown"
+	DropReasonTaskShed             DropReason = "task_shed"
+)
+
+// DropError carries the drop reason with the detail message.
+type DropError struct {
+	Reason DropReason
+	err    error
+}
+
+func NewDropError(reason DropReason, format string, args ...interface{}) error {
+	return &DropError{Reason: reason, err: fmt.Errorf(format, args...)}
+}
+
+// WrapDropError returns nil if err is nil, the reason of err is kept if it is already a DropError.
+func WrapDropError(reason DropReason, err error) error {
+	if err == nil {
+		return nil
+	}
+	var dropErr *DropError
+	if errors.As(err, &dropErr) {
+		return err
+	}
+	return &DropError{Reason: reason, err: err}
+}
+
+func (e *DropError) Error() string {
+	return e.err.Error()
+}
+
+func (e *DropError) Unwrap() error {
+	return e.err
+}
+
+// DropReasonOf returns DropReasonUnknown if err is not a DropError.
+func DropReasonOf(err error) DropReason {
+	var dropErr *DropError
+	if errors.As(err, &dropErr) {
+		return dropErr.Reason
+	}
+	return DropReasonUnknown
+}
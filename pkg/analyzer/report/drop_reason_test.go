@@ -0,0 +1,32 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+func TestDropReason(t *testing.T) {
+	err := NewDropError(DropReasonBelowThreshold, "entry service(%s) duration(%d) is less than threshold", "cart", 10)
+	if reason := DropReasonOf(fmt.Errorf("build: %w", err)); reason != DropReasonBelowThreshold {
+		t.Errorf("expect %s, got %s", DropReasonBelowThreshold, reason)
+	}
+	// The reason is kept when wrapped again.
+	if reason := DropReasonOf(WrapDropError(DropReasonApmQueryFailed, err)); reason != DropReasonBelowThreshold {
+		t.Errorf("expect %s, got %s", DropReasonBelowThreshold, reason)
+	}
+	if WrapDropError(DropReasonApmQueryFailed, nil) != nil {
+		t.Errorf("expect nil error")
+	}
+	if reason := DropReasonOf(errors.New("timeout")); reason != DropReasonUnknown {
+		t.Errorf("expect %s, got %s", DropReasonUnknown, reason)
+	}
+
+	trace := &model.Trace{Labels: &model.TraceLabels{TraceId: "trace-1", ServiceName: "cart"}}
+	dropReport := NewDropReport(CameraNodeReport, trace, err)
+	if dropReport.Data.DropReasonCode != DropReasonBelowThreshold || dropReport.Data.DropReason != err.Error() {
+		t.Errorf("unexpected drop reason %s: %s", dropReport.Data.DropReasonCode, dropReport.Data.DropReason)
+	}
+}
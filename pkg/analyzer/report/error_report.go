@@ -17,9 +17,10 @@ type ErrorReport struct {
 }
 
 type ErrorReportData struct {
-	EndTime            uint64 `json:"end_time,omitempty"`
-	MutatedContainerId string `json:"mutated_container_id,omitempty"`
-	DropReason         string `json:"drop_reason,omitempty"`
+	EndTime            uint64     `json:"end_time,omitempty"`
+	MutatedContainerId string     `json:"mutated_container_id,omitempty"`
+	DropReason         string     `json:"drop_reason,omitempty"`
+	DropReasonCode     DropReason `json:"drop_reason_code,omitempty"`
 
 	model.ErrorReportData
 }
@@ -36,7 +37,8 @@ func NewErrorReport(timestamp uint64, traceId string, duration uint64, data *Err
 	}
 }
 
-func NewDropErrorReport(name string, trace *model.Trace, errorMsg string) *ErrorReport {
+// NewDropErrorReport keeps the detail message of err and its code.
+func NewDropErrorReport(name string, trace *model.Trace, err error) *ErrorReport {
 	entry := trace.Labels
 	return &ErrorReport{
 		Name:      name,
@@ -46,8 +48,9 @@ func NewDropErrorReport(name string, trace *model.Trace, errorMsg string) *Error
 		IsDrop:    true,
 		Duration:  entry.Duration,
 		Data: &ErrorReportData{
-			EndTime:    entry.EndTime,
-			DropReason: errorMsg,
+			EndTime:        entry.EndTime,
+			DropReason:     err.Error(),
+			DropReasonCode: DropReasonOf(err),
 			ErrorReportData: model.ErrorReportData{
 				EntryService:  entry.ServiceName,
 				EntryInstance: trace.GetInstanceId(),
@@ -20,13 +20,15 @@ type NodeReport struct {
 }
 
 type ReportData struct {
-	EndTime    uint64 `json:"end_time,omitempty"`
-	DropReason string `json:"drop_reason,omitempty"`
+	EndTime        uint64     `json:"end_time,omitempty"`
+	DropReason     string     `json:"drop_reason,omitempty"`
+	DropReasonCode DropReason `json:"drop_reason_code,omitempty"`
 
 	model.CameraNodeReportData
 }
 
-func NewDropReport(name string, trace *model.Trace, errorMsg string) *NodeReport {
+// NewDropReport keeps the detail message of err and its code.
+func NewDropReport(name string, trace *model.Trace, err error) *NodeReport {
 	entry := trace.Labels
 	return &NodeReport{
 		Name:      name,
@@ -36,8 +38,9 @@ func NewDropReport(name string, trace *model.Trace, errorMsg string) *NodeReport
 		IsDrop:    true,
 		Duration:  entry.Duration,
 		Data: &ReportData{
-			EndTime:    entry.EndTime,
-			DropReason: errorMsg,
+			EndTime:        entry.EndTime,
+			DropReason:     err.Error(),
+			DropReasonCode: DropReasonOf(err),
 			CameraNodeReportData: model.CameraNodeReportData{
 				EntryService:  entry.ServiceName,
 				EntryInstance: trace.GetInstanceId(),
@@ -0,0 +1,11 @@
+package report
+
+// RawData is the json data of unknown data group, which is stored without parsing.
+type RawData struct {
+	// Timestamp is the time when the data is received, Millisecond.
+	Timestamp int64  `json:"timestamp"`
+	Group     string `json:"group"`
+	// Source is the address of agent which sent the data.
+	Source string `json:"source"`
+	Data   string `json:"data"`
+}
@@ -1,6 +1,7 @@
 package report
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 
@@ -22,6 +23,18 @@ func NewRelation(traceId string, node *TopologyNode) *Relation {
 	}
 }
 
+// UnmarshalJSON restores the Parent of TopologyNode which is skipped in json.
+func (relation *Relation) UnmarshalJSON(data []byte) error {
+	type relationAlias Relation
+	if err := json.Unmarshal(data, (*relationAlias)(relation)); err != nil {
+		return err
+	}
+	if relation.RootNode != nil {
+		relation.RootNode.linkChildren()
+	}
+	return nil
+}
+
 func (relation *Relation) CollectRelationships() {
 	if len(relation.Relationships) == 0 {
 		relation.collectRelationship(relation.RootNode, fmt.Sprintf("%s_", relation.RootNode.SpanId), 0)
@@ -0,0 +1,35 @@
+package report
+
+import "time"
+
+// ReplayResult is the report rebuilt from the stored span traces, it is never written to the production tables.
+type ReplayResult struct {
+	ReplayTime     int64          `json:"replay_time"`
+	TraceId        string         `json:"trace_id"`
+	ReportType     string         `json:"report_type"`
+	MutateNodeMode string         `json:"mutate_node_mode"`
+	IsDrop         bool           `json:"is_drop"`
+	DropReasonCode DropReason     `json:"drop_reason_code,omitempty"`
+	DropReason     string         `json:"drop_reason,omitempty"`
+	Retry          bool           `json:"retry"` // the report would be retried by analyzer
+	NodeReports    []*NodeReport  `json:"node_reports,omitempty"`
+	ErrorReports   []*ErrorReport `json:"error_reports,omitempty"`
+	Relations      []*Relation    `json:"relations,omitempty"`
+}
+
+func NewReplayResult(traceId string, reportType ReportType, mutateNodeMode string) *ReplayResult {
+	return &ReplayResult{
+		ReplayTime:     time.Now().UnixNano(),
+		TraceId:        traceId,
+		ReportType:     reportType.String(),
+		MutateNodeMode: mutateNodeMode,
+	}
+}
+
+// Drop records err as the drop reason.
+func (result *ReplayResult) Drop(err error, retry bool) {
+	result.IsDrop = true
+	result.DropReasonCode = DropReasonOf(err)
+	result.DropReason = err.Error()
+	result.Retry = retry
+}
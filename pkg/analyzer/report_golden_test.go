@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CloudDetail/apo-receiver/pkg/componment/tracefixture"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of testdata/golden")
+
+// TestReportGolden builds the reports of fixtures recorded by analyzer.record_fixture_dir,
+// and compares them with testdata/golden. Run with -update after the fixtures are added.
+func TestReportGolden(t *testing.T) {
+	paths, _ := filepath.Glob(filepath.Join("testdata", "fixtures", "*.json"))
+	if len(paths) == 0 {
+		t.Skip("no fixture is recorded")
+	}
+	oldClient := global.TRACE_CLIENT
+	t.Cleanup(func() {
+		global.TRACE_CLIENT = oldClient
+	})
+	replayer := NewReplayer(&config.AnalyzerConfig{
+		RatioThreshold: 20,
+		MuateNodeMode:  "top3Service",
+		MissTopTime:    30,
+		HttpParser:     "topUrl",
+	}, nil, nil)
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			fixture, err := tracefixture.LoadFixture(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			traces, err := fixture.Traces()
+			if !assert.NoError(t, err) {
+				return
+			}
+			global.TRACE_CLIENT = tracefixture.NewFixtureClient(fixture)
+			results := replayer.Replay(context.Background(), traces, "")
+			// The times of replay are not compared.
+			for _, result := range results {
+				result.ReplayTime = 0
+				for _, nodeReport := range result.NodeReports {
+					nodeReport.IndexTime = 0
+				}
+				for _, errorReport := range result.ErrorReports {
+					errorReport.IndexTime = 0
+				}
+			}
+			actual, err := json.MarshalIndent(results, "", "  ")
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			goldenPath := filepath.Join("testdata", "golden", name+".json")
+			if *updateGolden {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0755))
+				assert.NoError(t, os.WriteFile(goldenPath, actual, 0644))
+				return
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if assert.NoError(t, err, "run with -update to create the golden file") {
+				assert.JSONEq(t, string(expected), string(actual))
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/redis"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
+)
+
+type traceSink struct {
+	sink.Sink
+	traces []*model.Trace
+}
+
+func (s *traceSink) StoreTraceGroup(trace *model.Trace) {
+	s.traces = append(s.traces, trace)
+}
+
+func TestStoreSpanTraceDedup(t *testing.T) {
+	memory := &traceSink{}
+	cache, _ := redis.NewLocalCache(60, "")
+	oldSink, oldCache := global.SINK, global.CACHE
+	global.SINK, global.CACHE = memory, cache
+	t.Cleanup(func() {
+		global.SINK, global.CACHE = oldSink, oldCache
+	})
+
+	newSpanTrace := func(apmSpanId string) *model.Trace {
+		return &model.Trace{Labels: &model.TraceLabels{TraceId: "trace-1", ApmSpanId: apmSpanId}}
+	}
+	// Sent by two agents.
+	storeTrace(newSpanTrace("span-1"))
+	storeTrace(newSpanTrace("span-1"))
+	StoreSpanTrace(newSpanTrace("span-2"))
+	// Lacked apm span id, not deduplicated.
+	StoreSpanTrace(newSpanTrace(""))
+	StoreSpanTrace(newSpanTrace(""))
+	assert.Len(t, memory.traces, 4)
+}
@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	metricModel "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+// The pending works are journaled in cache, so that they are recovered after restart.
+const (
+	journalWait      = "wait"
+	journalCheckMiss = "check_miss"
+	journalTask      = "task"
+)
+
+type checkMissRecord struct {
+	ApmType       string `json:"apm_type"`
+	ExpireTime    int64  `json:"expire_time"`
+	CheckNanoTime int64  `json:"check_nano_time"`
+}
+
+// taskRecord keeps the traces of task, which may be expired in cache before recovered.
+type taskRecord struct {
+	TraceId     string            `json:"trace_id"`
+	ReportType  report.ReportType `json:"report_type"`
+	RetryTimes  int               `json:"retry_times"`
+	IgnoreRetry bool              `json:"ignore_retry"`
+	MetricCount int               `json:"metric_count"`
+	Traces      []*model.Trace    `json:"traces"`
+}
+
+func taskJournalKey(task *traceTask) string {
+	return fmt.Sprintf("%s-%d", task.traces.TraceId, task.reportType)
+}
+
+func (analyzer *ReportAnalyzer) storeWait(traceId string, expireTime int64) {
+	analyzer.waitMap.Store(traceId, expireTime)
+	global.CACHE.PutJournal(journalWait, traceId, strconv.FormatInt(expireTime, 10))
+}
+
+func (analyzer *ReportAnalyzer) deleteWait(traceId string) {
+	analyzer.waitMap.Delete(traceId)
+	global.CACHE.DeleteJournal(journalWait, traceId)
+}
+
+func (analyzer *ReportAnalyzer) storeCheckMiss(traceId string, traceValue *traceApmType) {
+	analyzer.checkMissMap.Store(traceId, traceValue)
+	value, _ := json.Marshal(&checkMissRecord{
+		ApmType:       traceValue.apmType,
+		ExpireTime:    traceValue.expireTime,
+		CheckNanoTime: traceValue.checkNanoTime,
+	})
+	global.CACHE.PutJournal(journalCheckMiss, traceId, string(value))
+}
+
+func (analyzer *ReportAnalyzer) deleteCheckMiss(traceId string) {
+	analyzer.checkMissMap.Delete(traceId)
+	global.CACHE.DeleteJournal(journalCheckMiss, traceId)
+}
+
+func (analyzer *ReportAnalyzer) addTask(task *traceTask) {
+	journalTaskRecord(task)
+	analyzer.shedTask(analyzer.taskPool.addTask(task))
+	analyzer.notifyDispatch()
+}
+
+func (analyzer *ReportAnalyzer) retryTask(task *traceTask, backoff int64) {
+	shed := analyzer.taskPool.retryTask(task, backoff)
+	if shed != task {
+		journalTaskRecord(task)
+	}
+	analyzer.shedTask(shed)
+}
+
+// deferTask checks the task again at checkTime without counting a retry, the journal is not changed.
+func (analyzer *ReportAnalyzer) deferTask(task *traceTask, checkTime int64) {
+	analyzer.shedTask(analyzer.taskPool.deferTask(task, checkTime))
+}
+
+// shedTask drops the task shed by the full pool.
+func (analyzer *ReportAnalyzer) shedTask(task *traceTask) {
+	if task == nil {
+		return
+	}
+	metrics.UpdateMetric(metricModel.MetricAnalyzerTaskShedCount, []string{taskPriorityName(taskPriority(task.reportType))}, 1)
+	analyzer.recordDropReport(task.traces, ErrTaskShed, task.reportType)
+	analyzer.finishTask(task)
+}
+
+// finishTask removes the task from journal when the report is built or dropped.
+func (analyzer *ReportAnalyzer) finishTask(task *traceTask) {
+	global.CACHE.DeleteJournal(journalTask, taskJournalKey(task))
+}
+
+func journalTaskRecord(task *traceTask) {
+	value, err := json.Marshal(&taskRecord{
+		TraceId:     task.traces.TraceId,
+		ReportType:  task.reportType,
+		RetryTimes:  task.retryTimes,
+		IgnoreRetry: task.ignoreRetry,
+		MetricCount: task.traces.MetricCount,
+		Traces:      task.traces.Traces,
+	})
+	if err != nil {
+		log.Printf("[x Journal Task] TraceId: %s, Error: %s", task.traces.TraceId, err.Error())
+		return
+	}
+	global.CACHE.PutJournal(journalTask, taskJournalKey(task), string(value))
+}
+
+// recoverJournal resumes the pending works left by the stopped receivers, they are journaled again by this receiver.
+func (analyzer *ReportAnalyzer) recoverJournal() {
+	entries := global.CACHE.RecoverJournal()
+	if len(entries) == 0 {
+		return
+	}
+	recovered := make(map[string]int)
+	for _, entry := range entries {
+		if err := analyzer.recoverEntry(entry.Kind, entry.Key, entry.Value); err != nil {
+			log.Printf("[x Recover Journal] Kind: %s, Key: %s, Error: %s", entry.Kind, entry.Key, err.Error())
+			continue
+		}
+		recovered[entry.Kind]++
+	}
+	for kind, count := range recovered {
+		metrics.UpdateMetric(metricModel.MetricAnalyzerRecoveredCount, []string{kind}, float64(count))
+	}
+	log.Printf("[Recover Journal] Wait: %d, CheckMiss: %d, Task: %d",
+		recovered[journalWait], recovered[journalCheckMiss], recovered[journalTask])
+}
+
+func (analyzer *ReportAnalyzer) recoverEntry(kind string, key string, value string) error {
+	switch kind {
+	case journalWait:
+		expireTime, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		analyzer.storeWait(key, expireTime)
+	case journalCheckMiss:
+		record := &checkMissRecord{}
+		if err := json.Unmarshal([]byte(value), record); err != nil {
+			return err
+		}
+		analyzer.storeCheckMiss(key, &traceApmType{
+			apmType:       record.ApmType,
+			expireTime:    record.ExpireTime,
+			checkNanoTime: record.CheckNanoTime,
+		})
+	case journalTask:
+		record := &taskRecord{}
+		if err := json.Unmarshal([]byte(value), record); err != nil {
+			return err
+		}
+		traces := model.NewTraces(record.TraceId)
+		for _, trace := range record.Traces {
+			traces.AddTrace(trace)
+		}
+		traces.MetricCount = record.MetricCount
+		// Process immediately, the retry time is not kept.
+		analyzer.addTask(&traceTask{
+			traces:      traces,
+			reportType:  record.ReportType,
+			retryTimes:  record.RetryTimes,
+			ignoreRetry: record.IgnoreRetry,
+		})
+	default:
+		return fmt.Errorf("unknown kind")
+	}
+	return nil
+}
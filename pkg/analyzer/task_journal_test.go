@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/redis"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+)
+
+func TestRecoverJournal(t *testing.T) {
+	dir := t.TempDir()
+	oldCache := global.CACHE
+	t.Cleanup(func() {
+		global.CACHE = oldCache
+	})
+	var cache *redis.LocalCache
+	restart := func() *ReportAnalyzer {
+		if cache != nil {
+			cache.Stop()
+		}
+		var err error
+		cache, err = redis.NewLocalCache(60, dir)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		global.CACHE = cache
+		analyzer := &ReportAnalyzer{taskPool: newTaskPool(5, 0)}
+		analyzer.recoverJournal()
+		return analyzer
+	}
+
+	analyzer := restart()
+	analyzer.storeWait("trace-1", 100)
+	analyzer.storeWait("trace-2", 200)
+	analyzer.deleteWait("trace-2")
+	analyzer.storeCheckMiss("trace-3", &traceApmType{apmType: "skywalking", expireTime: 300, checkNanoTime: 1})
+	analyzer.addTask(newSlowTraceTask(model.NewTraces("trace-4"), false))
+	finished := newErrorTraceTask(model.NewTraces("trace-5"), false)
+	analyzer.addTask(finished)
+	analyzer.finishTask(finished)
+	trace := &model.Trace{Labels: &model.TraceLabels{TraceId: "trace-1", ApmSpanId: "span-1"}}
+	global.CACHE.StoreTrace(trace, "")
+	// Left in journal when stopped.
+	dropped := newSlowTraceTask(model.NewTraces("trace-6"), false)
+	analyzer.addTask(dropped)
+	analyzer.dropTasks([]*traceTask{dropped})
+
+	// Recovered twice, the recovered works are journaled again.
+	for i := 0; i < 2; i++ {
+		analyzer = restart()
+		expireTime, found := analyzer.waitMap.Load("trace-1")
+		assert.True(t, found)
+		assert.Equal(t, int64(100), expireTime)
+		_, found = analyzer.waitMap.Load("trace-2")
+		assert.False(t, found)
+		if traceValue, found := analyzer.checkMissMap.Load("trace-3"); assert.True(t, found) {
+			assert.Equal(t, "skywalking", traceValue.(*traceApmType).apmType)
+			assert.Equal(t, int64(1), traceValue.(*traceApmType).checkNanoTime)
+		}
+		if traces := global.CACHE.GetTraces("trace-1"); assert.Len(t, traces, 1) {
+			assert.Equal(t, "span-1", traces[0].Labels.ApmSpanId)
+		}
+		tasks := analyzer.taskPool.getAllTasks()
+		if assert.Len(t, tasks, 2) {
+			sort.Slice(tasks, func(i, j int) bool {
+				return tasks[i].traces.TraceId < tasks[j].traces.TraceId
+			})
+			assert.Equal(t, "trace-4", tasks[0].traces.TraceId)
+			assert.Equal(t, report.SlowReportType, tasks[0].reportType)
+			assert.Equal(t, "trace-6", tasks[1].traces.TraceId)
+		}
+	}
+
+	// Taken only once by the process.
+	analyzer.recoverJournal()
+	assert.True(t, analyzer.taskPool.isEmpty())
+}
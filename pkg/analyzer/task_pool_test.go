@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+func TestTaskPoolDrain(t *testing.T) {
+	pool := newTaskPool(5, 0)
+	pool.addTask(newSlowTraceTask(model.NewTraces("trace-1"), false))
+	retryTask := newErrorTraceTask(model.NewTraces("trace-2"), false)
+	pool.retryTask(retryTask, 0)
+
+	tasks := pool.getToProcessTasks(time.Now().Unix())
+	if len(tasks) != 1 || tasks[0].traces.TraceId != "trace-1" {
+		t.Errorf("expect only the todo task, got %d tasks", len(tasks))
+	}
+	if pool.isEmpty() {
+		t.Errorf("expect the retry task left")
+	}
+
+	// Tasks not dispatched are kept to drain.
+	pool.restoreTasks(tasks)
+	tasks = pool.getAllTasks()
+	if len(tasks) != 2 {
+		t.Errorf("expect 2 tasks, got %d", len(tasks))
+	}
+	if !pool.isEmpty() {
+		t.Errorf("expect empty pool after getAllTasks")
+	}
+}
+
+func TestTaskPoolPriority(t *testing.T) {
+	pool := newTaskPool(5, 3)
+	normalTask := newNormalTraceTask(model.NewTraces("trace-1"), false)
+	slowTask := newSlowTraceTask(model.NewTraces("trace-2"), false)
+	if shed := pool.addTask(normalTask); shed != nil {
+		t.Errorf("expect no task shed")
+	}
+	pool.addTask(slowTask)
+	pool.addTask(newNormalTraceTask(model.NewTraces("trace-3"), false))
+
+	// The newest normal task is shed when full.
+	errorTask := newErrorTraceTask(model.NewTraces("trace-4"), false)
+	if shed := pool.addTask(errorTask); shed == nil || shed.traces.TraceId != "trace-3" {
+		t.Errorf("expect trace-3 shed, got %v", shed)
+	}
+	// No task of lower priority, the task itself is shed.
+	if shed := pool.addTask(newNormalTraceTask(model.NewTraces("trace-5"), false)); shed == nil || shed.traces.TraceId != "trace-5" {
+		t.Errorf("expect trace-5 shed, got %v", shed)
+	}
+
+	depths := pool.depths()
+	if depths[errorTaskPriority] != 1 || depths[slowTaskPriority] != 1 || depths[normalTaskPriority] != 1 {
+		t.Errorf("unexpected depths %v", depths)
+	}
+	now := time.Now().Unix()
+	for _, expect := range []*traceTask{errorTask, slowTask, normalTask} {
+		if task := pool.popTask(now); task != expect {
+			t.Errorf("expect %s task popped by priority", expect.reportType.String())
+		}
+	}
+	if task := pool.popTask(now); task != nil {
+		t.Errorf("expect no task left")
+	}
+}
+
+func TestTaskPoolRetryBackoff(t *testing.T) {
+	pool := newTaskPool(5, 0)
+	slowTask := newSlowTraceTask(model.NewTraces("trace-1"), false)
+	pool.retryTask(slowTask, 20)
+	errorTask := newErrorTraceTask(model.NewTraces("trace-2"), false)
+	pool.retryTask(errorTask, 0)
+
+	// Retried by check time, regardless of the order added.
+	now := time.Now().Unix()
+	if task := pool.popTask(now + 5); task != errorTask {
+		t.Errorf("expect the error task retried after 5 seconds")
+	}
+	if task := pool.popTask(now + 5); task != nil {
+		t.Errorf("expect the slow task not retried")
+	}
+	if task := pool.popTask(now + 20); task != slowTask {
+		t.Errorf("expect the slow task retried after 20 seconds")
+	}
+}
@@ -1,265 +1,148 @@
 package clickhouse
 
 import (
-	"log"
-	"sync"
-
 	"github.com/CloudDetail/apo-module/model/v1"
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
 	profile_model "github.com/CloudDetail/apo-receiver/pkg/componment/profile/model"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 
 	_ "github.com/ClickHouse/clickhouse-go/v2" // For register database driver.
 )
 
 type cache struct {
-	mutex               sync.RWMutex
-	cameraEventGroups   []string
-	flameGraphs         []string
-	jvmGcs              []string
-	onoffMetrics        []string
-	spanTraces          []*model.Trace
-	cameraNodeReports   []*report.NodeReport
-	cameraErrorReports  []*report.ErrorReport
-	cameraReportMetrics []*profile_model.SlowReportCountMetric
-	relations           []*report.Relation
-	originxAgentEvents  []*model.AgentEvent
-	originxAppInfos     []*appinfo.AppInfo
-}
-
-func newCache() *cache {
-	return &cache{
-		cameraEventGroups:   make([]string, 0),
-		flameGraphs:         make([]string, 0),
-		jvmGcs:              make([]string, 0),
-		onoffMetrics:        make([]string, 0),
-		spanTraces:          make([]*model.Trace, 0),
-		cameraNodeReports:   make([]*report.NodeReport, 0),
-		cameraErrorReports:  make([]*report.ErrorReport, 0),
-		cameraReportMetrics: make([]*profile_model.SlowReportCountMetric, 0),
-		relations:           make([]*report.Relation, 0),
-		originxAgentEvents:  make([]*model.AgentEvent, 0),
-		originxAppInfos:     make([]*appinfo.AppInfo, 0),
+	cameraEventGroups   *cacheBuffer[*grpc_model.ProfilingEvent]
+	flameGraphs         *cacheBuffer[*grpc_model.FlameGraph]
+	jvmGcs              *cacheBuffer[*grpc_model.JvmGc]
+	onoffMetrics        *cacheBuffer[*grpc_model.OnOffMetricGroup]
+	spanTraces          *cacheBuffer[*model.Trace]
+	cameraNodeReports   *cacheBuffer[*report.NodeReport]
+	cameraErrorReports  *cacheBuffer[*report.ErrorReport]
+	cameraReportMetrics *cacheBuffer[*profile_model.SlowReportCountMetric]
+	relations           *cacheBuffer[*report.Relation]
+	originxAgentEvents  *cacheBuffer[*model.AgentEvent]
+	originxAppInfos     *cacheBuffer[*appinfo.AppInfo]
+	appHeartbeats       *cacheBuffer[*appinfo.Heartbeat]
+	rawDatas            *cacheBuffer[*report.RawData]
+	logs                *cacheBuffer[*grpc_model.LogRecord]
+	k8sEvents           *cacheBuffer[*grpc_model.LogRecord]
+}
+
+// newCache creates the buffers of tables, error_propagation and service_client share the buffers of
+// error_report and service_relationship, which are written to both tables by one writer.
+func newCache(defaultLimit *cacheLimit, tableLimits map[string]*cacheLimit) *cache {
+	getLimit := func(table string) *cacheLimit {
+		if limit, found := tableLimits[table]; found {
+			return limit
+		}
+		return defaultLimit
 	}
-}
-
-func (c *cache) batchStore(name string, datas []string) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	switch name {
-	case report.CameraEventGroup:
-		c.cameraEventGroups = append(c.cameraEventGroups, datas...)
-	case report.FlameGraph:
-		c.flameGraphs = append(c.flameGraphs, datas...)
-	case report.JvmGc:
-		c.jvmGcs = append(c.jvmGcs, datas...)
-	case report.OnOffMetricGroup:
-		c.onoffMetrics = append(c.onoffMetrics, datas...)
-	default:
-		log.Printf("[x Unknown Data] %s, Skip.", name)
+	c := &cache{
+		cameraEventGroups:   newCacheBuffer("profiling_event", getLimit("profiling_event"), sizeOfProto[*grpc_model.ProfilingEvent]),
+		flameGraphs:         newCacheBuffer("flame_graph", getLimit("flame_graph"), sizeOfProto[*grpc_model.FlameGraph]),
+		jvmGcs:              newCacheBuffer("jvm_gc", getLimit("jvm_gc"), sizeOfProto[*grpc_model.JvmGc]),
+		onoffMetrics:        newCacheBuffer("onoff_metric", getLimit("onoff_metric"), sizeOfProto[*grpc_model.OnOffMetricGroup]),
+		spanTraces:          newCacheBuffer("span_trace", getLimit("span_trace"), sizeOfValue[*model.Trace]),
+		cameraNodeReports:   newCacheBuffer("slow_report", getLimit("slow_report"), sizeOfValue[*report.NodeReport]),
+		cameraErrorReports:  newCacheBuffer("error_report", getLimit("error_report"), sizeOfValue[*report.ErrorReport]),
+		cameraReportMetrics: newCacheBuffer("report_metric", getLimit("report_metric"), sizeOfValue[*profile_model.SlowReportCountMetric]),
+		relations:           newCacheBuffer("service_relationship", getLimit("service_relationship"), sizeOfValue[*report.Relation]),
+		originxAgentEvents:  newCacheBuffer("originx_agent_event", getLimit("originx_agent_event"), sizeOfValue[*model.AgentEvent]),
+		originxAppInfos:     newCacheBuffer("originx_app_info", getLimit("originx_app_info"), sizeOfValue[*appinfo.AppInfo]),
+		appHeartbeats:       newCacheBuffer("originx_app_heartbeat", getLimit("originx_app_heartbeat"), sizeOfValue[*appinfo.Heartbeat]),
+		rawDatas:            newCacheBuffer("raw_data_group", getLimit("raw_data_group"), sizeOfValue[*report.RawData]),
+		logs:                newCacheBuffer("ilogtail_logs", getLimit("ilogtail_logs"), sizeOfProto[*grpc_model.LogRecord]),
+		k8sEvents:           newCacheBuffer("k8s_events", getLimit("k8s_events"), sizeOfProto[*grpc_model.LogRecord]),
 	}
+	return c
 }
 
-func (c *cache) cacheSpanTrace(trace *model.Trace) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.spanTraces = append(c.spanTraces, trace)
+func (c *cache) cacheOnOffMetric(onOffMetric *grpc_model.OnOffMetricGroup) {
+	c.onoffMetrics.add(onOffMetric)
 }
 
-func (c *cache) cacheNodeReport(nodeReport *report.NodeReport) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.cameraNodeReports = append(c.cameraNodeReports, nodeReport)
+func (c *cache) cacheFlameGraph(flameGraph *grpc_model.FlameGraph) {
+	c.flameGraphs.add(flameGraph)
 }
 
-func (c *cache) cacheErrorReport(errorReport *report.ErrorReport) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.cameraErrorReports = append(c.cameraErrorReports, errorReport)
+func (c *cache) cacheProfilingEvent(profilingEvent *grpc_model.ProfilingEvent) {
+	c.cameraEventGroups.add(profilingEvent)
 }
 
-func (c *cache) cacheReportMetric(reportMetric *profile_model.SlowReportCountMetric) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.cameraReportMetrics = append(c.cameraReportMetrics, reportMetric)
+func (c *cache) cacheJvmGc(jvmGc *grpc_model.JvmGc) {
+	c.jvmGcs.add(jvmGc)
 }
 
-func (c *cache) cacheRelations(relation *report.Relation) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.relations = append(c.relations, relation)
+func (c *cache) cacheRawData(rawData *report.RawData) {
+	c.rawDatas.add(rawData)
 }
 
-func (c *cache) cacheAgentEvent(agentEvent *model.AgentEvent) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.originxAgentEvents = append(c.originxAgentEvents, agentEvent)
-}
-
-func (c *cache) cacheAppInfo(appInfo *appinfo.AppInfo) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.originxAppInfos = append(c.originxAppInfos, appInfo)
-}
-
-func (c *cache) getToSendEventGroups() []string {
-	size := len(c.cameraEventGroups)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.cameraEventGroups[0:size]
-	c.cameraEventGroups = c.cameraEventGroups[size:]
-	return toSends
-}
-
-func (c *cache) getToSendFlameGraphs() []string {
-	size := len(c.flameGraphs)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.flameGraphs[0:size]
-	c.flameGraphs = c.flameGraphs[size:]
-	return toSends
+func (c *cache) cacheLog(logRecord *grpc_model.LogRecord) {
+	c.logs.add(logRecord)
 }
 
-func (c *cache) getToSendJvmGcs() []string {
-	size := len(c.jvmGcs)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.jvmGcs[0:size]
-	c.jvmGcs = c.jvmGcs[size:]
-	return toSends
+func (c *cache) cacheK8sEvent(k8sEvent *grpc_model.LogRecord) {
+	c.k8sEvents.add(k8sEvent)
 }
 
-func (c *cache) getToSendOnOffMetrics() []string {
-	size := len(c.onoffMetrics)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.onoffMetrics[0:size]
-	c.onoffMetrics = c.onoffMetrics[size:]
-	return toSends
+func (c *cache) cacheSpanTrace(trace *model.Trace) {
+	c.spanTraces.add(trace)
 }
 
-func (c *cache) getToSendAppInfos() []*appinfo.AppInfo {
-	size := len(c.originxAppInfos)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.originxAppInfos[0:size]
-	c.originxAppInfos = c.originxAppInfos[size:]
-	return toSends
+func (c *cache) cacheNodeReport(nodeReport *report.NodeReport) {
+	c.cameraNodeReports.add(nodeReport)
 }
 
-func (c *cache) getToSendSpanTraces() []*model.Trace {
-	size := len(c.spanTraces)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.spanTraces[0:size]
-	c.spanTraces = c.spanTraces[size:]
-	return toSends
+func (c *cache) cacheErrorReport(errorReport *report.ErrorReport) {
+	c.cameraErrorReports.add(errorReport)
 }
 
-func (c *cache) getToSendNodeReports() []*report.NodeReport {
-	size := len(c.cameraNodeReports)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.cameraNodeReports[0:size]
-	c.cameraNodeReports = c.cameraNodeReports[size:]
-	return toSends
+func (c *cache) cacheReportMetric(reportMetric *profile_model.SlowReportCountMetric) {
+	c.cameraReportMetrics.add(reportMetric)
 }
 
-func (c *cache) getToSendErrorReports() []*report.ErrorReport {
-	size := len(c.cameraErrorReports)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.cameraErrorReports[0:size]
-	c.cameraErrorReports = c.cameraErrorReports[size:]
-	return toSends
+func (c *cache) cacheRelations(relation *report.Relation) {
+	// Collect before written to service_relationship and service_client.
+	relation.CollectRelationships()
+	c.relations.add(relation)
 }
 
-func (c *cache) getToSendReportMetrics() []*profile_model.SlowReportCountMetric {
-	size := len(c.cameraReportMetrics)
-	if size == 0 {
-		return nil
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.cameraReportMetrics[0:size]
-	c.cameraReportMetrics = c.cameraReportMetrics[size:]
-	return toSends
+func (c *cache) cacheAgentEvent(agentEvent *model.AgentEvent) {
+	c.originxAgentEvents.add(agentEvent)
 }
 
-func (c *cache) getToSendRelations() []*report.Relation {
-	size := len(c.relations)
-	if size == 0 {
-		return nil
+func (c *cache) cacheAppInfo(appInfo *appinfo.AppInfo) {
+	c.originxAppInfos.add(appInfo)
+}
+
+func (c *cache) cacheHeartbeats(heartbeats []*appinfo.Heartbeat) {
+	c.appHeartbeats.add(heartbeats...)
+}
+
+// usage returns the max usage of the buffers.
+func (c *cache) usage() float64 {
+	usages := []float64{
+		c.cameraEventGroups.usage(),
+		c.flameGraphs.usage(),
+		c.jvmGcs.usage(),
+		c.onoffMetrics.usage(),
+		c.spanTraces.usage(),
+		c.cameraNodeReports.usage(),
+		c.cameraErrorReports.usage(),
+		c.cameraReportMetrics.usage(),
+		c.relations.usage(),
+		c.originxAgentEvents.usage(),
+		c.originxAppInfos.usage(),
+		c.appHeartbeats.usage(),
+		c.rawDatas.usage(),
+		c.logs.usage(),
+		c.k8sEvents.usage(),
 	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.relations[0:size]
-	c.relations = c.relations[size:]
-	return toSends
-}
-
-func (c *cache) getToSendAgentEvents() []*model.AgentEvent {
-	size := len(c.originxAgentEvents)
-	if size == 0 {
-		return nil
+	maxUsage := 0.0
+	for _, usage := range usages {
+		if usage > maxUsage {
+			maxUsage = usage
+		}
 	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	toSends := c.originxAgentEvents[0:size]
-	c.originxAgentEvents = c.originxAgentEvents[size:]
-	return toSends
+	return maxUsage
 }
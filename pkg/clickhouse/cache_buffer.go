@@ -0,0 +1,246 @@
+package clickhouse
+
+import (
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	metricModel "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+)
+
+type overflowPolicy string
+
+const (
+	// overflowDropOldest drops the oldest cached datas to make room for the new one.
+	overflowDropOldest overflowPolicy = "drop_oldest"
+	// overflowDropNewest drops the new data.
+	overflowDropNewest overflowPolicy = "drop_newest"
+	// overflowBlock blocks the caller until the cached datas are flushed.
+	overflowBlock overflowPolicy = "block"
+
+	defaultCacheMaxRows = 100000
+)
+
+type cacheLimit struct {
+	maxRows  int
+	maxBytes int
+	policy   overflowPolicy
+}
+
+// cacheBuffer keeps the datas of one table to flush, bounded by cacheLimit.
+type cacheBuffer[T any] struct {
+	table  string
+	limit  *cacheLimit
+	sizeOf func(T) int
+	// flushChan is notified when the buffer reaches its limit.
+	flushChan chan struct{}
+
+	lock    sync.Mutex
+	notFull *sync.Cond
+	datas   []T
+	sizes   []int
+	bytes   int
+}
+
+func newCacheBuffer[T any](table string, limit *cacheLimit, sizeOf func(T) int) *cacheBuffer[T] {
+	buffer := &cacheBuffer[T]{
+		table:     table,
+		limit:     limit,
+		sizeOf:    sizeOf,
+		flushChan: make(chan struct{}, 1),
+		datas:     make([]T, 0),
+		sizes:     make([]int, 0),
+	}
+	buffer.notFull = sync.NewCond(&buffer.lock)
+	return buffer
+}
+
+func (b *cacheBuffer[T]) add(datas ...T) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, data := range datas {
+		size := 0
+		if b.limit.maxBytes > 0 {
+			size = b.sizeOf(data)
+		}
+		if !b.makeRoom(size) {
+			metrics.UpdateMetric(metricModel.MetricClickHouseCacheDroppedCount, []string{b.table, string(b.limit.policy)}, 1)
+			continue
+		}
+		b.datas = append(b.datas, data)
+		b.sizes = append(b.sizes, size)
+		b.bytes += size
+	}
+	if b.isFull(0) {
+		// Flush early, not wait for next period.
+		b.notifyFlush()
+	}
+}
+
+// makeRoom returns false if the new data should be dropped.
+func (b *cacheBuffer[T]) makeRoom(size int) bool {
+	for b.isFull(size) {
+		b.notifyFlush()
+		switch b.limit.policy {
+		case overflowBlock:
+			b.notFull.Wait()
+		case overflowDropNewest:
+			return false
+		default:
+			b.bytes -= b.sizes[0]
+			b.datas = b.datas[1:]
+			b.sizes = b.sizes[1:]
+			metrics.UpdateMetric(metricModel.MetricClickHouseCacheDroppedCount, []string{b.table, string(b.limit.policy)}, 1)
+		}
+	}
+	return true
+}
+
+func (b *cacheBuffer[T]) isFull(size int) bool {
+	if len(b.datas) == 0 {
+		// Always accept one data even it is larger than maxBytes.
+		return false
+	}
+	if b.limit.maxRows > 0 && len(b.datas) >= b.limit.maxRows {
+		return true
+	}
+	return b.limit.maxBytes > 0 && b.bytes+size > b.limit.maxBytes
+}
+
+// usage returns the ratio of cached datas to the limit, the larger one of rows and bytes.
+func (b *cacheBuffer[T]) usage() float64 {
+	if b == nil {
+		return 0
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	usage := 0.0
+	if b.limit.maxRows > 0 {
+		usage = float64(len(b.datas)) / float64(b.limit.maxRows)
+	}
+	if b.limit.maxBytes > 0 {
+		if bytesUsage := float64(b.bytes) / float64(b.limit.maxBytes); bytesUsage > usage {
+			usage = bytesUsage
+		}
+	}
+	return usage
+}
+
+func (b *cacheBuffer[T]) notifyFlush() {
+	select {
+	case b.flushChan <- struct{}{}:
+	default:
+	}
+}
+
+// getToSend takes at most maxSize datas to send, maxSize <= 0 means all.
+func (b *cacheBuffer[T]) getToSend(maxSize int) []T {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	size := len(b.datas)
+	if size == 0 {
+		return nil
+	}
+	if maxSize > 0 && size > maxSize {
+		size = maxSize
+	}
+	toSends := b.datas[:size:size]
+	for _, dataSize := range b.sizes[:size] {
+		b.bytes -= dataSize
+	}
+	if size == len(b.datas) {
+		b.datas = make([]T, 0)
+		b.sizes = make([]int, 0)
+	} else {
+		b.datas = b.datas[size:]
+		b.sizes = b.sizes[size:]
+	}
+	b.notFull.Broadcast()
+	return toSends
+}
+
+func sizeOfString(data string) int {
+	return len(data)
+}
+
+func sizeOfProto[T proto.Message](data T) int {
+	return proto.Size(data)
+}
+
+// sizeOfValue estimates the memory of the data by walking its fields without allocation,
+// the strings and slices are counted by their lengths.
+// The fields tagged json:"-" are not stored and skipped, eg. the parent of topology node which points back to the tree.
+func sizeOfValue[T any](data T) int {
+	return estimateSize(reflect.ValueOf(data), 0)
+}
+
+// maxEstimateDepth stops walking the nested datas, eg. the children of trace tree.
+const maxEstimateDepth = 16
+
+func estimateSize(value reflect.Value, depth int) int {
+	if !value.IsValid() || depth > maxEstimateDepth {
+		return 0
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.Len()
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return 0
+		}
+		return estimateSize(value.Elem(), depth+1)
+	case reflect.Struct:
+		size := 0
+		structType := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			if structType.Field(i).Tag.Get("json") == "-" {
+				continue
+			}
+			size += estimateSize(value.Field(i), depth+1)
+		}
+		return size
+	case reflect.Slice, reflect.Array:
+		if value.Len() == 0 {
+			return 0
+		}
+		elemKind := value.Type().Elem().Kind()
+		if elemKind != reflect.String && elemKind != reflect.Pointer && elemKind != reflect.Struct &&
+			elemKind != reflect.Slice && elemKind != reflect.Map && elemKind != reflect.Interface {
+			return value.Len() * int(value.Type().Elem().Size())
+		}
+		size := 0
+		for i := 0; i < value.Len(); i++ {
+			size += estimateSize(value.Index(i), depth+1)
+		}
+		return size
+	case reflect.Map:
+		size := 0
+		iter := value.MapRange()
+		for iter.Next() {
+			size += estimateSize(iter.Key(), depth+1) + estimateSize(iter.Value(), depth+1)
+		}
+		return size
+	default:
+		return int(value.Type().Size())
+	}
+}
+
+func newCacheLimit(maxRows int, maxMB int, policy string) *cacheLimit {
+	if maxRows == 0 {
+		maxRows = defaultCacheMaxRows
+	}
+	overflow := overflowPolicy(policy)
+	if overflow != overflowDropNewest && overflow != overflowBlock {
+		overflow = overflowDropOldest
+	}
+	return &cacheLimit{
+		maxRows:  maxRows,
+		maxBytes: maxMB * 1024 * 1024,
+		policy:   overflow,
+	}
+}
@@ -0,0 +1,96 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheBufferDropOldest(t *testing.T) {
+	buffer := newCacheBuffer("jvm_gc", newCacheLimit(2, 0, "drop_oldest"), sizeOfString)
+
+	buffer.add("a", "b", "c")
+	assert.Len(t, buffer.flushChan, 1)
+	assert.Equal(t, []string{"b", "c"}, buffer.getToSend(0))
+	assert.Nil(t, buffer.getToSend(0))
+}
+
+func TestCacheBufferDropNewest(t *testing.T) {
+	buffer := newCacheBuffer("jvm_gc", newCacheLimit(2, 0, "drop_newest"), sizeOfString)
+
+	buffer.add("a", "b", "c")
+	assert.Equal(t, []string{"a", "b"}, buffer.getToSend(0))
+}
+
+func TestCacheBufferMaxBytes(t *testing.T) {
+	buffer := newCacheBuffer("jvm_gc", &cacheLimit{maxRows: -1, maxBytes: 4, policy: overflowDropOldest}, sizeOfString)
+
+	buffer.add("ab", "cd", "ef")
+	assert.Equal(t, []string{"cd", "ef"}, buffer.getToSend(0))
+
+	// Accept a single data larger than the limit.
+	buffer.add("abcdef")
+	assert.Equal(t, []string{"abcdef"}, buffer.getToSend(0))
+}
+
+func TestCacheBufferMaxBatchSize(t *testing.T) {
+	buffer := newCacheBuffer("jvm_gc", &cacheLimit{maxRows: -1, maxBytes: 4, policy: overflowDropOldest}, sizeOfString)
+
+	buffer.add("a", "b", "c")
+	assert.Equal(t, []string{"a", "b"}, buffer.getToSend(2))
+	buffer.add("d", "e")
+	assert.Equal(t, []string{"c", "d", "e"}, buffer.getToSend(0))
+	assert.Equal(t, 0, buffer.bytes)
+}
+
+func TestCacheBufferBlock(t *testing.T) {
+	buffer := newCacheBuffer("jvm_gc", newCacheLimit(1, 0, "block"), sizeOfString)
+
+	buffer.add("a")
+	done := make(chan bool)
+	go func() {
+		buffer.add("b")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("add should block until flushed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	<-buffer.flushChan
+	assert.Equal(t, []string{"a"}, buffer.getToSend(0))
+	<-done
+	assert.Equal(t, []string{"b"}, buffer.getToSend(0))
+}
+
+func TestSizeOfValue(t *testing.T) {
+	type label struct {
+		Name  string
+		Count uint64
+	}
+	type data struct {
+		TraceId string
+		Labels  *label
+		Tags    map[string]string
+		Spans   []*label
+		Ids     []uint32
+	}
+	assert.Equal(t, 0, sizeOfValue[*data](nil))
+	assert.Equal(t, 5+(4+8)+(1+2)+(1+8)+2*4, sizeOfValue(&data{
+		TraceId: "trace",
+		Labels:  &label{Name: "name", Count: 1},
+		Tags:    map[string]string{"a": "bc"},
+		Spans:   []*label{{Name: "s"}, nil},
+		Ids:     []uint32{1, 2},
+	}))
+	type node struct {
+		Name     string
+		Parent   *node `json:"-"`
+		Children []*node
+	}
+	root := &node{Name: "root"}
+	root.Children = []*node{{Name: "a", Parent: root}, {Name: "b", Parent: root}}
+	assert.Equal(t, 4+1+1, sizeOfValue(root), "parent is not counted again by children")
+}
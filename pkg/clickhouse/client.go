@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"sync"
 	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-module/model/v1"
 
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
@@ -24,8 +27,13 @@ var (
 
 type ClickHouseClient struct {
 	Conn                 *sql.DB
+	batchConn            driver.Conn
 	cache                *cache
-	flushPeriod          uint
+	spool                *spool
+	defaultWriterConfig  *writerConfig
+	tableWriterConfigs   map[string]*writerConfig
+	writers              []tableRunner
+	writerGroup          sync.WaitGroup
 	stopChan             chan bool
 	exportServiceClient  bool
 	generateClientMetric bool
@@ -37,29 +45,46 @@ func NewClickHouseClient(ctx context.Context, cfg *config.ClickHouseConfig, gene
 		return nil, errConfigNoEndpoint
 	}
 
-	tableTTLs := make(map[string]uint)
-	tableHash := make(map[string]string)
-	for _, ttl := range cfg.TTLConfig {
-		for _, tableName := range ttl.Tables {
-			tableTTLs[tableName] = ttl.TTL
+	defaultLimit := newCacheLimit(cfg.CacheMaxRows, cfg.CacheMaxMB, cfg.CacheOverflowPolicy)
+	defaultWriterConfig := newWriterConfig(cfg.FlushSeconds, cfg.MaxBatchSize)
+	tableLimits := make(map[string]*cacheLimit)
+	tableWriterConfigs := make(map[string]*writerConfig)
+	for _, cacheCfg := range cfg.CacheConfig {
+		limit := newCacheLimit(cacheCfg.MaxRows, cacheCfg.MaxMB, cacheCfg.OverflowPolicy)
+		flushSeconds, maxBatchSize := cacheCfg.FlushSeconds, cacheCfg.MaxBatchSize
+		if flushSeconds == 0 {
+			flushSeconds = cfg.FlushSeconds
 		}
-	}
-	for _, hash := range cfg.HashConfig {
-		for _, tableName := range hash.Tables {
-			tableHash[tableName] = hash.Hash
+		if maxBatchSize == 0 {
+			maxBatchSize = cfg.MaxBatchSize
+		}
+		writerCfg := newWriterConfig(flushSeconds, maxBatchSize)
+		for _, tableName := range cacheCfg.Tables {
+			tableLimits[tableName] = limit
+			tableWriterConfigs[tableName] = writerCfg
 		}
 	}
 
-	init := NewClickHouseInit(cfg.Endpoint, cfg.Database, cfg.Replication, cfg.Cluster,
-		cfg.Username, cfg.Password, true, cfg.TTLDays, tableTTLs, tableHash)
+	init := newClickHouseInit(cfg, true, !cfg.SkipMigrate)
 	if err := init.Start(); err != nil {
 		return nil, err
 	}
 
+	var failedSpool *spool
+	if cfg.SpoolDir != "" {
+		var err error
+		if failedSpool, err = newSpool(cfg.SpoolDir, cfg.SpoolMaxMB); err != nil {
+			return nil, err
+		}
+	}
+
 	client := &ClickHouseClient{
 		Conn:                 init.GetConn(),
-		cache:                newCache(),
-		flushPeriod:          cfg.FlushSeconds,
+		batchConn:            init.GetBatchConn(),
+		cache:                newCache(defaultLimit, tableLimits),
+		spool:                failedSpool,
+		defaultWriterConfig:  defaultWriterConfig,
+		tableWriterConfigs:   tableWriterConfigs,
 		stopChan:             make(chan bool),
 		exportServiceClient:  cfg.ExportServiceClient,
 		generateClientMetric: generateClientMetric,
@@ -68,8 +93,55 @@ func NewClickHouseClient(ctx context.Context, cfg *config.ClickHouseConfig, gene
 	return client, nil
 }
 
-func (client *ClickHouseClient) BatchStore(table string, datas []string) {
-	client.cache.batchStore(table, datas)
+func newClickHouseInit(cfg *config.ClickHouseConfig, createTable bool, migrate bool) *ClickHouseInit {
+	tableTTLs := make(map[string]uint)
+	tableHash := make(map[string]string)
+	for _, ttl := range cfg.TTLConfig {
+		for _, tableName := range ttl.Tables {
+			tableTTLs[tableName] = ttl.TTL
+		}
+	}
+	for _, hash := range cfg.HashConfig {
+		for _, tableName := range hash.Tables {
+			tableHash[tableName] = hash.Hash
+		}
+	}
+	lockWaitSeconds := cfg.MigrateLockWaitSeconds
+	if lockWaitSeconds == 0 {
+		lockWaitSeconds = 60
+	}
+	init := NewClickHouseInit(cfg.Endpoint, cfg.Database, cfg.Replication, cfg.Cluster,
+		cfg.Username, cfg.Password, createTable, migrate, cfg.TTLDays, tableTTLs, tableHash, cfg.ScriptDir)
+	init.lockWait = time.Duration(lockWaitSeconds) * time.Second
+	return init
+}
+
+func (client *ClickHouseClient) StoreOnOffMetric(onOffMetric *grpc_model.OnOffMetricGroup) {
+	client.cache.cacheOnOffMetric(onOffMetric)
+}
+
+func (client *ClickHouseClient) StoreFlameGraph(flameGraph *grpc_model.FlameGraph) {
+	client.cache.cacheFlameGraph(flameGraph)
+}
+
+func (client *ClickHouseClient) StoreProfilingEvent(profilingEvent *grpc_model.ProfilingEvent) {
+	client.cache.cacheProfilingEvent(profilingEvent)
+}
+
+func (client *ClickHouseClient) StoreJvmGc(jvmGc *grpc_model.JvmGc) {
+	client.cache.cacheJvmGc(jvmGc)
+}
+
+func (client *ClickHouseClient) StoreLog(logRecord *grpc_model.LogRecord) {
+	client.cache.cacheLog(logRecord)
+}
+
+func (client *ClickHouseClient) StoreK8sEvent(k8sEvent *grpc_model.LogRecord) {
+	client.cache.cacheK8sEvent(k8sEvent)
+}
+
+func (client *ClickHouseClient) StoreRawData(rawData *report.RawData) {
+	client.cache.cacheRawData(rawData)
 }
 
 func (client *ClickHouseClient) StoreTraceGroup(trace *model.Trace) {
@@ -100,90 +172,109 @@ func (client *ClickHouseClient) StoreAppInfo(appInfo *appinfo.AppInfo) {
 	client.cache.cacheAppInfo(appInfo)
 }
 
-func (client *ClickHouseClient) QueryActiveApps(ctx context.Context, nodeIp string, nodeName string, deadApps []*grpc_model.QueryActiveApp) ([]*grpc_model.QueryActiveApp, error) {
-	return tables.QueryActiveApps(ctx, client.Conn, nodeIp, nodeName, deadApps)
-}
-
-func (client *ClickHouseClient) UpdateAppHeartTimes(nodeIp string, nodeName string, activeApps []*grpc_model.QueryActiveApp) error {
-	return tables.UpdateAppHeartTimes(client.Conn, nodeIp, nodeName, activeApps)
+// LoadHeartbeats returns the apps not dead to recover the app registry.
+func (client *ClickHouseClient) LoadHeartbeats(ctx context.Context) ([]*appinfo.Heartbeat, error) {
+	return tables.QueryAliveAppHeartbeats(ctx, client.batchConn)
 }
 
-func (client *ClickHouseClient) UpdateAppDeadFlags(nodeIp string, nodeName string, deadApps []*grpc_model.QueryActiveApp) error {
-	return tables.UpdateAppDeadFlags(client.Conn, nodeIp, nodeName, deadApps)
+func (client *ClickHouseClient) StoreHeartbeats(heartbeats []*appinfo.Heartbeat) {
+	client.cache.cacheHeartbeats(heartbeats)
 }
 
 func (client *ClickHouseClient) QueryTraces(ctx context.Context, traceId string) (*model.Traces, error) {
 	return tables.QueryTraces(ctx, client.Conn, traceId)
 }
 
-func (client *ClickHouseClient) Start() {
-	go client.batchSendToServer()
+func (client *ClickHouseClient) QueryTracesByTime(ctx context.Context, startTime time.Time, endTime time.Time, limit int) ([]*model.Traces, error) {
+	return tables.QueryTracesByTime(ctx, client.Conn, startTime, endTime, limit)
+}
+
+// StoreReplayResults writes the replay results without cache, so that the failure is returned to caller.
+func (client *ClickHouseClient) StoreReplayResults(ctx context.Context, results []*report.ReplayResult) error {
+	return tables.WriteReplayReports(ctx, client.batchConn, results)
 }
 
-func (client *ClickHouseClient) batchSendToServer() {
+// CacheUsage returns the max usage of table caches, 1 means the cache limit is reached.
+func (client *ClickHouseClient) CacheUsage() float64 {
+	return client.cache.usage()
+}
+
+// SyncedTime returns the time before which the stored datas are written or spooled by all the writers.
+func (client *ClickHouseClient) SyncedTime() time.Time {
+	var syncedTime time.Time
+	for i, writer := range client.writers {
+		if writerTime := writer.syncedTime(); i == 0 || writerTime.Before(syncedTime) {
+			syncedTime = writerTime
+		}
+	}
+	return syncedTime
+}
+
+func (client *ClickHouseClient) Start() {
 	ctx := context.Background()
-	waitSecond := client.flushPeriod
-	if waitSecond == 0 {
-		waitSecond = 5
+	client.writers = client.buildWriters()
+	for _, writer := range client.writers {
+		client.writerGroup.Add(1)
+		go func(writer tableRunner) {
+			defer client.writerGroup.Done()
+			writer.run(ctx, client.stopChan)
+		}(writer)
+	}
+}
+
+func (client *ClickHouseClient) buildWriters() []tableRunner {
+	c := client.cache
+	relationWriter := newTableWriter(client, c.relations, client.getWriterConfig(c.relations.table), tables.WriteServiceRelationships)
+	if client.exportServiceClient {
+		relationWriter.fanOut("service_client", tables.WriteServiceClients)
 	}
-	timer := time.NewTicker(time.Duration(waitSecond) * time.Second)
-	for {
-		select {
-		case <-timer.C:
-			if err := tables.WriteProfilingEvents(ctx, client.Conn, client.cache.getToSendEventGroups()); err != nil {
-				log.Printf("[x Add ProfilingEvent] %s", err.Error())
-			}
-			if err := tables.WriteFlameGraph(ctx, client.Conn, client.cache.getToSendFlameGraphs()); err != nil {
-				log.Printf("[x Add FlameGraph] %s", err.Error())
-			}
-			if err := tables.WriteJvmGcs(ctx, client.Conn, client.cache.getToSendJvmGcs()); err != nil {
-				log.Printf("[x Add JvmGc] %s", err.Error())
-			}
-			if err := tables.WriteSpanTraces(ctx, client.Conn, client.cache.getToSendSpanTraces()); err != nil {
-				log.Printf("[x Add SpanTrace] %s", err.Error())
-			}
-			if err := tables.WriteAppInfos(ctx, client.Conn, client.cache.getToSendAppInfos()); err != nil {
-				log.Printf("[x Add AppInfo] %s", err.Error())
-			}
-			if err := tables.WriteSlowReports(ctx, client.Conn, client.cache.getToSendNodeReports()); err != nil {
-				log.Printf("[x Add SlowReport] %s", err.Error())
-			}
-			errorReports := client.cache.getToSendErrorReports()
-			if err := tables.WriteErrorReports(ctx, client.Conn, errorReports); err != nil {
-				log.Printf("[x Add ErrorReport] %s", err.Error())
-			}
-			if err := tables.WriteErrorPropagations(ctx, client.Conn, errorReports); err != nil {
-				log.Printf("[x Add ErrorPropagation] %s", err.Error())
-			}
-			if err := tables.WriteReportMetrics(ctx, client.Conn, client.cache.getToSendReportMetrics()); err != nil {
-				log.Printf("[x Add ReportMetric] %s", err.Error())
-			}
-			if err := tables.WriteOnOffMetrics(ctx, client.Conn, client.cache.getToSendOnOffMetrics()); err != nil {
-				log.Printf("[x Add OnOffMetric] %s", err.Error())
-			}
-			relations := client.cache.getToSendRelations()
-			if err := tables.WriteServiceRelationships(ctx, client.Conn, relations); err != nil {
-				log.Printf("[x Add ServiceRelationship] %s", err.Error())
-			}
-			agentEvents := client.cache.getToSendAgentEvents()
-			if err := tables.WriteAgentEvents(ctx, client.Conn, agentEvents); err != nil {
-				log.Printf("[x Add AgentEvent] %s", err.Error())
-			}
-			if client.exportServiceClient {
-				if err := tables.WriteServiceClients(ctx, client.Conn, relations); err != nil {
-					log.Printf("[x Add ServiceClient] %s", err.Error())
-				}
-			}
-			if client.generateClientMetric {
-				tables.WriteClientMetric(relations, client.clientMetricWithUrl)
-			}
-		case <-client.stopChan:
-			timer.Stop()
-			return
+	if client.generateClientMetric {
+		relationWriter.afterWrite = func(relations []*report.Relation) {
+			tables.WriteClientMetric(relations, client.clientMetricWithUrl)
 		}
 	}
+	writers := []tableRunner{
+		newTableWriter(client, c.cameraEventGroups, client.getWriterConfig(c.cameraEventGroups.table), tables.WriteProfilingEvents),
+		newTableWriter(client, c.flameGraphs, client.getWriterConfig(c.flameGraphs.table), tables.WriteFlameGraph),
+		newTableWriter(client, c.jvmGcs, client.getWriterConfig(c.jvmGcs.table), tables.WriteJvmGcs),
+		newTableWriter(client, c.rawDatas, client.getWriterConfig(c.rawDatas.table), tables.WriteRawDatas),
+		newTableWriter(client, c.logs, client.getWriterConfig(c.logs.table), tables.WriteLogs),
+		newTableWriter(client, c.k8sEvents, client.getWriterConfig(c.k8sEvents.table), tables.WriteK8sEvents),
+		newTableWriter(client, c.spanTraces, client.getWriterConfig(c.spanTraces.table), tables.WriteSpanTraces),
+		newTableWriter(client, c.originxAppInfos, client.getWriterConfig(c.originxAppInfos.table), tables.WriteAppInfos),
+		newTableWriter(client, c.appHeartbeats, client.getWriterConfig(c.appHeartbeats.table), tables.WriteAppHeartbeats),
+		newTableWriter(client, c.cameraNodeReports, client.getWriterConfig(c.cameraNodeReports.table), tables.WriteSlowReports),
+		newTableWriter(client, c.cameraErrorReports, client.getWriterConfig(c.cameraErrorReports.table), tables.WriteErrorReports).
+			fanOut("error_propagation", tables.WriteErrorPropagations),
+		newTableWriter(client, c.cameraReportMetrics, client.getWriterConfig(c.cameraReportMetrics.table), tables.WriteReportMetrics),
+		newTableWriter(client, c.onoffMetrics, client.getWriterConfig(c.onoffMetrics.table), tables.WriteOnOffMetrics),
+		relationWriter,
+		newTableWriter(client, c.originxAgentEvents, client.getWriterConfig(c.originxAgentEvents.table), tables.WriteAgentEvents),
+	}
+	return writers
+}
+
+func (client *ClickHouseClient) getWriterConfig(table string) *writerConfig {
+	if writerCfg, found := client.tableWriterConfigs[table]; found {
+		return writerCfg
+	}
+	return client.defaultWriterConfig
 }
 
-func (client *ClickHouseClient) Stop() {
+// Stop stops the writers and flushes the cached datas for the last time,
+// the datas failed to write are spooled if spool is enabled.
+func (client *ClickHouseClient) Stop(ctx context.Context) {
 	close(client.stopChan)
+	client.writerGroup.Wait()
+
+	var wg sync.WaitGroup
+	for _, writer := range client.writers {
+		wg.Add(1)
+		go func(writer tableRunner) {
+			defer wg.Done()
+			writer.flush(ctx)
+		}(writer)
+	}
+	wg.Wait()
+	log.Println("[Stop ClickHouse] Cached datas are flushed")
 }
@@ -4,28 +4,30 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
-	"os"
-	"path/filepath"
-	"sort"
+	"path"
 	"strings"
-	"text/template"
+	"time"
 
-	_ "github.com/ClickHouse/clickhouse-go/v2" // For register database driver.
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
 const (
 	defaultDatabase = "default"
-	sqlCreateFolder = "sqlscript/create_table"
-	distributeSql   = "sqlscript/distributed-table.tmpl.sql"
-	migrateTableSql = "sqlscript/migrate.tmpl.sql"
 
 	templateCreateDb            = "CREATE DATABASE IF NOT EXISTS %s"
 	templateCreateDbWithCluster = "CREATE DATABASE IF NOT EXISTS %s ON CLUSTER %s"
 )
 
+// defaultTableHashKeys keeps the rows of same key in one shard, which is required by ReplacingMergeTree.
+var defaultTableHashKeys = map[string]string{
+	"originx_app_heartbeat": "cityHash64(node_ip, node_name)",
+}
+
 type ClickHouseInit struct {
 	endpoint      string
 	database      string
@@ -34,10 +36,14 @@ type ClickHouseInit struct {
 	userName      string
 	password      string
 	createTable   bool
+	migrate       bool
+	lockWait      time.Duration
 	defaultTTLDay uint
 	tableTTLs     map[string]uint
 	tableHashKeys map[string]string
+	scripts       *sqlScripts
 	conn          *sql.DB
+	batchConn     driver.Conn
 }
 
 func NewClickHouseInit(
@@ -48,9 +54,11 @@ func NewClickHouseInit(
 	userName string,
 	password string,
 	createTable bool,
+	migrate bool,
 	defaultTTLDay uint,
 	tableTTLs map[string]uint,
-	tableHashKeys map[string]string) *ClickHouseInit {
+	tableHashKeys map[string]string,
+	scriptDir string) *ClickHouseInit {
 	return &ClickHouseInit{
 		endpoint:      endpoint,
 		database:      database,
@@ -59,9 +67,11 @@ func NewClickHouseInit(
 		userName:      userName,
 		password:      password,
 		createTable:   createTable,
+		migrate:       migrate,
 		defaultTTLDay: defaultTTLDay,
 		tableTTLs:     tableTTLs,
 		tableHashKeys: tableHashKeys,
+		scripts:       newSqlScripts(scriptDir),
 	}
 }
 
@@ -69,14 +79,12 @@ func (ch *ClickHouseInit) GetConn() *sql.DB {
 	return ch.conn
 }
 
-func (ch *ClickHouseInit) Start() (err error) {
-	if ch.createTable {
-		if err = createDatabase(context.Background(), ch.endpoint, ch.database, ch.cluster, ch.userName, ch.password); err != nil {
-			return
-		}
-	}
+func (ch *ClickHouseInit) GetBatchConn() driver.Conn {
+	return ch.batchConn
+}
 
-	if ch.conn, err = buildDB(ch.endpoint, ch.database, ch.userName, ch.password); err != nil {
+func (ch *ClickHouseInit) Start() (err error) {
+	if err = ch.Connect(); err != nil {
 		return err
 	}
 
@@ -86,68 +94,48 @@ func (ch *ClickHouseInit) Start() (err error) {
 			return err
 		}
 	}
-	err = ch.runMigrateScripts()
-	if err != nil {
-		// Failed to run migrate scripts, maybe no need to migrate/upgrade.
-		// This is not an error case, just ignore and print the message.
-		log.Println(err)
+	if ch.migrate {
+		if _, err = ch.MigrateUp(context.Background()); err != nil {
+			if !errors.Is(err, errMigrationLockWait) {
+				return err
+			}
+			// The holder applies the pending migrations, start without waiting for it.
+			log.Printf("[x Migrate] %s, skip migrating on start", err.Error())
+		}
 	}
 	return nil
 }
 
-func (ch *ClickHouseInit) runInitScripts() error {
-	filePaths, err := walkMatch(sqlCreateFolder, "*.tmp.sql")
-	if err != nil {
-		return fmt.Errorf("could not list sql files: %q", err)
+// Connect creates the database if createTable is set and opens the connections.
+func (ch *ClickHouseInit) Connect() (err error) {
+	if ch.createTable {
+		if err = createDatabase(context.Background(), ch.endpoint, ch.database, ch.cluster, ch.userName, ch.password); err != nil {
+			return
+		}
 	}
-	sort.Strings(filePaths)
 
-	args := tableArgs{
-		TTLDay:      ch.defaultTTLDay,
-		Replication: ch.replication,
-		Cluster:     ch.cluster,
+	if ch.conn, err = buildDB(ch.endpoint, ch.database, ch.userName, ch.password); err != nil {
+		return err
 	}
-	distargs := distributedTableArgs{
-		Cluster:  ch.cluster,
-		Database: ch.database,
-		Hash:     "rand()",
+	if ch.batchConn, err = buildBatchConn(ch.endpoint, ch.database, ch.userName, ch.password); err != nil {
+		return err
 	}
-	sqlStatements := make([]string, 0)
-	for _, f := range filePaths {
-		_, fileName := filepath.Split(f)
-		tmpl, err := template.ParseFiles(filepath.Clean(f))
-		if err != nil {
-			return err
-		}
-		tableName := fileName[0 : len(fileName)-8]
-		if ttlDay, found := ch.tableTTLs[tableName]; found {
-			args.TTLDay = ttlDay
-		} else {
-			args.TTLDay = ch.defaultTTLDay
-		}
-		var rendered bytes.Buffer
-		if err := tmpl.Execute(&rendered, args); err != nil {
-			return err
-		}
-		sqlStatements = append(sqlStatements, rendered.String())
+	return nil
+}
 
-		if ch.cluster != "" {
-			disttmpl, err := template.ParseFiles(distributeSql)
-			if err != nil {
-				return err
-			}
-			distargs.Table = tableName
-			if hashKey, exist := ch.tableHashKeys[tableName]; exist {
-				distargs.Hash = hashKey
-			} else {
-				distargs.Hash = "rand()"
-			}
-			var distRendered bytes.Buffer
-			if err := disttmpl.Execute(&distRendered, distargs); err != nil {
-				return err
-			}
-			sqlStatements = append(sqlStatements, distRendered.String())
-		}
+func (ch *ClickHouseInit) Close() {
+	if ch.conn != nil {
+		_ = ch.conn.Close()
+	}
+	if ch.batchConn != nil {
+		_ = ch.batchConn.Close()
+	}
+}
+
+func (ch *ClickHouseInit) runInitScripts() error {
+	sqlStatements, err := ch.renderInitScripts()
+	if err != nil {
+		return err
 	}
 
 	for _, sqlStatement := range sqlStatements {
@@ -160,50 +148,98 @@ func (ch *ClickHouseInit) runInitScripts() error {
 	return nil
 }
 
-// runMigrateScripts Run scripts to upgrade the table schema
-func (ch *ClickHouseInit) runMigrateScripts() error {
-	fs, err := os.Stat(migrateTableSql)
-	if os.IsNotExist(err) {
-		return nil
+// renderInitScripts renders the sql to create all the tables.
+func (ch *ClickHouseInit) renderInitScripts() ([]string, error) {
+	filePaths, err := ch.scripts.glob(path.Join(sqlCreateFolder, "*.tmp.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list sql files: %q", err)
 	}
-	if fs.Size() < 100 {
-		return nil
+
+	sqlStatements := make([]string, 0)
+	for _, f := range filePaths {
+		fileName := path.Base(f)
+		tableName := fileName[0 : len(fileName)-8]
+		tableStatements, err := ch.renderTable(f, tableName, ch.tableArgsOf(tableName))
+		if err != nil {
+			return nil, err
+		}
+		sqlStatements = append(sqlStatements, tableStatements...)
 	}
+	return sqlStatements, nil
+}
 
-	args := distributedTableArgs{
-		Cluster:  ch.cluster,
-		Database: ch.database,
+func (ch *ClickHouseInit) tableArgsOf(tableName string) tableArgs {
+	args := tableArgs{
+		TTLDay:      ch.defaultTTLDay,
+		Replication: ch.replication,
+		Cluster:     ch.cluster,
+	}
+	if ttlDay, found := ch.tableTTLs[tableName]; found {
+		args.TTLDay = ttlDay
 	}
-	migrateTemplate, err := template.ParseFiles(migrateTableSql)
+	return args
+}
+
+// createSingleTable creates the table by create_table/<tableName>.tmp.sql, the other tables are not touched.
+func (ch *ClickHouseInit) createSingleTable(ctx context.Context, tableName string) error {
+	sqlStatements, err := ch.renderTable(path.Join(sqlCreateFolder, tableName+".tmp.sql"), tableName, ch.tableArgsOf(tableName))
 	if err != nil {
 		return err
 	}
-	var rendered bytes.Buffer
-	if err := migrateTemplate.Execute(&rendered, args); err != nil {
-		return err
+	for _, sqlStatement := range sqlStatements {
+		if _, err := ch.conn.ExecContext(ctx, sqlStatement); err != nil {
+			return fmt.Errorf("could not run sql %q: %q", sqlStatement, err)
+		}
 	}
-	sqlStatements := rendered.String()
-	log.Println("Try to run migrate scripts: " + sqlStatements)
-	err = executeMultiStatements(ch.conn, sqlStatements)
+	return nil
+}
+
+// renderTable renders the create table sql, and the distributed table sql if cluster is set.
+func (ch *ClickHouseInit) renderTable(name string, tableName string, args tableArgs) ([]string, error) {
+	tmpl, err := ch.scripts.parse(name)
 	if err != nil {
-		return fmt.Errorf("failed to run migrate sql, maybe no need to migrate/upgrade %q: %q", sqlStatements, err)
+		return nil, err
 	}
-	return nil
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, args); err != nil {
+		return nil, err
+	}
+	sqlStatements := []string{rendered.String()}
+
+	if ch.cluster != "" {
+		disttmpl, err := ch.scripts.parse(distributeSql)
+		if err != nil {
+			return nil, err
+		}
+		distargs := distributedTableArgs{
+			Cluster:  ch.cluster,
+			Database: ch.database,
+			Table:    tableName,
+			Hash:     "rand()",
+		}
+		if hashKey, exist := ch.tableHashKeys[tableName]; exist {
+			distargs.Hash = hashKey
+		} else if hashKey, exist := defaultTableHashKeys[tableName]; exist {
+			distargs.Hash = hashKey
+		}
+		var distRendered bytes.Buffer
+		if err := disttmpl.Execute(&distRendered, distargs); err != nil {
+			return nil, err
+		}
+		sqlStatements = append(sqlStatements, distRendered.String())
+	}
+	return sqlStatements, nil
 }
 
-func executeMultiStatements(conn *sql.DB, sqlStatements string) error {
-	statements := strings.Split(sqlStatements, ";")
+func executeStatements(ctx context.Context, conn *sql.DB, statements []string) error {
 	for _, stmt := range statements {
-		trimmedStmt := strings.TrimSpace(stmt)
-		if trimmedStmt != "" {
-			_, err := conn.ExecContext(context.Background(), trimmedStmt)
-			if err != nil {
-				if strings.Contains(trimmedStmt, "REMOVE TTL") {
-					// Ignore Remove TTL failed.
-					continue
-				}
-				return err
+		_, err := conn.ExecContext(ctx, stmt)
+		if err != nil {
+			if strings.Contains(stmt, "REMOVE TTL") {
+				// Ignore Remove TTL failed, the table may have no TTL.
+				continue
 			}
+			return err
 		}
 	}
 
@@ -281,26 +317,17 @@ func buildDB(endpoint string, database string, userName string, password string)
 	return conn, nil
 }
 
-func walkMatch(root, pattern string) ([]string, error) {
-	var matches []string
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if matched, err := filepath.Match(pattern, filepath.Base(path)); err != nil {
-			return err
-		} else if matched {
-			matches = append(matches, path)
-		}
-		return nil
-	})
+// buildBatchConn builds the native connection which supports PrepareBatch.
+func buildBatchConn(endpoint string, database string, userName string, password string) (driver.Conn, error) {
+	dsn, err := buildDSN(endpoint, database, userName, password)
+	if err != nil {
+		return nil, err
+	}
+	options, err := clickhouse.ParseDSN(dsn)
 	if err != nil {
 		return nil, err
 	}
-	return matches, nil
+	return clickhouse.Open(options)
 }
 
 type tableArgs struct {
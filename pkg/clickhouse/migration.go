@@ -0,0 +1,465 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+const (
+	MigrateActionUp     = "up"
+	MigrateActionStatus = "status"
+	MigrateActionDryRun = "dry-run"
+
+	migrationFolder     = "migrations"
+	migrationPattern    = "*.tmpl.sql"
+	migrationLedgerSql  = "schema_migrations.tmpl.sql"
+	migrationLedgerName = "schema_migrations"
+	migrationLockSql    = "schema_migrations_lock.tmpl.sql"
+	migrationLockName   = "schema_migrations_lock"
+
+	sqlExistMigrations  = "SELECT count() FROM system.tables WHERE database = currentDatabase() AND name = 'schema_migrations'"
+	sqlQueryMigrations  = "SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version, applied_at"
+	sqlInsertMigrations = "INSERT INTO schema_migrations (version, name, checksum, applied_at, duration_ms, hostname) VALUES (?, ?, ?, ?, ?, ?)"
+
+	// The lock is held by the first owner whose latest row is not expired, the owner releases it with an expired row.
+	sqlInsertMigrationLock = "INSERT INTO schema_migrations_lock (owner, acquired_at, expire_at) SELECT ?, now64(9), now64(9) + toIntervalSecond(?)"
+	sqlQueryMigrationLock  = `SELECT owner FROM (
+		SELECT owner, min(acquired_at) AS first_acquired_at, argMax(expire_at, acquired_at) AS last_expire_at
+		FROM schema_migrations_lock GROUP BY owner
+	) WHERE last_expire_at > now64(9) ORDER BY first_acquired_at, owner LIMIT 1`
+)
+
+var errMigrationLockWait = errors.New("timeout waiting for migration lock")
+
+var (
+	// migrationLockLease expires the lock of a receiver crashed when migrating, the holder renews it every third of lease.
+	migrationLockLease = 10 * time.Minute
+	// migrationLockSettle waits for the lock rows inserted by others at the same time.
+	migrationLockSettle = 2 * time.Second
+	migrationLockPoll   = 5 * time.Second
+)
+
+// Migration is one numbered file under migrations, named as <version>_<name>.tmpl.sql.
+type Migration struct {
+	Version    uint32
+	Name       string
+	Checksum   string
+	Statements []string
+}
+
+// MigrationStatus shows whether the migration is applied, Modified means the file is changed after applied.
+type MigrationStatus struct {
+	*Migration
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool
+}
+
+type appliedMigration struct {
+	version   uint32
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// loadMigrations reads and renders the migration files sorted by version.
+func loadMigrations(scripts *sqlScripts, args distributedTableArgs) ([]*Migration, error) {
+	filePaths, err := scripts.glob(path.Join(migrationFolder, migrationPattern))
+	if err != nil {
+		return nil, fmt.Errorf("could not list migration files: %w", err)
+	}
+
+	migrations := make([]*Migration, 0, len(filePaths))
+	versions := make(map[uint32]string)
+	for _, f := range filePaths {
+		fileName := strings.TrimSuffix(path.Base(f), ".tmpl.sql")
+		versionStr, name, found := strings.Cut(fileName, "_")
+		if !found {
+			return nil, fmt.Errorf("invalid migration file %s, expect <version>_<name>.tmpl.sql", f)
+		}
+		version, err := strconv.ParseUint(versionStr, 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version of %s", f)
+		}
+		if existFile, exist := versions[uint32(version)]; exist {
+			return nil, fmt.Errorf("duplicate migration version %d: %s, %s", version, existFile, f)
+		}
+		versions[uint32(version)] = f
+
+		content, err := scripts.readFile(f)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(fileName).Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, args); err != nil {
+			return nil, err
+		}
+		checksum := sha256.Sum256(content)
+		migrations = append(migrations, &Migration{
+			Version:    uint32(version),
+			Name:       name,
+			Checksum:   hex.EncodeToString(checksum[:]),
+			Statements: splitStatements(rendered.String()),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// splitStatements drops the comment lines and splits the script into statements.
+func splitStatements(script string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	statements := make([]string, 0)
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if trimmedStmt := strings.TrimSpace(stmt); trimmedStmt != "" {
+			statements = append(statements, trimmedStmt)
+		}
+	}
+	return statements
+}
+
+func (ch *ClickHouseInit) migrationArgs() distributedTableArgs {
+	return distributedTableArgs{
+		Cluster:  ch.cluster,
+		Database: ch.database,
+	}
+}
+
+// renderMigrationLedger renders the sql to create the schema_migrations and schema_migrations_lock tables,
+// which are distributed on cluster so that the applied versions and the lock are visible from every shard.
+func (ch *ClickHouseInit) renderMigrationLedger() ([]string, error) {
+	sqlStatements := make([]string, 0)
+	for _, table := range []struct{ sql, name string }{
+		{migrationLedgerSql, migrationLedgerName},
+		{migrationLockSql, migrationLockName},
+	} {
+		tableStatements, err := ch.renderTable(table.sql, table.name, tableArgs{
+			Replication: ch.replication,
+			Cluster:     ch.cluster,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sqlStatements = append(sqlStatements, tableStatements...)
+	}
+	return sqlStatements, nil
+}
+
+func (ch *ClickHouseInit) createMigrationLedger(ctx context.Context) error {
+	sqlStatements, err := ch.renderMigrationLedger()
+	if err != nil {
+		return err
+	}
+	for _, sqlStatement := range sqlStatements {
+		if _, err := ch.conn.ExecContext(ctx, sqlStatement); err != nil {
+			return fmt.Errorf("could not create migration ledger: %w", err)
+		}
+	}
+	return nil
+}
+
+// ledgerContext makes sure the inserted rows are visible for the next check once the insert returns.
+func (ch *ClickHouseInit) ledgerContext(ctx context.Context) context.Context {
+	if ch.cluster == "" {
+		return ctx
+	}
+	return clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"insert_distributed_sync": 1,
+	}))
+}
+
+// acquireMigrationLock blocks until this receiver is the first owner of schema_migrations_lock,
+// so that the receivers started at the same time never apply one migration twice.
+// It waits for lockWait at most if set, the lease is renewed by the holder until it is released.
+func (ch *ClickHouseInit) acquireMigrationLock(ctx context.Context) (release func(), err error) {
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+	lockCtx := ch.ledgerContext(ctx)
+	waitCtx := ctx
+	if ch.lockWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, ch.lockWait)
+		defer cancel()
+	}
+	var holder string
+	stopWait := func() error {
+		ch.releaseMigrationLock(owner)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w held by %s after %s", errMigrationLockWait, holder, ch.lockWait)
+	}
+
+	for {
+		// Renew the lease while waiting, the first acquired time is kept.
+		if err := ch.renewMigrationLock(lockCtx, owner); err != nil {
+			return nil, err
+		}
+		select {
+		case <-time.After(migrationLockSettle):
+		case <-waitCtx.Done():
+			return nil, stopWait()
+		}
+		// No rows if the inserted row is still not visible, try again.
+		if err := ch.conn.QueryRowContext(ctx, sqlQueryMigrationLock).Scan(&holder); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			ch.releaseMigrationLock(owner)
+			return nil, fmt.Errorf("failed to query %s: %w", migrationLockName, err)
+		}
+		if holder == owner {
+			return ch.keepMigrationLock(owner), nil
+		}
+		log.Printf("Wait for migration lock held by %s", holder)
+		select {
+		case <-time.After(migrationLockPoll):
+		case <-waitCtx.Done():
+			return nil, stopWait()
+		}
+	}
+}
+
+func (ch *ClickHouseInit) renewMigrationLock(ctx context.Context, owner string) error {
+	if _, err := ch.conn.ExecContext(ctx, sqlInsertMigrationLock, owner, int64(migrationLockLease.Seconds())); err != nil {
+		return fmt.Errorf("failed to insert %s: %w", migrationLockName, err)
+	}
+	return nil
+}
+
+// keepMigrationLock renews the lease of lock held until the returned release is called,
+// so that a long migration is not applied again by others after the lease.
+func (ch *ClickHouseInit) keepMigrationLock(owner string) (release func()) {
+	stopChan := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(migrationLockLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := ch.renewMigrationLock(ch.ledgerContext(ctx), owner); err != nil {
+					log.Printf("[x Renew Migration Lock] %s", err.Error())
+				}
+				cancel()
+			case <-stopChan:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stopChan)
+		<-stopped
+		ch.releaseMigrationLock(owner)
+	}
+}
+
+func (ch *ClickHouseInit) releaseMigrationLock(owner string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := ch.conn.ExecContext(ch.ledgerContext(ctx), sqlInsertMigrationLock, owner, 0); err != nil {
+		log.Printf("[x Release Migration Lock] %s, it is expired after %s", err.Error(), migrationLockLease)
+	}
+}
+
+func (ch *ClickHouseInit) queryAppliedMigrations(ctx context.Context) (map[uint32]*appliedMigration, error) {
+	applieds := make(map[uint32]*appliedMigration)
+	var count uint64
+	if err := ch.conn.QueryRowContext(ctx, sqlExistMigrations).Scan(&count); err != nil {
+		return nil, fmt.Errorf("query %s:%w", migrationLedgerName, err)
+	}
+	if count == 0 {
+		// No migration is applied before.
+		return applieds, nil
+	}
+
+	rows, err := ch.conn.QueryContext(ctx, sqlQueryMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("query %s:%w", migrationLedgerName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		applied := &appliedMigration{}
+		if err := rows.Scan(&applied.version, &applied.name, &applied.checksum, &applied.appliedAt); err != nil {
+			return nil, fmt.Errorf("scan %s:%w", migrationLedgerName, err)
+		}
+		if _, exist := applieds[applied.version]; !exist {
+			// Keep the first time it is applied.
+			applieds[applied.version] = applied
+		}
+	}
+	return applieds, rows.Err()
+}
+
+// MigrateStatus lists all the migrations and whether they are applied.
+func (ch *ClickHouseInit) MigrateStatus(ctx context.Context) ([]*MigrationStatus, error) {
+	migrations, err := loadMigrations(ch.scripts, ch.migrationArgs())
+	if err != nil {
+		return nil, err
+	}
+	applieds, err := ch.queryAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := &MigrationStatus{Migration: migration}
+		if applied, exist := applieds[migration.Version]; exist {
+			status.Applied = true
+			status.AppliedAt = applied.appliedAt
+			status.Modified = applied.checksum != migration.Checksum
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// MigratePending lists the migrations not applied yet, which are what MigrateUp will run.
+func (ch *ClickHouseInit) MigratePending(ctx context.Context) ([]*Migration, error) {
+	statuses, err := ch.MigrateStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pendings := make([]*Migration, 0)
+	for _, status := range statuses {
+		if !status.Applied {
+			pendings = append(pendings, status.Migration)
+		}
+	}
+	return pendings, nil
+}
+
+// MigrateUp applies the pending migrations in order and records each one in schema_migrations.
+// It stops at the first failed migration, so the later ones are not applied out of order.
+// The pending migrations are checked after schema_migrations_lock is acquired, so each one is applied once.
+func (ch *ClickHouseInit) MigrateUp(ctx context.Context) ([]*Migration, error) {
+	if err := ch.createMigrationLedger(ctx); err != nil {
+		return nil, err
+	}
+	release, err := ch.acquireMigrationLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	pendings, err := ch.MigratePending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	ledgerCtx := ch.ledgerContext(ctx)
+
+	applieds := make([]*Migration, 0, len(pendings))
+	for _, migration := range pendings {
+		log.Printf("Apply migration %d_%s", migration.Version, migration.Name)
+		startTime := time.Now()
+		if err := executeStatements(ctx, ch.conn, migration.Statements); err != nil {
+			return applieds, fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		duration := time.Since(startTime)
+		if _, err := ch.conn.ExecContext(ledgerCtx, sqlInsertMigrations,
+			migration.Version, migration.Name, migration.Checksum, startTime, uint64(duration.Milliseconds()), hostname); err != nil {
+			return applieds, fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		applieds = append(applieds, migration)
+	}
+	return applieds, nil
+}
+
+// RunMigrate runs the migrate action(up / status / dry-run) and prints the result to out.
+func RunMigrate(ctx context.Context, cfg *config.ClickHouseConfig, action string, out io.Writer) error {
+	if cfg.Endpoint == "" {
+		return errConfigNoEndpoint
+	}
+	// Only up changes the schema, status and dry-run never create anything.
+	init := newClickHouseInit(cfg, action == MigrateActionUp, false)
+	switch action {
+	case MigrateActionUp:
+		if err := init.Start(); err != nil {
+			return err
+		}
+		defer init.Close()
+		applieds, err := init.MigrateUp(ctx)
+		for _, migration := range applieds {
+			fmt.Fprintf(out, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applieds) == 0 {
+			fmt.Fprintln(out, "no pending migration")
+		}
+	case MigrateActionStatus:
+		if err := init.Connect(); err != nil {
+			return err
+		}
+		defer init.Close()
+		statuses, err := init.MigrateStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied at " + status.AppliedAt.Format(time.RFC3339)
+				if status.Modified {
+					state += " (modified after applied)"
+				}
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	case MigrateActionDryRun:
+		if err := init.Connect(); err != nil {
+			return err
+		}
+		defer init.Close()
+		pendings, err := init.MigratePending(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range pendings {
+			fmt.Fprintf(out, "-- %04d_%s\n", migration.Version, migration.Name)
+			for _, statement := range migration.Statements {
+				fmt.Fprintf(out, "%s;\n", statement)
+			}
+		}
+		if len(pendings) == 0 {
+			fmt.Fprintln(out, "-- no pending migration")
+		}
+	default:
+		return fmt.Errorf("unknown migrate action %q, expect %s / %s / %s", action, MigrateActionUp, MigrateActionStatus, MigrateActionDryRun)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/clickhouse/tables"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+const replayReportTable = "replay_report"
+
+// ReplayClient reads the span traces for the offline replay, it never creates or migrates the tables
+// except replay_report when the results are stored, and no data is cached or spooled.
+type ReplayClient struct {
+	init      *ClickHouseInit
+	conn      *sql.DB
+	batchConn driver.Conn
+}
+
+func NewReplayClient(ctx context.Context, cfg *config.ClickHouseConfig, storeResults bool) (*ReplayClient, error) {
+	if cfg.Endpoint == "" {
+		return nil, errConfigNoEndpoint
+	}
+	init := newClickHouseInit(cfg, false, false)
+	if err := init.Connect(); err != nil {
+		return nil, err
+	}
+	if storeResults {
+		if err := init.createSingleTable(ctx, replayReportTable); err != nil {
+			init.Close()
+			return nil, err
+		}
+	}
+	return &ReplayClient{
+		init:      init,
+		conn:      init.GetConn(),
+		batchConn: init.GetBatchConn(),
+	}, nil
+}
+
+func (client *ReplayClient) QueryTraces(ctx context.Context, traceId string) (*model.Traces, error) {
+	return tables.QueryTraces(ctx, client.conn, traceId)
+}
+
+func (client *ReplayClient) QueryTracesByTime(ctx context.Context, startTime time.Time, endTime time.Time, limit int) ([]*model.Traces, error) {
+	return tables.QueryTracesByTime(ctx, client.conn, startTime, endTime, limit)
+}
+
+func (client *ReplayClient) StoreReplayResults(ctx context.Context, results []*report.ReplayResult) error {
+	return tables.WriteReplayReports(ctx, client.batchConn, results)
+}
+
+func (client *ReplayClient) Close() {
+	client.init.Close()
+}
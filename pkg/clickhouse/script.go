@@ -0,0 +1,111 @@
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/sqlscript"
+)
+
+const (
+	sqlCreateFolder = "create_table"
+	distributeSql   = "distributed-table.tmpl.sql"
+)
+
+// sqlScripts reads the sql templates embedded in the binary,
+// a file with the same relative path under the override dir takes precedence.
+type sqlScripts struct {
+	overrideDir string
+	overrides   fs.FS
+}
+
+func newSqlScripts(overrideDir string) *sqlScripts {
+	scripts := &sqlScripts{overrideDir: overrideDir}
+	if overrideDir != "" {
+		scripts.overrides = os.DirFS(overrideDir)
+		_ = fs.WalkDir(scripts.overrides, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				log.Printf("[x Read Sql Script] %s", err.Error())
+				return nil
+			}
+			if !d.IsDir() {
+				log.Printf("Use the sql script %s from %s", path, overrideDir)
+			}
+			return nil
+		})
+	}
+	return scripts
+}
+
+func (s *sqlScripts) readFile(name string) ([]byte, error) {
+	if s.overrides != nil {
+		content, err := fs.ReadFile(s.overrides, name)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return fs.ReadFile(sqlscript.Scripts, name)
+}
+
+// glob lists the embedded files and the override files matching the pattern, sorted by name.
+func (s *sqlScripts) glob(pattern string) ([]string, error) {
+	names, err := fs.Glob(sqlscript.Scripts, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if s.overrides != nil {
+		overrideNames, err := fs.Glob(s.overrides, pattern)
+		if err != nil {
+			return nil, err
+		}
+		exists := make(map[string]bool, len(names))
+		for _, name := range names {
+			exists[name] = true
+		}
+		for _, name := range overrideNames {
+			if !exists[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *sqlScripts) parse(name string) (*template.Template, error) {
+	content, err := s.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(name).Parse(string(content))
+}
+
+// DumpDDL prints the sql to create the tables rendered by cfg, without connecting to clickhouse.
+func DumpDDL(cfg *config.ClickHouseConfig, out io.Writer) error {
+	init := newClickHouseInit(cfg, true, false)
+	sqlStatements, err := init.renderInitScripts()
+	if err != nil {
+		return err
+	}
+	ledgerStatements, err := init.renderMigrationLedger()
+	if err != nil {
+		return err
+	}
+	for _, sqlStatement := range append(sqlStatements, ledgerStatements...) {
+		if _, err := fmt.Fprintf(out, "%s;\n\n", strings.TrimSpace(sqlStatement)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	metricModel "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+)
+
+const (
+	spoolSegmentSuffix = ".json"
+	spoolTempSuffix    = ".tmp"
+	defaultSpoolMaxMB  = 1024
+)
+
+// spool persists the batches which failed to write into clickhouse.
+// Each failed batch is stored as a segment file under <dir>/<table>/ and replayed in order,
+// the oldest segments are evicted when the total size exceeds maxBytes.
+type spool struct {
+	dir        string
+	maxBytes   int64
+	totalBytes int64
+	seq        uint64
+	tables     map[string][]*spoolSegment
+	lock       sync.Mutex
+}
+
+type spoolSegment struct {
+	table string
+	path  string
+	size  int64
+}
+
+func newSpool(dir string, maxMB uint) (*spool, error) {
+	if maxMB == 0 {
+		maxMB = defaultSpoolMaxMB
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create spool dir %s: %w", dir, err)
+	}
+	spool := &spool{
+		dir:      dir,
+		maxBytes: int64(maxMB) * 1024 * 1024,
+		tables:   make(map[string][]*spoolSegment),
+	}
+	if err := spool.load(); err != nil {
+		return nil, err
+	}
+	return spool, nil
+}
+
+// load recovers the segments left by last run.
+func (s *spool) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("read spool dir %s: %w", s.dir, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		table := entry.Name()
+		files, err := os.ReadDir(filepath.Join(s.dir, table))
+		if err != nil {
+			return fmt.Errorf("read spool dir %s: %w", table, err)
+		}
+		segments := make([]*spoolSegment, 0)
+		for _, file := range files {
+			path := filepath.Join(s.dir, table, file.Name())
+			if strings.HasSuffix(file.Name(), spoolTempSuffix) {
+				// Partial write before crash.
+				os.Remove(path)
+				continue
+			}
+			if file.IsDir() || !strings.HasSuffix(file.Name(), spoolSegmentSuffix) {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			segments = append(segments, &spoolSegment{
+				table: table,
+				path:  path,
+				size:  info.Size(),
+			})
+		}
+		if len(segments) == 0 {
+			continue
+		}
+		sort.Slice(segments, func(i, j int) bool {
+			return segments[i].path < segments[j].path
+		})
+		s.tables[table] = segments
+		for _, segment := range segments {
+			s.totalBytes += segment.size
+			s.updateDepth(table, 1, segment.size)
+		}
+		log.Printf("[Recover Spool] Table: %s, Segments: %d", table, len(segments))
+	}
+	return nil
+}
+
+// save writes the batch as a new segment of the table.
+func (s *spool) save(table string, datas interface{}) error {
+	content, err := json.Marshal(datas)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", table, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tableDir := filepath.Join(s.dir, table)
+	if err := os.MkdirAll(tableDir, 0755); err != nil {
+		return fmt.Errorf("create spool dir %s: %w", table, err)
+	}
+	s.seq++
+	// Nano timestamp with fixed width keeps the file names sorted in write order.
+	path := filepath.Join(tableDir, fmt.Sprintf("%020d-%010d%s", time.Now().UnixNano(), s.seq, spoolSegmentSuffix))
+	tmpPath := path + spoolTempSuffix
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write spool %s: %w", table, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write spool %s: %w", table, err)
+	}
+
+	segment := &spoolSegment{
+		table: table,
+		path:  path,
+		size:  int64(len(content)),
+	}
+	s.tables[table] = append(s.tables[table], segment)
+	s.totalBytes += segment.size
+	s.updateDepth(table, 1, segment.size)
+
+	for s.totalBytes > s.maxBytes {
+		if !s.evictOldest() {
+			break
+		}
+	}
+	return nil
+}
+
+// evictOldest removes the oldest segment among all tables.
+func (s *spool) evictOldest() bool {
+	var oldest *spoolSegment
+	for _, segments := range s.tables {
+		if len(segments) == 0 {
+			continue
+		}
+		if oldest == nil || filepath.Base(segments[0].path) < filepath.Base(oldest.path) {
+			oldest = segments[0]
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	log.Printf("[x Evict Spool] Table: %s, Segment: %s, Size: %d, Spool is over %d bytes",
+		oldest.table, filepath.Base(oldest.path), oldest.size, s.maxBytes)
+	s.removeLocked(oldest)
+	metrics.UpdateMetric(metricModel.MetricClickHouseSpoolEvictedCount, []string{oldest.table}, 1)
+	return true
+}
+
+func (s *spool) removeLocked(segment *spoolSegment) {
+	segments := s.tables[segment.table]
+	found := false
+	for i, exist := range segments {
+		if exist == segment {
+			s.tables[segment.table] = append(segments[:i], segments[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		// Already evicted.
+		return
+	}
+	if len(s.tables[segment.table]) == 0 {
+		delete(s.tables, segment.table)
+	}
+	if err := os.Remove(segment.path); err != nil && !os.IsNotExist(err) {
+		log.Printf("[x Remove Spool] %s", err.Error())
+	}
+	s.totalBytes -= segment.size
+	s.updateDepth(segment.table, -1, -segment.size)
+}
+
+func (s *spool) first(table string) *spoolSegment {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if segments := s.tables[table]; len(segments) > 0 {
+		return segments[0]
+	}
+	return nil
+}
+
+// replay writes the segments of the table in order and removes them after succeed.
+// It stops at the first failed segment so the later batches are kept in order.
+func (s *spool) replay(table string, write func(content []byte) error) error {
+	for {
+		segment := s.first(table)
+		if segment == nil {
+			return nil
+		}
+		content, err := os.ReadFile(segment.path)
+		if err == nil {
+			err = write(content)
+			if err != nil {
+				return err
+			}
+			metrics.UpdateMetric(metricModel.MetricClickHouseSpoolReplayedCount, []string{table}, 1)
+		} else {
+			log.Printf("[x Read Spool] %s, Skip.", err.Error())
+		}
+
+		s.lock.Lock()
+		s.removeLocked(segment)
+		s.lock.Unlock()
+	}
+}
+
+func (s *spool) updateDepth(table string, segments int, bytes int64) {
+	metrics.UpdateMetric(metricModel.MetricClickHouseSpoolSegments, []string{table}, float64(segments))
+	metrics.UpdateMetric(metricModel.MetricClickHouseSpoolBytes, []string{table}, float64(bytes))
+}
+
+// writeWithSpool replays the spooled batches of the table before writing toSends,
+// toSends is spooled when the write failed or the older batches are still not replayed.
+func writeWithSpool[T any](ctx context.Context, client *ClickHouseClient, table string, toSends []T,
+	write func(ctx context.Context, conn driver.Conn, toSends []T) error) error {
+	if client.spool == nil {
+		return write(ctx, client.batchConn, toSends)
+	}
+
+	err := client.spool.replay(table, func(content []byte) error {
+		var datas []T
+		if err := json.Unmarshal(content, &datas); err != nil {
+			log.Printf("[x Parse Spool] %s, Table: %s, Skip.", err.Error(), table)
+			return nil
+		}
+		return write(ctx, client.batchConn, datas)
+	})
+	if err == nil {
+		err = write(ctx, client.batchConn, toSends)
+	}
+	if err != nil && len(toSends) > 0 {
+		if spoolErr := client.spool.save(table, toSends); spoolErr != nil {
+			log.Printf("[x Spool %s] %s", table, spoolErr.Error())
+		}
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package clickhouse
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpoolReplayInOrder(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := newSpool(dir, 1)
+	assert.NoError(t, err)
+
+	assert.NoError(t, spool.save("span_trace", []string{"a", "b"}))
+	assert.NoError(t, spool.save("span_trace", []string{"c"}))
+	assert.NoError(t, spool.save("jvm_gc", []string{"gc"}))
+
+	// Failed replay keeps all segments.
+	err = spool.replay("span_trace", func(content []byte) error {
+		return errors.New("connection refused")
+	})
+	assert.Error(t, err)
+
+	// Segments are recovered after restart.
+	spool, err = newSpool(dir, 1)
+	assert.NoError(t, err)
+
+	replayed := make([]string, 0)
+	err = spool.replay("span_trace", func(content []byte) error {
+		var datas []string
+		if err := json.Unmarshal(content, &datas); err != nil {
+			return err
+		}
+		replayed = append(replayed, datas...)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, replayed)
+	assert.Nil(t, spool.first("span_trace"))
+	assert.NotNil(t, spool.first("jvm_gc"))
+}
+
+func TestSpoolEvictOldest(t *testing.T) {
+	spool, err := newSpool(t.TempDir(), 1)
+	assert.NoError(t, err)
+	spool.maxBytes = 12
+
+	assert.NoError(t, spool.save("span_trace", []string{"oldest"}))
+	assert.NoError(t, spool.save("jvm_gc", []string{"older"}))
+	assert.NoError(t, spool.save("span_trace", []string{"newest"}))
+
+	assert.LessOrEqual(t, spool.totalBytes, spool.maxBytes)
+	assert.Nil(t, spool.first("jvm_gc"))
+
+	replayed := make([]string, 0)
+	spool.replay("span_trace", func(content []byte) error {
+		var datas []string
+		json.Unmarshal(content, &datas)
+		replayed = append(replayed, datas...)
+		return nil
+	})
+	assert.Equal(t, []string{"newest"}, replayed)
+}
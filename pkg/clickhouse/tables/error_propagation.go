@@ -2,9 +2,10 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
 )
 
@@ -42,19 +43,12 @@ const (
 	)`
 )
 
-func WriteErrorPropagations(ctx context.Context, conn *sql.DB, toSends []*report.ErrorReport) error {
+func WriteErrorPropagations(ctx context.Context, conn driver.Conn, toSends []*report.ErrorReport) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertErrorPropagationSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
+	err := doWithBatch(ctx, conn, insertErrorPropagationSQL, func(batch driver.Batch) error {
 		for _, errorReport := range toSends {
 			if errorReport.IsDrop || errorReport.Data.RelationTree == nil {
 				continue
@@ -62,7 +56,7 @@ func WriteErrorPropagations(ctx context.Context, conn *sql.DB, toSends []*report
 
 			rootNode := errorReport.Data.RelationTree
 			errorPropagation := report.NewErrorPropagation(rootNode)
-			if _, err = statement.ExecContext(ctx,
+			if err := batch.Append(
 				asTime(int64(errorReport.Timestamp)), // NanoTime
 				rootNode.ServiceName,
 				rootNode.Url,
@@ -78,7 +72,7 @@ func WriteErrorPropagations(ctx context.Context, conn *sql.DB, toSends []*report
 				errorPropagation.GetDepthList(),
 				errorPropagation.GetPathList()); err != nil {
 
-				return fmt.Errorf("ExecContext:%w", err)
+				return fmt.Errorf("Append:%w", err)
 			}
 		}
 		return nil
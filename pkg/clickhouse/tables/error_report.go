@@ -2,10 +2,11 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
 )
 
@@ -17,6 +18,7 @@ const (
 		duration,
 		end_time,
 		drop_reason,
+		drop_reason_code,
 		labels,
 		cause,
 		cause_message,
@@ -37,24 +39,18 @@ const (
         ?,
         ?,
         ?,
+        ?,
         ?,
 		?
 	)`
 )
 
-func WriteErrorReports(ctx context.Context, conn *sql.DB, toSends []*report.ErrorReport) error {
+func WriteErrorReports(ctx context.Context, conn driver.Conn, toSends []*report.ErrorReport) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertErrorReportSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
+	err := doWithBatch(ctx, conn, insertErrorReportSQL, func(batch driver.Batch) error {
 		for _, errorReport := range toSends {
 			relationTrees := ""
 			if errorReport.Data.RelationTree != nil {
@@ -75,13 +71,14 @@ func WriteErrorReports(ctx context.Context, conn *sql.DB, toSends []*report.Erro
 				"mutated_workload_type": errorReport.Data.MutatedWorkloadType,
 				"content_key":           errorReport.Data.ContentKey,
 			}
-			if _, err = statement.ExecContext(ctx,
+			if err := batch.Append(
 				asTime(int64(errorReport.Timestamp)), // NanoTime
 				errorReport.IsDrop,
 				errorReport.TraceId,
 				errorReport.Duration,
 				errorReport.Data.EndTime,
 				errorReport.Data.DropReason,
+				string(errorReport.Data.DropReasonCode),
 				labels,
 				errorReport.Data.Cause,
 				errorReport.Data.CauseMessage,
@@ -91,7 +88,7 @@ func WriteErrorReports(ctx context.Context, conn *sql.DB, toSends []*report.Erro
 				errorReport.Data.ThresholdValue,
 				errorReport.Data.ThresholdMultiple); err != nil {
 
-				return fmt.Errorf("ExecContext:%w", err)
+				return fmt.Errorf("Append:%w", err)
 			}
 		}
 		return nil
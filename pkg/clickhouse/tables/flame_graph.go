@@ -2,11 +2,12 @@ package tables
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 )
 
 const (
@@ -31,48 +32,19 @@ const (
 	)`
 )
 
-type FlameGraphEvent struct {
-	Pid         uint32 `json:"pid"`
-	Tid         uint32 `json:"tid"`
-	ContainerId string `json:"container_id"`
-	NsPid       int    `json:"ns_pid"`
-	NodeName    string `json:"node_name"`
-	NodeIp      string `json:"node_ip"`
-	ClusterID   string `json:"cluster_id"`
-	SampleType  string `json:"sample_type"`
-	SampleRate  uint32 `json:"sample_rate"`
-	TraceId     string `json:"trace_id"`
-	SpanId      string `json:"span_id"`
-	Flamebearer string `json:"flamebearer"`
-	StartTime   uint64 `json:"start_time"`
-	EndTime     uint64 `json:"end_time"`
-}
-
-func WriteFlameGraph(ctx context.Context, conn *sql.DB, toSends []string) error {
+func WriteFlameGraph(ctx context.Context, conn driver.Conn, toSends []*grpc_model.FlameGraph) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertFlameGraphSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
-		for _, flameGraphJson := range toSends {
-			flameGraphEvent := &FlameGraphEvent{}
-			if err := json.Unmarshal([]byte(flameGraphJson), flameGraphEvent); err != nil {
-				log.Printf("[x Parse Flame Graph] Error: %s", err.Error())
-				continue
-			}
+	err := doWithBatch(ctx, conn, insertFlameGraphSQL, func(batch driver.Batch) error {
+		for _, flameGraphEvent := range toSends {
 			labels := map[string]string{
 				"node_name":    flameGraphEvent.NodeName,
 				"node_ip":      flameGraphEvent.NodeIp,
-				"cluster_id":   flameGraphEvent.ClusterID,
+				"cluster_id":   flameGraphEvent.ClusterId,
 				"container_id": flameGraphEvent.ContainerId,
-				"ns_pid":       strconv.Itoa(flameGraphEvent.NsPid),
+				"ns_pid":       strconv.Itoa(int(flameGraphEvent.NsPid)),
 			}
 			if len(flameGraphEvent.TraceId) > 0 {
 				labels["trace_id"] = flameGraphEvent.TraceId
@@ -80,7 +52,7 @@ func WriteFlameGraph(ctx context.Context, conn *sql.DB, toSends []string) error
 			if len(flameGraphEvent.SpanId) > 0 {
 				labels["span_id"] = flameGraphEvent.SpanId
 			}
-			if _, err = statement.ExecContext(ctx,
+			if err := batch.Append(
 				asTime(int64(flameGraphEvent.StartTime)),
 				asTime(int64(flameGraphEvent.EndTime)),
 				flameGraphEvent.Pid,
@@ -90,7 +62,7 @@ func WriteFlameGraph(ctx context.Context, conn *sql.DB, toSends []string) error
 				labels,
 				flameGraphEvent.Flamebearer); err != nil {
 
-				return fmt.Errorf("ExecContext:%w", err)
+				return fmt.Errorf("Append:%w", err)
 			}
 		}
 		return nil
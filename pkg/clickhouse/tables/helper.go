@@ -2,23 +2,38 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
-func doWithTx(_ context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
-	tx, err := db.Begin()
+func doWithBatch(ctx context.Context, conn driver.Conn, query string, fn func(batch driver.Batch) error) error {
+	batch, err := conn.PrepareBatch(ctx, query)
 	if err != nil {
-		return fmt.Errorf("db.Begin: %w", err)
+		return fmt.Errorf("PrepareBatch:%w", err)
 	}
 	defer func() {
-		_ = tx.Rollback()
+		// No-op if the batch is sent.
+		_ = batch.Abort()
 	}()
-	if err := fn(tx); err != nil {
+	if err := fn(batch); err != nil {
 		return err
 	}
-	return tx.Commit()
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("Send:%w", err)
+	}
+	return nil
+}
+
+// appendColumns appends the datas by column instead of row, columns are in the order of insert sql.
+func appendColumns(batch driver.Batch, columns ...interface{}) error {
+	for i, column := range columns {
+		if err := batch.Column(i).Append(column); err != nil {
+			return fmt.Errorf("Append column %d:%w", i, err)
+		}
+	}
+	return nil
 }
 
 // asTime converts this to a time.Time.
@@ -0,0 +1,114 @@
+package tables
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+const (
+	// insertOtelLogSQL is shared by the tables of OTel log schema.
+	insertOtelLogSQL = `INSERT INTO %s (
+		Timestamp,
+		TraceId,
+		SpanId,
+		TraceFlags,
+		SeverityText,
+		SeverityNumber,
+		ServiceName,
+		Body,
+		ResourceSchemaUrl,
+		ResourceAttributes,
+		ScopeSchemaUrl,
+		ScopeName,
+		ScopeVersion,
+		ScopeAttributes,
+		LogAttributes
+	) VALUES (
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?
+	)`
+)
+
+func WriteLogs(ctx context.Context, conn driver.Conn, toSends []*grpc_model.LogRecord) error {
+	return writeOtelLogs(ctx, conn, "ilogtail_logs", toSends)
+}
+
+func writeOtelLogs(ctx context.Context, conn driver.Conn, table string, toSends []*grpc_model.LogRecord) error {
+	if len(toSends) == 0 {
+		return nil
+	}
+
+	size := len(toSends)
+	var (
+		timestamps         = make([]time.Time, 0, size)
+		traceIds           = make([]string, 0, size)
+		spanIds            = make([]string, 0, size)
+		traceFlags         = make([]uint32, 0, size)
+		severityTexts      = make([]string, 0, size)
+		severityNumbers    = make([]int32, 0, size)
+		serviceNames       = make([]string, 0, size)
+		bodies             = make([]string, 0, size)
+		resourceSchemaUrls = make([]string, 0, size)
+		resourceAttributes = make([]map[string]string, 0, size)
+		scopeSchemaUrls    = make([]string, 0, size)
+		scopeNames         = make([]string, 0, size)
+		scopeVersions      = make([]string, 0, size)
+		scopeAttributes    = make([]map[string]string, 0, size)
+		logAttributes      = make([]map[string]string, 0, size)
+	)
+	for _, toSend := range toSends {
+		timestamps = append(timestamps, asTime(int64(toSend.Timestamp))) // NanoTime
+		traceIds = append(traceIds, toSend.TraceId)
+		spanIds = append(spanIds, toSend.SpanId)
+		traceFlags = append(traceFlags, toSend.TraceFlags)
+		severityTexts = append(severityTexts, toSend.SeverityText)
+		severityNumbers = append(severityNumbers, toSend.SeverityNumber)
+		serviceNames = append(serviceNames, toSend.ServiceName)
+		bodies = append(bodies, toSend.Body)
+		resourceSchemaUrls = append(resourceSchemaUrls, toSend.ResourceSchemaUrl)
+		resourceAttributes = append(resourceAttributes, toSend.ResourceAttributes)
+		scopeSchemaUrls = append(scopeSchemaUrls, toSend.ScopeSchemaUrl)
+		scopeNames = append(scopeNames, toSend.ScopeName)
+		scopeVersions = append(scopeVersions, toSend.ScopeVersion)
+		scopeAttributes = append(scopeAttributes, toSend.ScopeAttributes)
+		logAttributes = append(logAttributes, toSend.LogAttributes)
+	}
+
+	return doWithBatch(ctx, conn, fmt.Sprintf(insertOtelLogSQL, table), func(batch driver.Batch) error {
+		return appendColumns(batch,
+			timestamps,
+			traceIds,
+			spanIds,
+			traceFlags,
+			severityTexts,
+			severityNumbers,
+			serviceNames,
+			bodies,
+			resourceSchemaUrls,
+			resourceAttributes,
+			scopeSchemaUrls,
+			scopeNames,
+			scopeVersions,
+			scopeAttributes,
+			logAttributes,
+		)
+	})
+}
@@ -2,10 +2,11 @@ package tables
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
 	"fmt"
-	"log"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 )
 
 const (
@@ -36,47 +37,19 @@ const (
 	)`
 )
 
-type JvmGcInfo struct {
-	Pid              string `json:"pid"`
-	NodeName         string `json:"node_name"`
-	NodeIp           string `json:"node_ip"`
-	ClusterID        string `json:"cluster_id"`
-	Ygc              int64  `json:"ygc"`
-	Fgc              int64  `json:"fgc"`
-	LastYgc          int64  `json:"last_ygc"`
-	LastFgc          int64  `json:"last_fgc"`
-	YgcLastEntryTime int64  `json:"ygc_last_entry_time"`
-	FgcLastEntryTime int64  `json:"fgc_last_entry_time"`
-	YgcSpan          int64  `json:"ygc_span"`
-	FgcSpan          int64  `json:"fgc_span"`
-	Timestamp        uint64 `json:"timestamp"`
-}
-
-func WriteJvmGcs(ctx context.Context, conn *sql.DB, toSends []string) error {
+func WriteJvmGcs(ctx context.Context, conn driver.Conn, toSends []*grpc_model.JvmGc) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertJvmGcSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
-		for _, jvmGcJson := range toSends {
-			jvmGc := &JvmGcInfo{}
-			if err := json.Unmarshal([]byte(jvmGcJson), jvmGc); err != nil {
-				log.Printf("[x Parse Jvm Gc] Error: %s", err.Error())
-				continue
-			}
+	err := doWithBatch(ctx, conn, insertJvmGcSQL, func(batch driver.Batch) error {
+		for _, jvmGc := range toSends {
 			labels := map[string]string{
 				"node_name":  jvmGc.NodeName,
 				"node_ip":    jvmGc.NodeIp,
-				"cluster_id": jvmGc.ClusterID,
+				"cluster_id": jvmGc.ClusterId,
 			}
-			_, err = statement.ExecContext(ctx,
+			err := batch.Append(
 				asTime(int64(jvmGc.Timestamp)), // NanoTime
 				jvmGc.Pid,
 				labels,
@@ -90,7 +63,7 @@ func WriteJvmGcs(ctx context.Context, conn *sql.DB, toSends []string) error {
 				jvmGc.FgcSpan,
 			)
 			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
+				return fmt.Errorf("Append:%w", err)
 			}
 		}
 		return nil
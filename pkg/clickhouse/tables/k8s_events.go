@@ -0,0 +1,14 @@
+package tables
+
+import (
+	"context"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+// WriteK8sEvents writes the k8s events which are normalized as OTel logs.
+func WriteK8sEvents(ctx context.Context, conn driver.Conn, toSends []*grpc_model.LogRecord) error {
+	return writeOtelLogs(ctx, conn, "k8s_events", toSends)
+}
@@ -2,10 +2,11 @@ package tables
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
-	"fmt"
-	"log"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 )
 
 const (
@@ -28,48 +29,32 @@ const (
 	)`
 )
 
-type OnOffMetric struct {
-	Timestamp   uint64 `json:"timestamp"`
-	Pid         uint32 `json:"pid"`
-	Tid         uint32 `json:"tid"`
-	ContainerId string `json:"container_id"`
-	TraceId     string `json:"trace_id"`
-	SpanId      string `json:"span_id"`
-	Metrics     string `json:"metrics"`
-}
-
-func WriteOnOffMetrics(ctx context.Context, conn *sql.DB, toSends []string) error {
+func WriteOnOffMetrics(ctx context.Context, conn driver.Conn, toSends []*grpc_model.OnOffMetricGroup) error {
 	if len(toSends) == 0 {
 		return nil
 	}
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertOnoffMetricSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
-		for _, onoffJson := range toSends {
-			onOffMetric := &OnOffMetric{}
-			if err := json.Unmarshal([]byte(onoffJson), onOffMetric); err != nil {
-				log.Printf("[x Parse Onoff Metric] Error: %s", err.Error())
-				continue
-			}
-			_, err = statement.ExecContext(ctx,
-				asTime(int64(onOffMetric.Timestamp)), // NanoTime
-				onOffMetric.Pid,
-				onOffMetric.Tid,
-				onOffMetric.ContainerId,
-				onOffMetric.TraceId,
-				onOffMetric.SpanId,
-				onOffMetric.Metrics,
-			)
-			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
-			}
-		}
-		return nil
+
+	size := len(toSends)
+	var (
+		timestamps   = make([]time.Time, 0, size)
+		pids         = make([]uint32, 0, size)
+		tids         = make([]uint32, 0, size)
+		containerIds = make([]string, 0, size)
+		traceIds     = make([]string, 0, size)
+		spanIds      = make([]string, 0, size)
+		metrics      = make([]string, 0, size)
+	)
+	for _, onOffMetric := range toSends {
+		timestamps = append(timestamps, asTime(int64(onOffMetric.Timestamp))) // NanoTime
+		pids = append(pids, onOffMetric.Pid)
+		tids = append(tids, onOffMetric.Tid)
+		containerIds = append(containerIds, onOffMetric.ContainerId)
+		traceIds = append(traceIds, onOffMetric.TraceId)
+		spanIds = append(spanIds, onOffMetric.SpanId)
+		metrics = append(metrics, onOffMetric.Metrics)
+	}
+
+	return doWithBatch(ctx, conn, insertOnoffMetricSQL, func(batch driver.Batch) error {
+		return appendColumns(batch, timestamps, pids, tids, containerIds, traceIds, spanIds, metrics)
 	})
-	return err
 }
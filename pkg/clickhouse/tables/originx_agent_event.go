@@ -2,10 +2,11 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-module/model/v1"
 )
 
@@ -25,20 +26,13 @@ const (
 	)`
 )
 
-func WriteAgentEvents(ctx context.Context, conn *sql.DB, toSends []*model.AgentEvent) error {
+func WriteAgentEvents(ctx context.Context, conn driver.Conn, toSends []*model.AgentEvent) error {
 	if len(toSends) == 0 {
 		return nil
 	}
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertAgentEventSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
+	err := doWithBatch(ctx, conn, insertAgentEventSQL, func(batch driver.Batch) error {
 		for _, toSend := range toSends {
-			_, err = statement.ExecContext(ctx,
+			err := batch.Append(
 				time.Unix(int64(toSend.Timestamp), 0).UTC(),
 				toSend.Name,
 				toSend.Pid,
@@ -46,7 +40,7 @@ func WriteAgentEvents(ctx context.Context, conn *sql.DB, toSends []*model.AgentE
 				toSend.Status,
 			)
 			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
+				return fmt.Errorf("Append:%w", err)
 			}
 		}
 
@@ -0,0 +1,95 @@
+package tables
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+)
+
+const (
+	insertAppHeartbeatSQL = `INSERT INTO originx_app_heartbeat (
+		node_ip,
+		node_name,
+		host_pid,
+		start_time,
+		heart_time,
+		heart_flag,
+		update_time
+	) VALUES (
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?
+	)`
+
+	countAppHeartbeatsSQL = `SELECT count(1) FROM originx_app_heartbeat`
+
+	queryAliveAppHeartbeatsSQL = `SELECT node_ip, node_name, host_pid, start_time, heart_time, heart_flag, update_time
+		FROM originx_app_heartbeat FINAL
+		WHERE heart_flag < ?
+	`
+)
+
+func WriteAppHeartbeats(ctx context.Context, conn driver.Conn, toSends []*appinfo.Heartbeat) error {
+	if len(toSends) == 0 {
+		return nil
+	}
+
+	return doWithBatch(ctx, conn, insertAppHeartbeatSQL, func(batch driver.Batch) error {
+		for _, toSend := range toSends {
+			if err := batch.Append(
+				toSend.NodeIp,
+				toSend.NodeName,
+				toSend.HostPid,
+				toSend.StartTime,
+				toSend.HeartTime,
+				toSend.HeartFlag,
+				toSend.UpdateTime,
+			); err != nil {
+				return fmt.Errorf("Append:%w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// QueryAliveAppHeartbeats returns the latest heartbeats of apps not dead,
+// the apps in originx_app_info are returned if no heartbeat is stored yet.
+func QueryAliveAppHeartbeats(ctx context.Context, conn driver.Conn) ([]*appinfo.Heartbeat, error) {
+	var count uint64
+	if err := conn.QueryRow(ctx, countAppHeartbeatsSQL).Scan(&count); err != nil {
+		return nil, fmt.Errorf("fail to count heartbeats: %w", err)
+	}
+	if count == 0 {
+		return QueryAliveAppInfos(ctx, conn)
+	}
+
+	rows, err := conn.Query(ctx, queryAliveAppHeartbeatsSQL, uint32(HEART_FLAG_DEAD))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*appinfo.Heartbeat, 0)
+	for rows.Next() {
+		heartbeat := &appinfo.Heartbeat{}
+		if err = rows.Scan(
+			&heartbeat.NodeIp,
+			&heartbeat.NodeName,
+			&heartbeat.HostPid,
+			&heartbeat.StartTime,
+			&heartbeat.HeartTime,
+			&heartbeat.HeartFlag,
+			&heartbeat.UpdateTime); err != nil {
+			return nil, err
+		}
+		result = append(result, heartbeat)
+	}
+	return result, rows.Err()
+}
@@ -2,13 +2,13 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
-	"github.com/CloudDetail/apo-receiver/pkg/model"
 )
 
 const (
@@ -35,48 +35,36 @@ const (
 	)`
 
 	queryStoredAppCountSQL = `SELECT count(1) FROM originx_app_info
-		WHERE start_time = %d AND host_pid = %d AND labels['node_ip'] = '%s' AND labels['node_name'] = '%s'
+		WHERE start_time = ? AND host_pid = ? AND labels['node_ip'] = ? AND labels['node_name'] = ?
 	`
 
-	updateHeartTimeSQL = "ALTER TABLE originx_app_info UPDATE heart_time=%d, heart_flag=0 WHERE start_time=%d AND host_pid=%d AND labels['node_ip'] = '%s' AND labels['node_name'] = '%s'"
-
-	updateHeartDeadSQL = "ALTER TABLE originx_app_info UPDATE heart_flag=%d WHERE start_time=%d AND host_pid=%d AND labels['node_ip'] = '%s' AND labels['node_name'] = '%s'"
-
-	queryActiveAppsSQL = `SELECT host_pid, start_time
+	// queryAliveAppInfosSQL is used to load the apps stored before originx_app_heartbeat is created.
+	queryAliveAppInfosSQL = `SELECT labels['node_ip'], labels['node_name'], host_pid, start_time, heart_time, heart_flag
 		FROM originx_app_info
-		WHERE heart_flag < 2 AND labels['node_ip'] = '%s' AND labels['node_name'] = '%s'
-		ORDER BY host_pid, start_time
+		WHERE heart_flag < ?
 	`
 )
 
 const (
-	HEART_FLAG_ALIVE = 0
-	HEART_FLAG_MISS  = 1
-	HEART_FLAG_DEAD  = 2
+	HEART_FLAG_ALIVE = appinfo.HEART_FLAG_ALIVE
+	HEART_FLAG_MISS  = appinfo.HEART_FLAG_MISS
+	HEART_FLAG_DEAD  = appinfo.HEART_FLAG_DEAD
 )
 
-func WriteAppInfos(ctx context.Context, conn *sql.DB, toSends []*appinfo.AppInfo) error {
+func WriteAppInfos(ctx context.Context, conn driver.Conn, toSends []*appinfo.AppInfo) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	return doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertServiceInstanceSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
-
+	return doWithBatch(ctx, conn, insertServiceInstanceSQL, func(batch driver.Batch) error {
 		now := time.Now()
 		for _, toSend := range toSends {
-			var count int
-			if err := conn.QueryRow(fmt.Sprintf(queryStoredAppCountSQL, toSend.StartTime, toSend.HostPid, toSend.Labels["node_ip"], toSend.Labels["node_name"])).Scan(&count); err != nil {
+			var count uint64
+			if err := conn.QueryRow(ctx, queryStoredAppCountSQL, toSend.StartTime, toSend.HostPid, toSend.Labels["node_ip"], toSend.Labels["node_name"]).Scan(&count); err != nil {
 				return fmt.Errorf("fail to query app count: s%w", err)
 			}
 			if count == 0 {
-				if _, err = statement.ExecContext(ctx,
+				if err := batch.Append(
 					now.UTC(),
 					toSend.StartTime,
 					now.Unix(), // Second
@@ -87,7 +75,7 @@ func WriteAppInfos(ctx context.Context, conn *sql.DB, toSends []*appinfo.AppInfo
 					toSend.ContainerId,
 					toSend.Labels,
 				); err != nil {
-					return fmt.Errorf("ExecContext:%w", err)
+					return fmt.Errorf("Append:%w", err)
 				}
 				log.Printf("[Store New App] %v", toSend)
 			} else {
@@ -98,58 +86,27 @@ func WriteAppInfos(ctx context.Context, conn *sql.DB, toSends []*appinfo.AppInfo
 	})
 }
 
-func QueryActiveApps(ctx context.Context, conn *sql.DB, nodeIp string, nodeName string, deadApps []*model.QueryActiveApp) ([]*model.QueryActiveApp, error) {
-	rows, err := conn.Query(fmt.Sprintf(queryActiveAppsSQL, nodeIp, nodeName))
+// QueryAliveAppInfos returns the apps not dead in originx_app_info as heartbeats.
+func QueryAliveAppInfos(ctx context.Context, conn driver.Conn) ([]*appinfo.Heartbeat, error) {
+	rows, err := conn.Query(ctx, queryAliveAppInfosSQL, uint32(HEART_FLAG_DEAD))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	deadKeys := map[string]struct{}{}
-	for _, deadApp := range deadApps {
-		deadKeys[fmt.Sprintf("%d-%d", deadApp.Pid, deadApp.StartTime)] = struct{}{}
-	}
-	result := make([]*model.QueryActiveApp, 0)
+	result := make([]*appinfo.Heartbeat, 0)
 	for rows.Next() {
-		activeApp := &ActiveApp{}
+		heartbeat := &appinfo.Heartbeat{}
 		if err = rows.Scan(
-			&activeApp.HostPid,
-			&activeApp.StartTime); err != nil {
+			&heartbeat.NodeIp,
+			&heartbeat.NodeName,
+			&heartbeat.HostPid,
+			&heartbeat.StartTime,
+			&heartbeat.HeartTime,
+			&heartbeat.HeartFlag); err != nil {
 			return nil, err
 		}
-		key := fmt.Sprintf("%d-%d", activeApp.HostPid, activeApp.StartTime)
-		if _, exist := deadKeys[key]; !exist {
-			result = append(result, &model.QueryActiveApp{
-				Pid:       activeApp.HostPid,
-				StartTime: activeApp.StartTime,
-			})
-		}
+		result = append(result, heartbeat)
 	}
-	return result, nil
-}
-
-func UpdateAppHeartTimes(conn *sql.DB, nodeIp string, nodeName string, activeApps []*model.QueryActiveApp) error {
-	heartTime := time.Now().Unix()
-	log.Printf("[Update App HeartTime] NodeIp: %s, Count: %d", nodeIp, len(activeApps))
-	for _, activeApp := range activeApps {
-		if _, err := conn.Exec(fmt.Sprintf(updateHeartTimeSQL, heartTime, activeApp.StartTime, activeApp.Pid, nodeIp, nodeName)); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func UpdateAppDeadFlags(conn *sql.DB, nodeIp string, nodeName string, deadApps []*model.QueryActiveApp) error {
-	for _, deadApp := range deadApps {
-		log.Printf("[Update App DeadFlag] NodeIp: %s, Pid: %d", nodeIp, deadApp.Pid)
-		if _, err := conn.Exec(fmt.Sprintf(updateHeartDeadSQL, HEART_FLAG_DEAD, deadApp.StartTime, deadApp.Pid, nodeIp, nodeName)); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-type ActiveApp struct {
-	HostPid   uint32 `db:"host_pid"`
-	StartTime uint64 `db:"start_time"`
+	return result, rows.Err()
 }
@@ -2,10 +2,11 @@ package tables
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
-	"fmt"
-	"log"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 )
 
 const (
@@ -40,79 +41,71 @@ const (
 	)`
 )
 
-type CameraEventGroup struct {
-	Name        string              `json:"name"`
-	Timestamp   uint64              `json:"timestamp"`
-	DataVersion string              `json:"data_version"`
-	Labels      *CameraProfileEvent `json:"labels"`
-}
-
-type CameraProfileEvent struct {
-	CpuEvents       string `json:"cpuEvents"`
-	ContainerId     string `json:"container_id"`
-	NodeName        string `json:"node_name"`
-	NodeIp          string `json:"node_ip"`
-	ClusterID       string `json:"cluster_id"`
-	EndTime         uint64 `json:"endTime"`
-	InnerCalls      string `json:"innerCalls"`
-	JavaFutexEvents string `json:"javaFutexEvents"`
-	Pid             uint32 `json:"pid"`
-	Protocol        string `json:"protocol"`
-	Spans           string `json:"spans"`
-	StartTime       uint64 `json:"startTime"`
-	ThreadName      string `json:"threadName"`
-	Tid             uint32 `json:"tid"`
-	TransactionIds  string `json:"transactionIds"`
-	OffsetTs        int64  `json:"offset_ts"`
-}
-
-func WriteProfilingEvents(ctx context.Context, conn *sql.DB, toSends []string) error {
+func WriteProfilingEvents(ctx context.Context, conn driver.Conn, toSends []*grpc_model.ProfilingEvent) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertProfilingEventSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
-		for _, eventGroupJson := range toSends {
-			eventGroup := &CameraEventGroup{}
-			if err := json.Unmarshal([]byte(eventGroupJson), eventGroup); err != nil {
-				log.Printf("[x Parse Profile Event] Error: %s", err.Error())
-				continue
-			}
-			labels := map[string]string{
-				"container_id": eventGroup.Labels.ContainerId,
-				"node_name":    eventGroup.Labels.NodeName,
-				"node_ip":      eventGroup.Labels.NodeIp,
-				"cluster_id":   eventGroup.Labels.ClusterID,
-				"protocol":     eventGroup.Labels.Protocol,
-				"threadName":   eventGroup.Labels.ThreadName,
-			}
-			_, err = statement.ExecContext(ctx,
-				asTime(int64(eventGroup.Timestamp)), // Second
-				eventGroup.DataVersion,
-				eventGroup.Labels.Pid,
-				eventGroup.Labels.Tid,
-				eventGroup.Labels.StartTime,
-				eventGroup.Labels.EndTime,
-				eventGroup.Labels.CpuEvents,
-				eventGroup.Labels.InnerCalls,
-				eventGroup.Labels.JavaFutexEvents,
-				eventGroup.Labels.Spans,
-				eventGroup.Labels.TransactionIds,
-				labels,
-				eventGroup.Labels.OffsetTs,
-			)
-			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
-			}
+	size := len(toSends)
+	var (
+		timestamps      = make([]time.Time, 0, size)
+		versions        = make([]string, 0, size)
+		pids            = make([]uint32, 0, size)
+		tids            = make([]uint32, 0, size)
+		startTimes      = make([]uint64, 0, size)
+		endTimes        = make([]uint64, 0, size)
+		cpuEvents       = make([]string, 0, size)
+		innerCalls      = make([]string, 0, size)
+		javaFutexEvents = make([]string, 0, size)
+		spans           = make([]string, 0, size)
+		transactionIds  = make([]string, 0, size)
+		labelsList      = make([]map[string]string, 0, size)
+		offsetTss       = make([]int64, 0, size)
+	)
+	for _, eventGroup := range toSends {
+		if eventGroup.Labels == nil {
+			continue
 		}
+		timestamps = append(timestamps, asTime(int64(eventGroup.Timestamp))) // Second
+		versions = append(versions, eventGroup.DataVersion)
+		pids = append(pids, eventGroup.Labels.Pid)
+		tids = append(tids, eventGroup.Labels.Tid)
+		startTimes = append(startTimes, eventGroup.Labels.StartTime)
+		endTimes = append(endTimes, eventGroup.Labels.EndTime)
+		cpuEvents = append(cpuEvents, eventGroup.Labels.CpuEvents)
+		innerCalls = append(innerCalls, eventGroup.Labels.InnerCalls)
+		javaFutexEvents = append(javaFutexEvents, eventGroup.Labels.JavaFutexEvents)
+		spans = append(spans, eventGroup.Labels.Spans)
+		transactionIds = append(transactionIds, eventGroup.Labels.TransactionIds)
+		labelsList = append(labelsList, map[string]string{
+			"container_id": eventGroup.Labels.ContainerId,
+			"node_name":    eventGroup.Labels.NodeName,
+			"node_ip":      eventGroup.Labels.NodeIp,
+			"cluster_id":   eventGroup.Labels.ClusterId,
+			"protocol":     eventGroup.Labels.Protocol,
+			"threadName":   eventGroup.Labels.ThreadName,
+		})
+		offsetTss = append(offsetTss, eventGroup.Labels.OffsetTs)
+	}
+	if len(timestamps) == 0 {
 		return nil
+	}
+
+	return doWithBatch(ctx, conn, insertProfilingEventSQL, func(batch driver.Batch) error {
+		return appendColumns(batch,
+			timestamps,
+			versions,
+			pids,
+			tids,
+			startTimes,
+			endTimes,
+			cpuEvents,
+			innerCalls,
+			javaFutexEvents,
+			spans,
+			transactionIds,
+			labelsList,
+			offsetTss,
+		)
 	})
-	return err
 }
@@ -0,0 +1,44 @@
+package tables
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+)
+
+const (
+	insertRawDataGroupSQL = `INSERT INTO raw_data_group (
+		timestamp,
+		data_group,
+		source,
+		data
+	) VALUES (
+		?,
+		?,
+		?,
+		?
+	)`
+)
+
+func WriteRawDatas(ctx context.Context, conn driver.Conn, toSends []*report.RawData) error {
+	if len(toSends) == 0 {
+		return nil
+	}
+	return doWithBatch(ctx, conn, insertRawDataGroupSQL, func(batch driver.Batch) error {
+		for _, toSend := range toSends {
+			if err := batch.Append(
+				time.UnixMilli(toSend.Timestamp).UTC(),
+				toSend.Group,
+				toSend.Source,
+				toSend.Data,
+			); err != nil {
+				return fmt.Errorf("Append:%w", err)
+			}
+		}
+		return nil
+	})
+}
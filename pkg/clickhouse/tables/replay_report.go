@@ -0,0 +1,77 @@
+package tables
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+)
+
+const (
+	insertReplayReportSQL = `INSERT INTO replay_report (
+		timestamp,
+		trace_id,
+		report_type,
+		mutate_node_mode,
+		is_drop,
+		retry,
+		drop_reason,
+		drop_reason_code,
+		node_reports,
+		error_reports,
+		relations
+	) VALUES (
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?
+	)`
+)
+
+// WriteReplayReports writes the replayed reports to the shadow table replay_report.
+func WriteReplayReports(ctx context.Context, conn driver.Conn, toSends []*report.ReplayResult) error {
+	if len(toSends) == 0 {
+		return nil
+	}
+
+	err := doWithBatch(ctx, conn, insertReplayReportSQL, func(batch driver.Batch) error {
+		for _, result := range toSends {
+			err := batch.Append(
+				asTime(result.ReplayTime), // NanoTime
+				result.TraceId,
+				result.ReportType,
+				result.MutateNodeMode,
+				result.IsDrop,
+				result.Retry,
+				result.DropReason,
+				string(result.DropReasonCode),
+				toJsonString(result.NodeReports),
+				toJsonString(result.ErrorReports),
+				toJsonString(result.Relations),
+			)
+			if err != nil {
+				return fmt.Errorf("Append:%w", err)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func toJsonString[T any](values []T) string {
+	if len(values) == 0 {
+		return ""
+	}
+	jsonBytes, _ := json.Marshal(values)
+	return string(jsonBytes)
+}
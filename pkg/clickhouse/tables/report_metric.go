@@ -2,9 +2,10 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	profile_model "github.com/CloudDetail/apo-receiver/pkg/componment/profile/model"
 )
 
@@ -28,21 +29,14 @@ const (
 	)`
 )
 
-func WriteReportMetrics(ctx context.Context, conn *sql.DB, toSends []*profile_model.SlowReportCountMetric) error {
+func WriteReportMetrics(ctx context.Context, conn driver.Conn, toSends []*profile_model.SlowReportCountMetric) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertReportMetricSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
+	err := doWithBatch(ctx, conn, insertReportMetricSQL, func(batch driver.Batch) error {
 		for _, reportMetric := range toSends {
-			_, err = statement.ExecContext(ctx,
+			err := batch.Append(
 				asTime(reportMetric.Timestamp), // NanoTime
 				reportMetric.EntryService,
 				reportMetric.EntryService,
@@ -52,7 +46,7 @@ func WriteReportMetrics(ctx context.Context, conn *sql.DB, toSends []*profile_mo
 				reportMetric.Success,
 			)
 			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
+				return fmt.Errorf("Append:%w", err)
 			}
 		}
 		return nil
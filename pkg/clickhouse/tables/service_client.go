@@ -2,11 +2,12 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/external"
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
 	"github.com/CloudDetail/apo-receiver/pkg/metrics"
@@ -47,19 +48,12 @@ const (
 	sourceAdapter = "adapter"
 )
 
-func WriteServiceClients(ctx context.Context, conn *sql.DB, toSends []*report.Relation) error {
+func WriteServiceClients(ctx context.Context, conn driver.Conn, toSends []*report.Relation) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertServiceClientSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
+	err := doWithBatch(ctx, conn, insertServiceClientSQL, func(batch driver.Batch) error {
 		for _, toSend := range toSends {
 			timestamp := asTime(int64(toSend.RootNode.StartTime))
 			for _, externalNode := range toSend.CollectExternalNodes() {
@@ -72,7 +66,7 @@ func WriteServiceClients(ctx context.Context, conn *sql.DB, toSends []*report.Re
 						"client_peer":   external.Peer,
 						"client_detail": external.Detail,
 					}
-					_, err = statement.ExecContext(ctx,
+					err := batch.Append(
 						timestamp,
 						toSend.RootNode.ServiceName,
 						toSend.RootNode.Url,
@@ -88,7 +82,7 @@ func WriteServiceClients(ctx context.Context, conn *sql.DB, toSends []*report.Re
 						labels,
 					)
 					if err != nil {
-						return fmt.Errorf("ExecContext:%w", err)
+						return fmt.Errorf("Append:%w", err)
 					}
 				}
 			}
@@ -2,9 +2,10 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
 )
 
@@ -38,18 +39,11 @@ const (
 	)`
 )
 
-func WriteServiceRelationships(ctx context.Context, conn *sql.DB, toSends []*report.Relation) error {
+func WriteServiceRelationships(ctx context.Context, conn driver.Conn, toSends []*report.Relation) error {
 	if len(toSends) == 0 {
 		return nil
 	}
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertServiceRelationShipSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
+	err := doWithBatch(ctx, conn, insertServiceRelationShipSQL, func(batch driver.Batch) error {
 		for _, toSend := range toSends {
 			toSend.CollectRelationships()
 			timestamp := asTime(int64(toSend.RootNode.StartTime))
@@ -66,7 +60,7 @@ func WriteServiceRelationships(ctx context.Context, conn *sql.DB, toSends []*rep
 					"is_traced":     relationship.IsTraced,
 				}
 
-				_, err = statement.ExecContext(ctx,
+				err := batch.Append(
 					timestamp,
 					toSend.RootNode.ServiceName,
 					toSend.RootNode.Url,
@@ -80,9 +74,9 @@ func WriteServiceRelationships(ctx context.Context, conn *sql.DB, toSends []*rep
 					labels,
 					flags,
 				)
-			}
-			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
+				if err != nil {
+					return fmt.Errorf("Append:%w", err)
+				}
 			}
 		}
 
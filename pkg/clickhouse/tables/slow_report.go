@@ -2,10 +2,11 @@ package tables
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
 )
 
@@ -17,6 +18,7 @@ const (
 		duration,
 		end_time,
 		drop_reason,
+		drop_reason_code,
 		cause,
 		relation_trees,
 		otel_client_calls,
@@ -38,24 +40,18 @@ const (
         ?,
         ?,
         ?,
+        ?,
         ?,
 		?
 	)`
 )
 
-func WriteSlowReports(ctx context.Context, conn *sql.DB, toSends []*report.NodeReport) error {
+func WriteSlowReports(ctx context.Context, conn driver.Conn, toSends []*report.NodeReport) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertSlowReportSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
+	err := doWithBatch(ctx, conn, insertSlowReportSQL, func(batch driver.Batch) error {
 		for _, nodeReport := range toSends {
 			relationTrees := ""
 			if nodeReport.Data.RelationTree != nil {
@@ -81,13 +77,14 @@ func WriteSlowReports(ctx context.Context, conn *sql.DB, toSends []*report.NodeR
 				"mutated_workload_type": nodeReport.Data.MutatedWorkloadType,
 				"content_key":           nodeReport.Data.ContentKey,
 			}
-			_, err = statement.ExecContext(ctx,
+			err := batch.Append(
 				asTime(int64(nodeReport.Timestamp)), // NanoTime
 				nodeReport.IsDrop,
 				nodeReport.TraceId,
 				nodeReport.Duration,
 				nodeReport.Data.EndTime,
 				nodeReport.Data.DropReason,
+				string(nodeReport.Data.DropReasonCode),
 				nodeReport.Data.Cause,
 				relationTrees,
 				clientCalls,
@@ -98,7 +95,7 @@ func WriteSlowReports(ctx context.Context, conn *sql.DB, toSends []*report.NodeR
 				nodeReport.Data.ThresholdMultiple,
 			)
 			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
+				return fmt.Errorf("Append:%w", err)
 			}
 		}
 		return nil
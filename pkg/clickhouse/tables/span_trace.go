@@ -3,11 +3,12 @@ package tables
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
 	"github.com/CloudDetail/apo-module/model/v1"
 )
 
@@ -51,8 +52,17 @@ const (
 		?,
 		?
 	)`
+
+	querySlowErrorTracesSQL = `SELECT * FROM span_trace WHERE timestamp >= ? AND timestamp < ? AND trace_id IN (
+		SELECT DISTINCT trace_id FROM span_trace
+		WHERE timestamp >= ? AND timestamp < ? AND (flags['is_slow'] OR flags['is_error'])
+		LIMIT ?
+	)`
 )
 
+// traceTimeMargin bounds the spans of a trace queried by time, they are stored within the margin of the slow or error span.
+const traceTimeMargin = 5 * time.Minute
+
 var cpuTypes = []string{
 	"cpu",
 	"file",
@@ -64,83 +74,107 @@ var cpuTypes = []string{
 	"runq",
 }
 
-func WriteSpanTraces(ctx context.Context, conn *sql.DB, toSends []*model.Trace) error {
+func WriteSpanTraces(ctx context.Context, conn driver.Conn, toSends []*model.Trace) error {
 	if len(toSends) == 0 {
 		return nil
 	}
 
-	err := doWithTx(ctx, conn, func(tx *sql.Tx) error {
-		statement, err := tx.PrepareContext(ctx, insertSpanTraceSQL)
-		if err != nil {
-			return fmt.Errorf("PrepareContext:%w", err)
-		}
-		defer func() {
-			_ = statement.Close()
-		}()
-		for _, trace := range toSends {
-			traceLabel := trace.Labels
-			flags := map[string]bool{
-				"top_span":    traceLabel.TopSpan,
-				"is_silent":   traceLabel.IsSilent,
-				"is_sampled":  traceLabel.IsSampled,
-				"is_slow":     traceLabel.IsSlow,
-				"is_server":   traceLabel.IsServer,
-				"is_error":    traceLabel.IsError,
-				"is_profiled": traceLabel.IsProfiled,
-			}
+	size := len(toSends)
+	var (
+		timestamps         = make([]time.Time, 0, size)
+		versions           = make([]string, 0, size)
+		pids               = make([]uint32, 0, size)
+		tids               = make([]uint32, 0, size)
+		reportTypes        = make([]uint32, 0, size)
+		thresholdTypes     = make([]string, 0, size)
+		thresholdRanges    = make([]string, 0, size)
+		thresholdValues    = make([]float64, 0, size)
+		thresholdMultiples = make([]float64, 0, size)
+		traceIds           = make([]string, 0, size)
+		apmSpanIds         = make([]string, 0, size)
+		flagsList          = make([]map[string]bool, 0, size)
+		labelsList         = make([]map[string]string, 0, size)
+		metricsList        = make([]map[string]uint64, 0, size)
+		startTimes         = make([]uint64, 0, size)
+		durations          = make([]uint64, 0, size)
+		endTimes           = make([]uint64, 0, size)
+		offsetTss          = make([]int64, 0, size)
+	)
+	for _, trace := range toSends {
+		traceLabel := trace.Labels
+		timestamps = append(timestamps, asTime(int64(trace.Timestamp))) // NanoTime
+		versions = append(versions, trace.Version)
+		pids = append(pids, uint32(traceLabel.Pid))
+		tids = append(tids, uint32(traceLabel.Tid))
+		reportTypes = append(reportTypes, uint32(traceLabel.ReportType))
+		thresholdTypes = append(thresholdTypes, string(traceLabel.ThresholdType))
+		thresholdRanges = append(thresholdRanges, string(traceLabel.ThresholdRange))
+		thresholdValues = append(thresholdValues, float64(traceLabel.ThresholdValue))
+		thresholdMultiples = append(thresholdMultiples, float64(traceLabel.ThresholdMultiple))
+		traceIds = append(traceIds, traceLabel.TraceId)
+		apmSpanIds = append(apmSpanIds, traceLabel.ApmSpanId)
+		flagsList = append(flagsList, map[string]bool{
+			"top_span":    traceLabel.TopSpan,
+			"is_silent":   traceLabel.IsSilent,
+			"is_sampled":  traceLabel.IsSampled,
+			"is_slow":     traceLabel.IsSlow,
+			"is_server":   traceLabel.IsServer,
+			"is_error":    traceLabel.IsError,
+			"is_profiled": traceLabel.IsProfiled,
+		})
+		labelsList = append(labelsList, map[string]string{
+			"instance_id":        trace.GetInstanceId(),
+			"protocol":           traceLabel.Protocol,
+			"service_name":       traceLabel.ServiceName,
+			"content_key":        traceLabel.Url,
+			"http_url":           traceLabel.HttpUrl,
+			"apm_type":           traceLabel.ApmType,
+			"attributes":         traceLabel.Attributes,
+			"container_id":       traceLabel.ContainerId,
+			"container_name":     traceLabel.ContainerName,
+			"workload_name":      trace.WorkloadName,
+			"workload_kind":      trace.WorkloadKind,
+			"pod_ip":             trace.PodIp,
+			"pod_name":           trace.PodName,
+			"namespace":          trace.Namespace,
+			"node_name":          traceLabel.NodeName,
+			"node_ip":            traceLabel.NodeIp,
+			"cluster_id":         traceLabel.ClusterID,
+			"onoff_metrics":      trace.OnOffMetrics,
+			"base_onoff_metrics": trace.BaseOnOffMetrics,
+			"base_range":         trace.BaseRange,
+			"data_source":        trace.Source,
+			"mutated_type":       trace.MutatedType,
+		})
+		metricsList = append(metricsList, calcMutatedTypes(trace.OnOffMetrics, trace.BaseOnOffMetrics))
+		startTimes = append(startTimes, uint64(traceLabel.StartTime))
+		durations = append(durations, uint64(traceLabel.Duration))
+		endTimes = append(endTimes, uint64(traceLabel.EndTime))
+		offsetTss = append(offsetTss, int64(traceLabel.OffsetTs))
+	}
 
-			metrics := calcMutatedTypes(trace.OnOffMetrics, trace.BaseOnOffMetrics)
-			labels := map[string]string{
-				"instance_id":        trace.GetInstanceId(),
-				"protocol":           traceLabel.Protocol,
-				"service_name":       traceLabel.ServiceName,
-				"content_key":        traceLabel.Url,
-				"http_url":           traceLabel.HttpUrl,
-				"apm_type":           traceLabel.ApmType,
-				"attributes":         traceLabel.Attributes,
-				"container_id":       traceLabel.ContainerId,
-				"container_name":     traceLabel.ContainerName,
-				"workload_name":      trace.WorkloadName,
-				"workload_kind":      trace.WorkloadKind,
-				"pod_ip":             trace.PodIp,
-				"pod_name":           trace.PodName,
-				"namespace":          trace.Namespace,
-				"node_name":          traceLabel.NodeName,
-				"node_ip":            traceLabel.NodeIp,
-				"cluster_id":         traceLabel.ClusterID,
-				"onoff_metrics":      trace.OnOffMetrics,
-				"base_onoff_metrics": trace.BaseOnOffMetrics,
-				"base_range":         trace.BaseRange,
-				"data_source":        trace.Source,
-				"mutated_type":       trace.MutatedType,
-			}
-			_, err = statement.ExecContext(ctx,
-				asTime(int64(trace.Timestamp)), // NanoTime
-				trace.Version,
-				traceLabel.Pid,
-				traceLabel.Tid,
-				traceLabel.ReportType,
-				string(traceLabel.ThresholdType),
-				string(traceLabel.ThresholdRange),
-				traceLabel.ThresholdValue,
-				traceLabel.ThresholdMultiple,
-				traceLabel.TraceId,
-				traceLabel.ApmSpanId,
-				flags,
-				labels,
-				metrics,
-				traceLabel.StartTime,
-				traceLabel.Duration,
-				traceLabel.EndTime,
-				traceLabel.OffsetTs,
-			)
-			if err != nil {
-				return fmt.Errorf("ExecContext:%w", err)
-			}
-		}
-		return nil
+	return doWithBatch(ctx, conn, insertSpanTraceSQL, func(batch driver.Batch) error {
+		return appendColumns(batch,
+			timestamps,
+			versions,
+			pids,
+			tids,
+			reportTypes,
+			thresholdTypes,
+			thresholdRanges,
+			thresholdValues,
+			thresholdMultiples,
+			traceIds,
+			apmSpanIds,
+			flagsList,
+			labelsList,
+			metricsList,
+			startTimes,
+			durations,
+			endTimes,
+			offsetTss,
+		)
 	})
-	return err
 }
 
 func QueryTraces(ctx context.Context, conn *sql.DB, traceId string) (*model.Traces, error) {
@@ -152,79 +186,117 @@ func QueryTraces(ctx context.Context, conn *sql.DB, traceId string) (*model.Trac
 
 	traces := model.NewTraces(traceId)
 	for rows.Next() {
-		spanTrace := &SpanTrace{}
-		if err = rows.Scan(
-			&spanTrace.Timestamp,
-			&spanTrace.DataVersion,
-			&spanTrace.Pid,
-			&spanTrace.Tid,
-			&spanTrace.ReportType,
-			&spanTrace.ThresholdType,
-			&spanTrace.ThresholdRange,
-			&spanTrace.ThresholdValue,
-			&spanTrace.ThresholdMultiple,
-			&spanTrace.TraceId,
-			&spanTrace.ApmSpanId,
-			&spanTrace.Flags,
-			&spanTrace.Labels,
-			&spanTrace.StartTime,
-			&spanTrace.Duration,
-			&spanTrace.EndTime,
-			&spanTrace.OffsetTs,
-			&spanTrace.Metrics); err != nil {
+		trace, err := scanSpanTrace(rows)
+		if err != nil {
 			return nil, err
 		}
+		traces.AddTrace(trace)
+	}
+	return traces, nil
+}
+
+// QueryTracesByTime queries the traces which have slow or error span in [startTime, endTime), limit is the max count of traces.
+// The other spans of traces are queried within traceTimeMargin around the window.
+func QueryTracesByTime(ctx context.Context, conn *sql.DB, startTime time.Time, endTime time.Time, limit int) ([]*model.Traces, error) {
+	rows, err := conn.QueryContext(ctx, querySlowErrorTracesSQL,
+		startTime.Add(-traceTimeMargin), endTime.Add(traceTimeMargin), startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		label := &model.TraceLabels{
-			Pid:               spanTrace.Pid,
-			Tid:               spanTrace.Tid,
-			TopSpan:           spanTrace.Flags["top_span"],
-			Protocol:          spanTrace.Labels["protocol"],
-			ServiceName:       spanTrace.Labels["service_name"],
-			Url:               spanTrace.Labels["content_key"],
-			HttpUrl:           spanTrace.Labels["http_url"],
-			IsSilent:          spanTrace.Flags["is_silent"],
-			IsSampled:         spanTrace.Flags["is_sampled"],
-			IsSlow:            spanTrace.Flags["is_slow"],
-			IsServer:          spanTrace.Flags["is_server"],
-			IsError:           spanTrace.Flags["is_error"],
-			IsProfiled:        spanTrace.Flags["is_profiled"],
-			ReportType:        spanTrace.ReportType,
-			ThresholdType:     model.ThresholdType(spanTrace.ThresholdType),
-			ThresholdValue:    spanTrace.ThresholdValue,
-			ThresholdRange:    model.ThresholdRange(spanTrace.ThresholdRange),
-			ThresholdMultiple: spanTrace.ThresholdMultiple,
-			TraceId:           spanTrace.TraceId,
-			ApmType:           spanTrace.Labels["apm_type"],
-			ApmSpanId:         spanTrace.ApmSpanId,
-			Attributes:        spanTrace.Labels["attributes"],
-			ContainerId:       spanTrace.Labels["container_id"],
-			ContainerName:     spanTrace.Labels["container_name"],
-			StartTime:         spanTrace.StartTime,
-			Duration:          spanTrace.Duration,
-			EndTime:           spanTrace.EndTime,
-			NodeName:          spanTrace.Labels["node_name"],
-			NodeIp:            spanTrace.Labels["node_ip"],
-			OffsetTs:          spanTrace.OffsetTs,
+	tracesList := make([]*model.Traces, 0)
+	tracesMap := make(map[string]*model.Traces)
+	for rows.Next() {
+		trace, err := scanSpanTrace(rows)
+		if err != nil {
+			return nil, err
 		}
-		trace := &model.Trace{
-			Timestamp:        spanTrace.StartTime,
-			Version:          spanTrace.DataVersion,
-			Source:           spanTrace.Labels["data_source"],
-			Labels:           label,
-			WorkloadName:     spanTrace.Labels["workload_name"],
-			WorkloadKind:     spanTrace.Labels["workload_kind"],
-			PodIp:            spanTrace.Labels["pod_ip"],
-			PodName:          spanTrace.Labels["pod_name"],
-			Namespace:        spanTrace.Labels["namespace"],
-			OnOffMetrics:     spanTrace.Labels["onoff_metrics"],
-			BaseOnOffMetrics: spanTrace.Labels["base_onoff_metrics"],
-			BaseRange:        spanTrace.Labels["base_range"],
-			MutatedType:      spanTrace.Labels["mutated_type"],
+		traceId := trace.Labels.TraceId
+		traces, found := tracesMap[traceId]
+		if !found {
+			traces = model.NewTraces(traceId)
+			tracesMap[traceId] = traces
+			tracesList = append(tracesList, traces)
 		}
 		traces.AddTrace(trace)
 	}
-	return traces, nil
+	return tracesList, rows.Err()
+}
+
+func scanSpanTrace(rows *sql.Rows) (*model.Trace, error) {
+	spanTrace := &SpanTrace{}
+	if err := rows.Scan(
+		&spanTrace.Timestamp,
+		&spanTrace.DataVersion,
+		&spanTrace.Pid,
+		&spanTrace.Tid,
+		&spanTrace.ReportType,
+		&spanTrace.ThresholdType,
+		&spanTrace.ThresholdRange,
+		&spanTrace.ThresholdValue,
+		&spanTrace.ThresholdMultiple,
+		&spanTrace.TraceId,
+		&spanTrace.ApmSpanId,
+		&spanTrace.Flags,
+		&spanTrace.Labels,
+		&spanTrace.StartTime,
+		&spanTrace.Duration,
+		&spanTrace.EndTime,
+		&spanTrace.OffsetTs,
+		&spanTrace.Metrics); err != nil {
+		return nil, err
+	}
+
+	label := &model.TraceLabels{
+		Pid:               spanTrace.Pid,
+		Tid:               spanTrace.Tid,
+		TopSpan:           spanTrace.Flags["top_span"],
+		Protocol:          spanTrace.Labels["protocol"],
+		ServiceName:       spanTrace.Labels["service_name"],
+		Url:               spanTrace.Labels["content_key"],
+		HttpUrl:           spanTrace.Labels["http_url"],
+		IsSilent:          spanTrace.Flags["is_silent"],
+		IsSampled:         spanTrace.Flags["is_sampled"],
+		IsSlow:            spanTrace.Flags["is_slow"],
+		IsServer:          spanTrace.Flags["is_server"],
+		IsError:           spanTrace.Flags["is_error"],
+		IsProfiled:        spanTrace.Flags["is_profiled"],
+		ReportType:        spanTrace.ReportType,
+		ThresholdType:     model.ThresholdType(spanTrace.ThresholdType),
+		ThresholdValue:    spanTrace.ThresholdValue,
+		ThresholdRange:    model.ThresholdRange(spanTrace.ThresholdRange),
+		ThresholdMultiple: spanTrace.ThresholdMultiple,
+		TraceId:           spanTrace.TraceId,
+		ApmType:           spanTrace.Labels["apm_type"],
+		ApmSpanId:         spanTrace.ApmSpanId,
+		Attributes:        spanTrace.Labels["attributes"],
+		ContainerId:       spanTrace.Labels["container_id"],
+		ContainerName:     spanTrace.Labels["container_name"],
+		StartTime:         spanTrace.StartTime,
+		Duration:          spanTrace.Duration,
+		EndTime:           spanTrace.EndTime,
+		NodeName:          spanTrace.Labels["node_name"],
+		NodeIp:            spanTrace.Labels["node_ip"],
+		ClusterID:         spanTrace.Labels["cluster_id"],
+		OffsetTs:          spanTrace.OffsetTs,
+	}
+	trace := &model.Trace{
+		Timestamp:        spanTrace.StartTime,
+		Version:          spanTrace.DataVersion,
+		Source:           spanTrace.Labels["data_source"],
+		Labels:           label,
+		WorkloadName:     spanTrace.Labels["workload_name"],
+		WorkloadKind:     spanTrace.Labels["workload_kind"],
+		PodIp:            spanTrace.Labels["pod_ip"],
+		PodName:          spanTrace.Labels["pod_name"],
+		Namespace:        spanTrace.Labels["namespace"],
+		OnOffMetrics:     spanTrace.Labels["onoff_metrics"],
+		BaseOnOffMetrics: spanTrace.Labels["base_onoff_metrics"],
+		BaseRange:        spanTrace.Labels["base_range"],
+		MutatedType:      spanTrace.Labels["mutated_type"],
+	}
+	return trace, nil
 }
 
 func calcMutatedTypes(onoffMetrics string, baseOnOffMetrics string) map[string]uint64 {
@@ -1,9 +1,14 @@
 package clickhouse
 
 import (
-	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
-	"text/template"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/sqlscript"
 )
 
 func TestTemplate(t *testing.T) {
@@ -11,16 +16,96 @@ func TestTemplate(t *testing.T) {
 		Cluster:  "apocluster",
 		Database: "apo",
 	}
-	migrateTemplate, err := template.ParseFiles("../../sqlscript/migrate.tmpl.sql")
+	migrations, err := loadMigrations(newSqlScripts(""), args)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migration is found")
+	}
+	for i, migration := range migrations {
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			t.Errorf("migration %d is not sorted by version", migration.Version)
+		}
+		if len(migration.Statements) == 0 {
+			t.Errorf("migration %d_%s has no statement", migration.Version, migration.Name)
+		}
+		for _, statement := range migration.Statements {
+			if strings.HasPrefix(statement, "ALTER") && !strings.Contains(statement, "ON CLUSTER apocluster") {
+				t.Errorf("migration %d_%s misses cluster: %s", migration.Version, migration.Name, statement)
+			}
+			t.Logf("%d_%s: %s", migration.Version, migration.Name, statement)
+		}
+	}
+
+	localMigrations, err := loadMigrations(newSqlScripts(""), distributedTableArgs{Database: "apo"})
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	for i, migration := range localMigrations {
+		for _, statement := range migration.Statements {
+			if strings.Contains(statement, "Distributed") || strings.Contains(statement, "_local") {
+				t.Errorf("migration %d_%s should not touch distributed table: %s", migration.Version, migration.Name, statement)
+			}
+		}
+		if migration.Checksum != migrations[i].Checksum {
+			t.Errorf("migration %d_%s checksum should not depend on args", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements("-- 1.0.0\nALTER TABLE a ADD COLUMN b String;\n\n-- comment; with semicolon\nDROP TABLE c;\n")
+	if len(statements) != 2 {
+		t.Fatalf("want 2 statements, got %d: %v", len(statements), statements)
+	}
+	if statements[0] != "ALTER TABLE a ADD COLUMN b String" || statements[1] != "DROP TABLE c" {
+		t.Errorf("unexpected statements: %v", statements)
+	}
+}
+
+func TestSqlScriptsOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, sqlCreateFolder), 0755); err != nil {
+		t.Fatal(err)
+	}
+	override := "CREATE TABLE IF NOT EXISTS span_trace (trace_id String) ENGINE MergeTree() ORDER BY trace_id"
+	if err := os.WriteFile(filepath.Join(dir, sqlCreateFolder, "span_trace.tmp.sql"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	init := NewClickHouseInit("tcp://localhost:9000", "apo", false, "apocluster", "", "", true, false, 7,
+		map[string]uint{}, map[string]string{"span_trace": "cityHash64(trace_id)"}, dir)
+	sqlStatements, err := init.renderInitScripts()
 	if err != nil {
-		t.Failed()
-		return
+		t.Fatalf("render init scripts: %v", err)
+	}
+	tables, _ := fs.Glob(sqlscript.Scripts, "create_table/*.tmp.sql")
+	if len(sqlStatements) != 2*len(tables) {
+		t.Errorf("want %d statements, got %d", 2*len(tables), len(sqlStatements))
+	}
+	var found bool
+	for i, sqlStatement := range sqlStatements {
+		if sqlStatement == override {
+			found = true
+			if !strings.Contains(sqlStatements[i+1], "span_trace_local, cityHash64(trace_id)") {
+				t.Errorf("unexpected distributed table: %s", sqlStatements[i+1])
+			}
+		}
+	}
+	if !found {
+		t.Error("span_trace is not overridden")
+	}
+}
+
+func TestDumpDDL(t *testing.T) {
+	var out strings.Builder
+	if err := DumpDDL(&config.ClickHouseConfig{Database: "apo", Cluster: "apocluster"}, &out); err != nil {
+		t.Fatalf("dump ddl: %v", err)
 	}
-	var rendered bytes.Buffer
-	if err := migrateTemplate.Execute(&rendered, args); err != nil {
-		t.Failed()
-		return
+	for _, table := range []string{migrationLedgerName, migrationLockName} {
+		if !strings.Contains(out.String(), "CREATE TABLE IF NOT EXISTS "+table) {
+			t.Errorf("%s is not dumped", table)
+		}
 	}
-	sqlStatement := rendered.String()
-	t.Logf("%s", sqlStatement)
 }
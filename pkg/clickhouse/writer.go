@@ -0,0 +1,104 @@
+package clickhouse
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+type writerConfig struct {
+	flushPeriod  time.Duration
+	maxBatchSize int
+}
+
+func newWriterConfig(flushSeconds uint, maxBatchSize int) *writerConfig {
+	if flushSeconds == 0 {
+		flushSeconds = 5
+	}
+	return &writerConfig{
+		flushPeriod:  time.Duration(flushSeconds) * time.Second,
+		maxBatchSize: maxBatchSize,
+	}
+}
+
+type tableRunner interface {
+	run(ctx context.Context, stopChan chan bool)
+	flush(ctx context.Context)
+	// syncedTime returns the start time of the last flush which writes all the cached datas.
+	syncedTime() time.Time
+}
+
+// tableWriter flushes the cached datas of one table with its own period and batch size,
+// so a slow table will not delay others.
+type tableWriter[T any] struct {
+	client *ClickHouseClient
+	buffer *cacheBuffer[T]
+	config *writerConfig
+	// targets are the tables written by the datas of buffer, eg. error_report and error_propagation share one buffer.
+	targets    []*tableTarget[T]
+	afterWrite func(toSends []T)
+	synced     atomic.Int64
+}
+
+type tableTarget[T any] struct {
+	table string
+	write func(ctx context.Context, conn driver.Conn, toSends []T) error
+}
+
+func newTableWriter[T any](client *ClickHouseClient, buffer *cacheBuffer[T], config *writerConfig,
+	write func(ctx context.Context, conn driver.Conn, toSends []T) error) *tableWriter[T] {
+	return &tableWriter[T]{
+		client:  client,
+		buffer:  buffer,
+		config:  config,
+		targets: []*tableTarget[T]{{table: buffer.table, write: write}},
+	}
+}
+
+// fanOut writes the datas of buffer to another table, each table is spooled by itself when failed.
+func (writer *tableWriter[T]) fanOut(table string, write func(ctx context.Context, conn driver.Conn, toSends []T) error) *tableWriter[T] {
+	writer.targets = append(writer.targets, &tableTarget[T]{table: table, write: write})
+	return writer
+}
+
+func (writer *tableWriter[T]) run(ctx context.Context, stopChan chan bool) {
+	timer := time.NewTicker(writer.config.flushPeriod)
+	for {
+		select {
+		case <-timer.C:
+			writer.flush(ctx)
+		case <-writer.buffer.flushChan:
+			// The buffer reaches its limit.
+			writer.flush(ctx)
+		case <-stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (writer *tableWriter[T]) flush(ctx context.Context) {
+	startTime := time.Now()
+	for {
+		toSends := writer.buffer.getToSend(writer.config.maxBatchSize)
+		for _, target := range writer.targets {
+			if err := writeWithSpool(ctx, writer.client, target.table, toSends, target.write); err != nil {
+				log.Printf("[x Add %s] %s", target.table, err.Error())
+			}
+		}
+		if writer.afterWrite != nil && len(toSends) > 0 {
+			writer.afterWrite(toSends)
+		}
+		if writer.config.maxBatchSize <= 0 || len(toSends) < writer.config.maxBatchSize {
+			writer.synced.Store(startTime.UnixNano())
+			return
+		}
+	}
+}
+
+func (writer *tableWriter[T]) syncedTime() time.Time {
+	return time.Unix(0, writer.synced.Load())
+}
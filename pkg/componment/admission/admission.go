@@ -0,0 +1,317 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	metric_model "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+)
+
+const (
+	defaultHighWaterMark     = 0.9
+	defaultMaxAnalyzerTasks  = 10000
+	defaultRetryAfterSeconds = 5
+
+	// RetryAfterKey is the trailer of rejected requests, the seconds to retry.
+	RetryAfterKey = "retry-after"
+
+	reasonHighWater = "high_water"
+	reasonMaxBytes  = "max_bytes"
+	reasonRate      = "rate"
+
+	// sampleInterval is how often the usages of internal queues are sampled.
+	sampleInterval = time.Second
+	// nodeIdleTimeout is how long the limiter of a node is kept without requests.
+	nodeIdleTimeout = 10 * time.Minute
+)
+
+// Usage is the datas of one data group in a request.
+type Usage struct {
+	Datas int
+	Bytes int
+}
+
+// Controller admits the requests of agents by the rate / size limits and the usage of internal queues,
+// all the requests are admitted when it is nil.
+// The usages are sampled every sampleInterval, so the requests are not blocked by reading the queues.
+type Controller struct {
+	nodeLimit        *config.AdmissionLimit
+	groupLimits      map[string]*config.AdmissionLimit
+	highWaterMark    float64
+	maxAnalyzerTasks int
+	retryAfter       time.Duration
+	// groupLimiters is not changed after created, the limiters are safe for concurrent use.
+	groupLimiters map[string]*rate.Limiter
+
+	lock         sync.Mutex
+	nodeLimiters map[string]*nodeLimiter
+	usages       map[string]func() float64
+	// maxUsage is the highest usage of the last sample.
+	maxUsage atomic.Pointer[usageSample]
+}
+
+type nodeLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+type usageSample struct {
+	name  string
+	value float64
+}
+
+// NewController returns nil when admission is not enabled.
+func NewController(cfg *config.AdmissionConfig) *Controller {
+	if cfg == nil || !cfg.Enable {
+		return nil
+	}
+	highWaterMark := cfg.HighWaterMark
+	if highWaterMark <= 0 {
+		highWaterMark = defaultHighWaterMark
+	}
+	maxAnalyzerTasks := cfg.MaxAnalyzerTasks
+	if maxAnalyzerTasks <= 0 {
+		maxAnalyzerTasks = defaultMaxAnalyzerTasks
+	}
+	retryAfterSeconds := cfg.RetryAfterSeconds
+	if retryAfterSeconds <= 0 {
+		retryAfterSeconds = defaultRetryAfterSeconds
+	}
+	controller := &Controller{
+		nodeLimit:        cfg.NodeLimit,
+		groupLimits:      cfg.GroupLimits,
+		highWaterMark:    highWaterMark,
+		maxAnalyzerTasks: maxAnalyzerTasks,
+		retryAfter:       time.Duration(retryAfterSeconds) * time.Second,
+		nodeLimiters:     make(map[string]*nodeLimiter),
+		groupLimiters:    make(map[string]*rate.Limiter),
+		usages:           make(map[string]func() float64),
+	}
+	for group, limit := range cfg.GroupLimits {
+		if limiter := newLimiter(limit); limiter != nil {
+			controller.groupLimiters[group] = limiter
+		}
+	}
+	return controller
+}
+
+func newLimiter(limit *config.AdmissionLimit) *rate.Limiter {
+	if limit == nil || limit.Rate <= 0 {
+		return nil
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(limit.Rate))
+	}
+	return rate.NewLimiter(rate.Limit(limit.Rate), burst)
+}
+
+// RegisterUsage registers the usage of internal queue, which is between 0 and 1.
+func (controller *Controller) RegisterUsage(name string, usage func() float64) {
+	if controller == nil {
+		return
+	}
+	controller.lock.Lock()
+	defer controller.lock.Unlock()
+	controller.usages[name] = usage
+}
+
+// RegisterAnalyzerTasks registers the pending analyzer tasks as usage, MaxAnalyzerTasks is regarded as full.
+func (controller *Controller) RegisterAnalyzerTasks(pendingTasks func() int) {
+	if controller == nil {
+		return
+	}
+	controller.RegisterUsage("analyzer_tasks", func() float64 {
+		return float64(pendingTasks()) / float64(controller.maxAnalyzerTasks)
+	})
+}
+
+// Start samples the usages and expires the idle node limiters until ctx is done.
+func (controller *Controller) Start(ctx context.Context) {
+	if controller == nil {
+		return
+	}
+	controller.sample()
+	go func() {
+		timer := time.NewTicker(sampleInterval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				controller.sample()
+				controller.expireNodes(time.Now())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (controller *Controller) sample() {
+	controller.lock.Lock()
+	usages := make(map[string]func() float64, len(controller.usages))
+	for name, usage := range controller.usages {
+		usages[name] = usage
+	}
+	controller.lock.Unlock()
+
+	maxUsage := &usageSample{}
+	for name, usage := range usages {
+		if value := usage(); value > maxUsage.value {
+			maxUsage.name, maxUsage.value = name, value
+		}
+	}
+	controller.maxUsage.Store(maxUsage)
+}
+
+// expireNodes removes the limiters of nodes without requests in nodeIdleTimeout.
+func (controller *Controller) expireNodes(now time.Time) {
+	controller.lock.Lock()
+	defer controller.lock.Unlock()
+	for node, limiter := range controller.nodeLimiters {
+		if now.Sub(limiter.lastUsed) >= nodeIdleTimeout {
+			delete(controller.nodeLimiters, node)
+		}
+	}
+}
+
+// Admit returns ResourceExhausted when the request of node should be rejected, usages are keyed by data group.
+// The retry-after hint is set as RetryInfo and trailer if agents can retry later.
+func (controller *Controller) Admit(ctx context.Context, node string, usages map[string]Usage) error {
+	if controller == nil || len(usages) == 0 {
+		return nil
+	}
+
+	if maxUsage := controller.maxUsage.Load(); maxUsage != nil && maxUsage.value >= controller.highWaterMark {
+		return controller.reject(ctx, node, usages, reasonHighWater, controller.retryAfter,
+			"receiver is overloaded, %s usage %.2f reaches high water mark %.2f", maxUsage.name, maxUsage.value, controller.highWaterMark)
+	}
+
+	total := Usage{}
+	for _, usage := range usages {
+		total.Datas += usage.Datas
+		total.Bytes += usage.Bytes
+	}
+	if exceedBytes(controller.nodeLimit, total.Bytes) {
+		return controller.reject(ctx, node, usages, reasonMaxBytes, 0,
+			"request of %d bytes exceeds max bytes %d of node", total.Bytes, controller.nodeLimit.MaxBytes)
+	}
+	for group, usage := range usages {
+		if limit := controller.groupLimits[group]; exceedBytes(limit, usage.Bytes) {
+			return controller.reject(ctx, node, usages, reasonMaxBytes, 0,
+				"%d bytes of %s exceeds max bytes %d of group", usage.Bytes, group, limit.MaxBytes)
+		}
+	}
+
+	now := time.Now()
+	reservations := make([]*rate.Reservation, 0, len(usages)+1)
+	cancelAll := func() {
+		for _, reservation := range reservations {
+			reservation.CancelAt(now)
+		}
+	}
+	var delay time.Duration
+	reserve := func(limiter *rate.Limiter, datas int) bool {
+		reservation := limiter.ReserveN(now, datas)
+		if !reservation.OK() {
+			return false
+		}
+		reservations = append(reservations, reservation)
+		if reservationDelay := reservation.DelayFrom(now); reservationDelay > delay {
+			delay = reservationDelay
+		}
+		return true
+	}
+	if limiter := controller.getNodeLimiter(node, now); limiter != nil && !reserve(limiter, total.Datas) {
+		cancelAll()
+		return controller.reject(ctx, node, usages, reasonRate, 0,
+			"request of %d datas exceeds burst %d of node", total.Datas, limiter.Burst())
+	}
+	for _, group := range sortedGroups(usages) {
+		limiter, found := controller.groupLimiters[group]
+		if found && !reserve(limiter, usages[group].Datas) {
+			cancelAll()
+			return controller.reject(ctx, node, usages, reasonRate, 0,
+				"%d datas of %s exceeds burst %d of group", usages[group].Datas, group, limiter.Burst())
+		}
+	}
+	if delay > 0 {
+		cancelAll()
+		return controller.reject(ctx, node, usages, reasonRate, delay, "rate limit is reached")
+	}
+	return nil
+}
+
+// RetryAfter returns the retry delay of the rejected error, 0 means retrying later is not helpful.
+func RetryAfter(err error) time.Duration {
+	for _, detail := range status.Convert(err).Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			return retryInfo.RetryDelay.AsDuration()
+		}
+	}
+	return 0
+}
+
+func exceedBytes(limit *config.AdmissionLimit, bytes int) bool {
+	return limit != nil && limit.MaxBytes > 0 && bytes > limit.MaxBytes
+}
+
+func (controller *Controller) getNodeLimiter(node string, now time.Time) *rate.Limiter {
+	controller.lock.Lock()
+	defer controller.lock.Unlock()
+	if limiter, found := controller.nodeLimiters[node]; found {
+		limiter.lastUsed = now
+		return limiter.limiter
+	}
+	limiter := newLimiter(controller.nodeLimit)
+	if limiter != nil {
+		controller.nodeLimiters[node] = &nodeLimiter{limiter: limiter, lastUsed: now}
+	}
+	return limiter
+}
+
+// sortedGroups keeps the order of reservations, so that the limiters are always reserved in the same order.
+func sortedGroups(usages map[string]Usage) []string {
+	groups := make([]string, 0, len(usages))
+	for group := range usages {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func (controller *Controller) reject(ctx context.Context, node string, usages map[string]Usage, reason string,
+	retryAfter time.Duration, format string, args ...interface{}) error {
+	for group, usage := range usages {
+		metrics.UpdateMetric(metric_model.MetricAdmissionRejectedCount, []string{node, group, reason}, float64(usage.Datas))
+	}
+	message := fmt.Sprintf(format, args...)
+	if retryAfter <= 0 {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	// Not a grpc server stream in tests, the trailer is ignored.
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, strconv.Itoa(seconds)))
+	rejected, err := status.New(codes.ResourceExhausted, fmt.Sprintf("%s, retry after %ds", message, seconds)).
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+	return rejected.Err()
+}
@@ -0,0 +1,55 @@
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+func TestControllerAdmit(t *testing.T) {
+	assert.Nil(t, NewController(&config.AdmissionConfig{}))
+	var disabled *Controller
+	assert.NoError(t, disabled.Admit(context.Background(), "node", map[string]Usage{"jvm_gc": {Datas: 1}}))
+
+	controller := NewController(&config.AdmissionConfig{
+		Enable:    true,
+		NodeLimit: &config.AdmissionLimit{Rate: 0.001, Burst: 10, MaxBytes: 100},
+		GroupLimits: map[string]*config.AdmissionLimit{
+			"span_trace_group": {Rate: 0.001, Burst: 5},
+		},
+	})
+	ctx := context.Background()
+
+	err := controller.Admit(ctx, "node-1", map[string]Usage{"jvm_gc": {Datas: 1, Bytes: 200}})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	assert.NoError(t, controller.Admit(ctx, "node-1", map[string]Usage{"span_trace_group": {Datas: 5}}))
+	// The group limit is shared by nodes, the reservation of node-2 is canceled.
+	err = controller.Admit(ctx, "node-2", map[string]Usage{"span_trace_group": {Datas: 1}})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.True(t, RetryAfter(err) > 0)
+	assert.NoError(t, controller.Admit(ctx, "node-2", map[string]Usage{"jvm_gc": {Datas: 10}}))
+
+	usage := 0.5
+	controller.RegisterUsage("queue", func() float64 { return usage })
+	controller.sample()
+	assert.NoError(t, controller.Admit(ctx, "node-3", map[string]Usage{"jvm_gc": {Datas: 1}}))
+	usage = 0.95
+	// The usage is read by sample.
+	assert.NoError(t, controller.Admit(ctx, "node-3", map[string]Usage{"jvm_gc": {Datas: 1}}))
+	controller.sample()
+	err = controller.Admit(ctx, "node-3", map[string]Usage{"jvm_gc": {Datas: 1}})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, defaultRetryAfterSeconds*time.Second, RetryAfter(err))
+
+	controller.expireNodes(time.Now())
+	assert.Len(t, controller.nodeLimiters, 3)
+	controller.expireNodes(time.Now().Add(nodeIdleTimeout))
+	assert.Empty(t, controller.nodeLimiters)
+}
@@ -2,7 +2,6 @@ package agentmonitor
 
 import (
 	"context"
-	"log"
 
 	"github.com/CloudDetail/apo-receiver/pkg/global"
 	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
@@ -25,21 +24,13 @@ func (server *MonitedAppServer) QueryMonitedApps(ctx context.Context, request *g
 
 func (server *MonitedAppServer) QueryActiveApps(ctx context.Context, request *grpc_model.QueryActiveAppRequest) (*grpc_model.QueryActiveAppResponse, error) {
 	if len(request.ActiveApps) > 0 {
-		if err := global.CLICK_HOUSE.UpdateAppHeartTimes(request.NodeIp, request.NodeName, request.ActiveApps); err != nil {
-			log.Printf("[x Update App HeartTime] NodeIp: %s, NodeName: %s, Error: %s", request.NodeIp, request.NodeName, err.Error())
-		}
+		global.APP_REGISTRY.Heartbeat(request.NodeIp, request.NodeName, request.ActiveApps)
 	}
 	if len(request.DeadApps) > 0 {
-		if err := global.CLICK_HOUSE.UpdateAppDeadFlags(request.NodeIp, request.NodeName, request.DeadApps); err != nil {
-			log.Printf("[x Update App IsDead] NodeIp: %s, NodeName: %s, Error: %s", request.NodeIp, request.NodeName, err.Error())
-		}
+		global.APP_REGISTRY.MarkDead(request.NodeIp, request.NodeName, request.DeadApps)
 	}
 
-	result, err := global.CLICK_HOUSE.QueryActiveApps(ctx, request.NodeIp, request.NodeName, request.DeadApps)
-	if err != nil {
-		log.Printf("[x Query Active Apps] NodeIp: %s, NodeName: %s, Error: %s", request.NodeIp, request.NodeName, err.Error())
-	}
 	return &grpc_model.QueryActiveAppResponse{
-		Datas: result,
-	}, err
+		Datas: global.APP_REGISTRY.QueryActiveApps(request.NodeIp, request.NodeName, request.DeadApps),
+	}, nil
 }
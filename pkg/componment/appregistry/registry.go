@@ -0,0 +1,301 @@
+package appregistry
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+const (
+	// persistInterval is how often the heartbeat of an alive app is persisted, the changed flags are persisted at once.
+	persistInterval = 60 * time.Second
+	loadRetryPeriod = 30 * time.Second
+	// deadRetention is how long the dead apps are kept to be counted, a removed app is registered again by its heartbeat.
+	deadRetention = time.Hour
+
+	defaultMissSeconds  = 180
+	defaultDeadSeconds  = 600
+	defaultSweepSeconds = 30
+)
+
+// HeartbeatStore persists the heartbeats, the writes are expected to be batched.
+type HeartbeatStore interface {
+	LoadHeartbeats(ctx context.Context) ([]*appinfo.Heartbeat, error)
+	StoreHeartbeats(heartbeats []*appinfo.Heartbeat)
+}
+
+type nodeKey struct {
+	ip   string
+	name string
+}
+
+type appKey struct {
+	pid       uint32
+	startTime uint64
+}
+
+type appState struct {
+	heartTime   uint64
+	heartFlag   uint32
+	persistTime time.Time
+	// flagTime is when heartFlag is changed.
+	flagTime time.Time
+	// labels of the registered app, which are attached to the agent events.
+	labels map[string]string
+}
+
+// Registry keeps the heartbeats of the monitored apps in memory and answers QueryActiveApps,
+// the heartbeats are persisted by store so they are recovered after restart.
+type Registry struct {
+	store       HeartbeatStore
+	notify      func(event *model.AgentEvent)
+	missTimeout time.Duration
+	deadTimeout time.Duration
+	sweepPeriod time.Duration
+
+	lock  sync.RWMutex
+	nodes map[nodeKey]map[appKey]*appState
+	// loadTime is the earliest heart time used by sweeper, so the loaded apps have a full grace period after restart.
+	loadTime uint64
+	// reported is the counts of each node exported by metric.
+	reported map[nodeKey]NodeCounts
+}
+
+// NewRegistry creates a registry, store is nil means the heartbeats are only kept in memory,
+// notify receives the agent event when the heart flag of an app is changed.
+func NewRegistry(store HeartbeatStore, cfg *config.AppHeartbeatConfig, notify func(event *model.AgentEvent)) *Registry {
+	if cfg == nil {
+		cfg = &config.AppHeartbeatConfig{}
+	}
+	missSeconds := cfg.MissSeconds
+	if missSeconds <= 0 {
+		missSeconds = defaultMissSeconds
+	}
+	deadSeconds := cfg.DeadSeconds
+	if deadSeconds <= missSeconds {
+		deadSeconds = max(defaultDeadSeconds, missSeconds*2)
+	}
+	sweepSeconds := cfg.SweepSeconds
+	if sweepSeconds <= 0 {
+		sweepSeconds = defaultSweepSeconds
+	}
+	return &Registry{
+		store:       store,
+		notify:      notify,
+		missTimeout: time.Duration(missSeconds) * time.Second,
+		deadTimeout: time.Duration(deadSeconds) * time.Second,
+		sweepPeriod: time.Duration(sweepSeconds) * time.Second,
+		nodes:       make(map[nodeKey]map[appKey]*appState),
+		loadTime:    uint64(time.Now().Unix()),
+		reported:    make(map[nodeKey]NodeCounts),
+	}
+}
+
+// Start loads the alive apps from store and starts the sweeper, the loading is retried in background until succeed.
+func (r *Registry) Start(ctx context.Context) {
+	go r.sweepLoop(ctx)
+	if r.store == nil {
+		return
+	}
+	err := r.load(ctx)
+	if err == nil {
+		return
+	}
+	log.Printf("[x Load App Heartbeats] %s, retry after %s", err.Error(), loadRetryPeriod)
+	go func() {
+		timer := time.NewTicker(loadRetryPeriod)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				if err := r.load(ctx); err != nil {
+					log.Printf("[x Load App Heartbeats] %s, retry after %s", err.Error(), loadRetryPeriod)
+					continue
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (r *Registry) load(ctx context.Context) error {
+	heartbeats, err := r.store.LoadHeartbeats(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := time.Now()
+	for _, heartbeat := range heartbeats {
+		apps := r.getApps(nodeKey{ip: heartbeat.NodeIp, name: heartbeat.NodeName})
+		key := appKey{pid: heartbeat.HostPid, startTime: heartbeat.StartTime}
+		if _, exist := apps[key]; exist {
+			// Updated after started, which is newer.
+			continue
+		}
+		apps[key] = &appState{
+			heartTime:   heartbeat.HeartTime,
+			heartFlag:   heartbeat.HeartFlag,
+			persistTime: now,
+		}
+	}
+	log.Printf("[Load App Heartbeats] Count: %d", len(heartbeats))
+	return nil
+}
+
+func (r *Registry) getApps(node nodeKey) map[appKey]*appState {
+	apps, exist := r.nodes[node]
+	if !exist {
+		apps = make(map[appKey]*appState)
+		r.nodes[node] = apps
+	}
+	return apps
+}
+
+// Register adds the app reported by agent as alive.
+func (r *Registry) Register(appInfo *appinfo.AppInfo) {
+	node := nodeKey{ip: appInfo.Labels["node_ip"], name: appInfo.Labels["node_name"]}
+	key := appKey{pid: appInfo.HostPid, startTime: appInfo.StartTime}
+	now := time.Now()
+
+	r.lock.Lock()
+	apps := r.getApps(node)
+	state, exist := apps[key]
+	if !exist {
+		state = &appState{heartFlag: appinfo.HEART_FLAG_ALIVE, flagTime: now}
+		apps[key] = state
+	}
+	state.labels = appInfo.Labels
+	state.heartTime = uint64(now.Unix())
+	event := r.changeFlag(node, key, state, appinfo.HEART_FLAG_ALIVE, now)
+	state.persistTime = now
+	heartbeat := newHeartbeat(node, key, state, now)
+	r.lock.Unlock()
+
+	r.persist(heartbeat)
+	r.sendEvents(event)
+}
+
+// Heartbeat updates the heart time of the registered apps and brings the missing or dead ones back alive.
+// The unknown apps are registered as alive, as they may be registered by other receivers or removed after deadRetention.
+func (r *Registry) Heartbeat(nodeIp string, nodeName string, activeApps []*grpc_model.QueryActiveApp) {
+	node := nodeKey{ip: nodeIp, name: nodeName}
+	now := time.Now()
+	heartbeats := make([]*appinfo.Heartbeat, 0)
+	events := make([]*model.AgentEvent, 0)
+
+	r.lock.Lock()
+	apps := r.getApps(node)
+	for _, activeApp := range activeApps {
+		key := appKey{pid: activeApp.Pid, startTime: activeApp.StartTime}
+		state, exist := apps[key]
+		if !exist {
+			state = &appState{heartFlag: appinfo.HEART_FLAG_ALIVE, flagTime: now}
+			apps[key] = state
+		}
+		state.heartTime = uint64(now.Unix())
+		event := r.changeFlag(node, key, state, appinfo.HEART_FLAG_ALIVE, now)
+		if !exist || event != nil || now.Sub(state.persistTime) >= persistInterval {
+			state.persistTime = now
+			heartbeats = append(heartbeats, newHeartbeat(node, key, state, now))
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+	r.lock.Unlock()
+
+	r.persist(heartbeats...)
+	r.sendEvents(events...)
+}
+
+// MarkDead marks the apps reported by agent dead, they are kept for deadRetention to be counted.
+func (r *Registry) MarkDead(nodeIp string, nodeName string, deadApps []*grpc_model.QueryActiveApp) {
+	node := nodeKey{ip: nodeIp, name: nodeName}
+	now := time.Now()
+	heartbeats := make([]*appinfo.Heartbeat, 0)
+	events := make([]*model.AgentEvent, 0)
+
+	r.lock.Lock()
+	apps := r.nodes[node]
+	for _, deadApp := range deadApps {
+		key := appKey{pid: deadApp.Pid, startTime: deadApp.StartTime}
+		state, exist := apps[key]
+		if !exist {
+			continue
+		}
+		event := r.changeFlag(node, key, state, appinfo.HEART_FLAG_DEAD, now)
+		if event == nil {
+			continue
+		}
+		state.persistTime = now
+		heartbeats = append(heartbeats, newHeartbeat(node, key, state, now))
+		events = append(events, event)
+		log.Printf("[Mark App Dead] NodeIp: %s, Pid: %d", nodeIp, deadApp.Pid)
+	}
+	r.lock.Unlock()
+
+	r.persist(heartbeats...)
+	r.sendEvents(events...)
+}
+
+// QueryActiveApps returns the apps of node which are not dead, sorted by pid and start time.
+func (r *Registry) QueryActiveApps(nodeIp string, nodeName string, deadApps []*grpc_model.QueryActiveApp) []*grpc_model.QueryActiveApp {
+	deadKeys := make(map[appKey]struct{}, len(deadApps))
+	for _, deadApp := range deadApps {
+		deadKeys[appKey{pid: deadApp.Pid, startTime: deadApp.StartTime}] = struct{}{}
+	}
+
+	r.lock.RLock()
+	result := make([]*grpc_model.QueryActiveApp, 0)
+	for key, state := range r.nodes[nodeKey{ip: nodeIp, name: nodeName}] {
+		if state.heartFlag == appinfo.HEART_FLAG_DEAD {
+			continue
+		}
+		if _, dead := deadKeys[key]; dead {
+			continue
+		}
+		result = append(result, &grpc_model.QueryActiveApp{
+			Pid:       key.pid,
+			StartTime: key.startTime,
+		})
+	}
+	r.lock.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Pid != result[j].Pid {
+			return result[i].Pid < result[j].Pid
+		}
+		return result[i].StartTime < result[j].StartTime
+	})
+	return result
+}
+
+func (r *Registry) persist(heartbeats ...*appinfo.Heartbeat) {
+	if r.store == nil || len(heartbeats) == 0 {
+		return
+	}
+	r.store.StoreHeartbeats(heartbeats)
+}
+
+func newHeartbeat(node nodeKey, key appKey, state *appState, now time.Time) *appinfo.Heartbeat {
+	return &appinfo.Heartbeat{
+		NodeIp:     node.ip,
+		NodeName:   node.name,
+		HostPid:    key.pid,
+		StartTime:  key.startTime,
+		HeartTime:  state.heartTime,
+		HeartFlag:  state.heartFlag,
+		UpdateTime: uint64(now.UnixMilli()),
+	}
+}
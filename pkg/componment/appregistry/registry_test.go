@@ -0,0 +1,139 @@
+package appregistry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+type memoryStore struct {
+	loads  []*appinfo.Heartbeat
+	stores []*appinfo.Heartbeat
+}
+
+func (s *memoryStore) LoadHeartbeats(ctx context.Context) ([]*appinfo.Heartbeat, error) {
+	return s.loads, nil
+}
+
+func (s *memoryStore) StoreHeartbeats(heartbeats []*appinfo.Heartbeat) {
+	s.stores = append(s.stores, heartbeats...)
+}
+
+func TestRegistry(t *testing.T) {
+	store := &memoryStore{
+		loads: []*appinfo.Heartbeat{
+			{NodeIp: "10.0.0.1", NodeName: "node1", HostPid: 200, StartTime: 2, HeartTime: 100},
+		},
+	}
+	registry := NewRegistry(store, nil, nil)
+	registry.Start(context.Background())
+
+	registry.Register(&appinfo.AppInfo{
+		HostPid:   100,
+		StartTime: 1,
+		Labels:    map[string]string{"node_ip": "10.0.0.1", "node_name": "node1"},
+	})
+	if len(store.stores) != 1 || store.stores[0].HostPid != 100 || store.stores[0].HeartFlag != appinfo.HEART_FLAG_ALIVE {
+		t.Fatalf("registered app is not persisted: %v", store.stores)
+	}
+
+	activeApps := registry.QueryActiveApps("10.0.0.1", "node1", nil)
+	if len(activeApps) != 2 || activeApps[0].Pid != 100 || activeApps[1].Pid != 200 {
+		t.Fatalf("unexpected active apps: %v", activeApps)
+	}
+	if activeApps := registry.QueryActiveApps("10.0.0.2", "node2", nil); len(activeApps) != 0 {
+		t.Errorf("apps of other node are returned: %v", activeApps)
+	}
+
+	// Heartbeat within persistInterval is not persisted again, unknown apps are registered.
+	registry.Heartbeat("10.0.0.1", "node1", []*grpc_model.QueryActiveApp{{Pid: 100, StartTime: 1}, {Pid: 300, StartTime: 3}})
+	if len(store.stores) != 2 || store.stores[1].HostPid != 300 || store.stores[1].HeartFlag != appinfo.HEART_FLAG_ALIVE {
+		t.Errorf("only the unknown app should be persisted within %s: %v", persistInterval, store.stores)
+	}
+	if activeApps := registry.QueryActiveApps("10.0.0.1", "node1", nil); len(activeApps) != 3 || activeApps[2].Pid != 300 {
+		t.Errorf("unknown app should be registered: %v", activeApps)
+	}
+	// Unknown node registered by other receivers.
+	registry.Heartbeat("10.0.0.2", "node2", []*grpc_model.QueryActiveApp{{Pid: 400, StartTime: 4}})
+	if activeApps := registry.QueryActiveApps("10.0.0.2", "node2", nil); len(activeApps) != 1 || activeApps[0].Pid != 400 {
+		t.Errorf("app of unknown node should be registered: %v", activeApps)
+	}
+
+	deadApps := []*grpc_model.QueryActiveApp{{Pid: 200, StartTime: 2}}
+	if activeApps := registry.QueryActiveApps("10.0.0.1", "node1", deadApps); len(activeApps) != 2 || activeApps[0].Pid != 100 {
+		t.Errorf("dead app in request should be excluded: %v", activeApps)
+	}
+	registry.MarkDead("10.0.0.1", "node1", deadApps)
+	if last := store.stores[len(store.stores)-1]; last.HostPid != 200 || last.HeartFlag != appinfo.HEART_FLAG_DEAD {
+		t.Errorf("dead flag is not persisted: %v", last)
+	}
+	if activeApps := registry.QueryActiveApps("10.0.0.1", "node1", nil); len(activeApps) != 2 || activeApps[0].Pid != 100 {
+		t.Errorf("dead app is still active: %v", activeApps)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	events := make([]*model.AgentEvent, 0)
+	registry := NewRegistry(nil, &config.AppHeartbeatConfig{MissSeconds: 60, DeadSeconds: 120}, func(event *model.AgentEvent) {
+		events = append(events, event)
+	})
+	registry.Register(&appinfo.AppInfo{
+		HostPid:   100,
+		StartTime: 1,
+		Labels:    map[string]string{"node_ip": "10.0.0.1", "node_name": "node1", "container_id": "abc"},
+	})
+	now := time.Now()
+
+	registry.sweep(now.Add(30 * time.Second))
+	if len(events) != 0 {
+		t.Fatalf("app is swept within grace period: %v", events)
+	}
+
+	registry.sweep(now.Add(90 * time.Second))
+	if len(events) != 1 || events[0].Status || events[0].Labels["heart_flag"] != "miss" || events[0].Labels["container_id"] != "abc" {
+		t.Fatalf("want miss event, got %v", events)
+	}
+	if counts := registry.QueryNodeCounts(); len(counts) != 1 || counts[0].Miss != 1 || counts[0].Alive != 0 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+	if activeApps := registry.QueryActiveApps("10.0.0.1", "node1", nil); len(activeApps) != 1 {
+		t.Errorf("miss app should still be active: %v", activeApps)
+	}
+
+	registry.sweep(now.Add(150 * time.Second))
+	if len(events) != 2 || events[1].Labels["heart_flag"] != "dead" || events[1].Labels["previous_heart_flag"] != "miss" {
+		t.Fatalf("want dead event, got %v", events)
+	}
+	if counts := registry.QueryNodeCounts(); len(counts) != 1 || counts[0].Dead != 1 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+	if activeApps := registry.QueryActiveApps("10.0.0.1", "node1", nil); len(activeApps) != 0 {
+		t.Errorf("dead app is still active: %v", activeApps)
+	}
+
+	registry.Heartbeat("10.0.0.1", "node1", []*grpc_model.QueryActiveApp{{Pid: 100, StartTime: 1}})
+	if len(events) != 3 || !events[2].Status || events[2].Labels["previous_heart_flag"] != "dead" {
+		t.Fatalf("want alive event, got %v", events)
+	}
+
+	registry.MarkDead("10.0.0.1", "node1", []*grpc_model.QueryActiveApp{{Pid: 100, StartTime: 1}})
+	registry.sweep(time.Now().Add(deadRetention))
+	if counts := registry.QueryNodeCounts(); len(counts) != 0 {
+		t.Errorf("dead app should be removed after %s: %v", deadRetention, counts)
+	}
+
+	// The removed app is registered again by heartbeat.
+	registry.Heartbeat("10.0.0.1", "node1", []*grpc_model.QueryActiveApp{{Pid: 100, StartTime: 1}})
+	if activeApps := registry.QueryActiveApps("10.0.0.1", "node1", nil); len(activeApps) != 1 || activeApps[0].Pid != 100 {
+		t.Errorf("removed app should be registered by heartbeat: %v", activeApps)
+	}
+	registry.sweep(time.Now().Add(30 * time.Second))
+	if counts := registry.QueryNodeCounts(); len(counts) != 1 || counts[0].Alive != 1 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+}
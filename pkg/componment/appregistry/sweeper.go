@@ -0,0 +1,184 @@
+package appregistry
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	metricModel "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+)
+
+const AppHeartbeatEvent = "app_heartbeat"
+
+var heartFlagNames = map[uint32]string{
+	appinfo.HEART_FLAG_ALIVE: "alive",
+	appinfo.HEART_FLAG_MISS:  "miss",
+	appinfo.HEART_FLAG_DEAD:  "dead",
+}
+
+// NodeCounts is the count of apps in each heart flag of a node.
+type NodeCounts struct {
+	NodeIp   string `json:"nodeIp"`
+	NodeName string `json:"nodeName"`
+	Alive    int    `json:"alive"`
+	Miss     int    `json:"miss"`
+	Dead     int    `json:"dead"`
+}
+
+func (r *Registry) sweepLoop(ctx context.Context) {
+	timer := time.NewTicker(r.sweepPeriod)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			r.sweep(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sweep marks the apps without heartbeat in missTimeout as miss and in deadTimeout as dead,
+// the apps dead for deadRetention are removed.
+func (r *Registry) sweep(now time.Time) {
+	heartbeats := make([]*appinfo.Heartbeat, 0)
+	events := make([]*model.AgentEvent, 0)
+
+	r.lock.Lock()
+	for node, apps := range r.nodes {
+		for key, state := range apps {
+			if state.heartFlag == appinfo.HEART_FLAG_DEAD {
+				if now.Sub(state.flagTime) >= deadRetention {
+					delete(apps, key)
+				}
+				continue
+			}
+
+			heartTime := max(state.heartTime, r.loadTime)
+			elapsed := now.Sub(time.Unix(int64(heartTime), 0))
+			heartFlag := state.heartFlag
+			if elapsed >= r.deadTimeout {
+				heartFlag = appinfo.HEART_FLAG_DEAD
+			} else if elapsed >= r.missTimeout {
+				heartFlag = appinfo.HEART_FLAG_MISS
+			}
+			if event := r.changeFlag(node, key, state, heartFlag, now); event != nil {
+				state.persistTime = now
+				heartbeats = append(heartbeats, newHeartbeat(node, key, state, now))
+				events = append(events, event)
+				log.Printf("[Sweep App Heartbeat] NodeIp: %s, Pid: %d, HeartFlag: %s", node.ip, key.pid, heartFlagNames[heartFlag])
+			}
+		}
+		if len(apps) == 0 {
+			delete(r.nodes, node)
+		}
+	}
+	r.updateCountMetrics()
+	r.lock.Unlock()
+
+	r.persist(heartbeats...)
+	r.sendEvents(events...)
+}
+
+// changeFlag updates the heart flag of app and returns the agent event of the transition, nil means unchanged.
+func (r *Registry) changeFlag(node nodeKey, key appKey, state *appState, heartFlag uint32, now time.Time) *model.AgentEvent {
+	if state.heartFlag == heartFlag {
+		return nil
+	}
+	labels := make(map[string]string, len(state.labels)+5)
+	for k, v := range state.labels {
+		labels[k] = v
+	}
+	labels["node_ip"] = node.ip
+	labels["node_name"] = node.name
+	labels["start_time"] = strconv.FormatUint(key.startTime, 10)
+	labels["heart_flag"] = heartFlagNames[heartFlag]
+	labels["previous_heart_flag"] = heartFlagNames[state.heartFlag]
+
+	state.heartFlag = heartFlag
+	state.flagTime = now
+	return &model.AgentEvent{
+		Timestamp: uint64(now.Unix()),
+		Name:      AppHeartbeatEvent,
+		Pid:       key.pid,
+		Labels:    labels,
+		Status:    heartFlag == appinfo.HEART_FLAG_ALIVE,
+	}
+}
+
+func (r *Registry) sendEvents(events ...*model.AgentEvent) {
+	if r.notify == nil {
+		return
+	}
+	for _, event := range events {
+		if event != nil {
+			r.notify(event)
+		}
+	}
+}
+
+// QueryNodeCounts returns the count of apps in each heart flag by node.
+func (r *Registry) QueryNodeCounts() []NodeCounts {
+	r.lock.RLock()
+	result := make([]NodeCounts, 0, len(r.nodes))
+	for node := range r.nodes {
+		result = append(result, r.countLocked(node))
+	}
+	r.lock.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NodeIp != result[j].NodeIp {
+			return result[i].NodeIp < result[j].NodeIp
+		}
+		return result[i].NodeName < result[j].NodeName
+	})
+	return result
+}
+
+func (r *Registry) countLocked(node nodeKey) NodeCounts {
+	counts := NodeCounts{NodeIp: node.ip, NodeName: node.name}
+	for _, state := range r.nodes[node] {
+		switch state.heartFlag {
+		case appinfo.HEART_FLAG_ALIVE:
+			counts.Alive++
+		case appinfo.HEART_FLAG_MISS:
+			counts.Miss++
+		case appinfo.HEART_FLAG_DEAD:
+			counts.Dead++
+		}
+	}
+	return counts
+}
+
+// updateCountMetrics exports the counts of nodes, the gauge is added by the difference with the reported one.
+func (r *Registry) updateCountMetrics() {
+	for node := range r.nodes {
+		r.updateCountMetric(node, r.countLocked(node))
+	}
+	for node := range r.reported {
+		if _, exist := r.nodes[node]; !exist {
+			r.updateCountMetric(node, NodeCounts{NodeIp: node.ip, NodeName: node.name})
+			delete(r.reported, node)
+		}
+	}
+}
+
+func (r *Registry) updateCountMetric(node nodeKey, counts NodeCounts) {
+	reported := r.reported[node]
+	updateCount(node, appinfo.HEART_FLAG_ALIVE, counts.Alive-reported.Alive)
+	updateCount(node, appinfo.HEART_FLAG_MISS, counts.Miss-reported.Miss)
+	updateCount(node, appinfo.HEART_FLAG_DEAD, counts.Dead-reported.Dead)
+	r.reported[node] = counts
+}
+
+func updateCount(node nodeKey, heartFlag uint32, diff int) {
+	if diff == 0 {
+		return
+	}
+	metrics.UpdateMetric(metricModel.MetricAppHeartbeatApps, []string{node.ip, node.name, heartFlagNames[heartFlag]}, float64(diff))
+}
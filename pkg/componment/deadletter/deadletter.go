@@ -0,0 +1,374 @@
+package deadletter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	metric_model "github.com/CloudDetail/apo-receiver/pkg/metrics/model"
+)
+
+const (
+	defaultMaxRecords = 10000
+	recordFile        = "deadletter.ndjson"
+	// writePeriod is how often the added records are written to file.
+	writePeriod = time.Second
+)
+
+// StoreInstance keeps the records reported by Report, the records are dropped when it is not set.
+var StoreInstance *Store
+
+// Record is the data sent by agent which is failed to parse or rejected by receiver.
+type Record struct {
+	Id uint64 `json:"id"`
+	// Timestamp is the time when the data is rejected, Millisecond.
+	Timestamp int64  `json:"timestamp"`
+	Group     string `json:"group"`
+	// Source is the address of agent which sent the data.
+	Source string `json:"source"`
+	Error  string `json:"error"`
+	Data   string `json:"data"`
+}
+
+// Filter selects the records, the empty fields match all.
+type Filter struct {
+	Ids    []uint64 `json:"ids"`
+	Group  string   `json:"group"`
+	Source string   `json:"source"`
+}
+
+func (filter *Filter) match(record *Record) bool {
+	if filter.Group != "" && filter.Group != record.Group {
+		return false
+	}
+	if filter.Source != "" && filter.Source != record.Source {
+		return false
+	}
+	if len(filter.Ids) == 0 {
+		return true
+	}
+	for _, id := range filter.Ids {
+		if id == record.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// RedriveHandler sends the datas of group to the ingest path again, ctx carries the source of datas.
+type RedriveHandler func(ctx context.Context, group string, datas []string)
+
+// Store keeps the latest records in memory, they are also appended to a ndjson file when dir is set.
+// The file is written in background every writePeriod, so Add is not blocked by the disk.
+type Store struct {
+	mutex      sync.Mutex
+	path       string
+	maxRecords int
+	seq        uint64
+	records    []*Record
+	// pending is the records not written to file yet.
+	pending []*Record
+	// rewriteFile means the file is to be replaced with the records, eg. after redrive.
+	rewriteFile bool
+	redrive     RedriveHandler
+
+	// The fields below are only used by the writer.
+	file   *os.File
+	writer *bufio.Writer
+	// fileRecords is the count of lines in file, the file is compacted when it is much more than the records.
+	fileRecords int
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
+
+func NewStore(cfg *config.DeadLetterConfig) (*Store, error) {
+	maxRecords := cfg.MaxRecords
+	if maxRecords <= 0 {
+		maxRecords = defaultMaxRecords
+	}
+	store := &Store{
+		maxRecords: maxRecords,
+		records:    make([]*Record, 0),
+	}
+	if cfg.Dir == "" {
+		return store, nil
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+	store.path = filepath.Join(cfg.Dir, recordFile)
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	store.stopChan = make(chan struct{})
+	store.wg.Add(1)
+	go store.writeLoop()
+	return store, nil
+}
+
+func (store *Store) load() error {
+	file, err := os.Open(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		store.fileRecords++
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			continue
+		}
+		store.records = append(store.records, record)
+		if record.Id > store.seq {
+			store.seq = record.Id
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	store.evict()
+	log.Printf("Load %d dead letters from %s", len(store.records), store.path)
+	return nil
+}
+
+// SetRedriveHandler sets the ingest path used by Redrive.
+func (store *Store) SetRedriveHandler(redrive RedriveHandler) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.redrive = redrive
+}
+
+func (store *Store) Add(group string, source string, err error, data string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.seq++
+	record := &Record{
+		Id:        store.seq,
+		Timestamp: time.Now().UnixMilli(),
+		Group:     group,
+		Source:    source,
+		Error:     err.Error(),
+		Data:      data,
+	}
+	store.records = append(store.records, record)
+	store.evict()
+	if store.path != "" {
+		store.pending = append(store.pending, record)
+	}
+	metrics.UpdateMetric(metric_model.MetricDeadLetterCount, []string{group}, 1)
+}
+
+func (store *Store) evict() {
+	if overflow := len(store.records) - store.maxRecords; overflow > 0 {
+		store.records = store.records[overflow:]
+	}
+}
+
+func (store *Store) writeLoop() {
+	defer store.wg.Done()
+	timer := time.NewTicker(writePeriod)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			store.writeFile()
+		case <-store.stopChan:
+			return
+		}
+	}
+}
+
+// writeFile appends the pending records to file, or rewrites the file if it is required or too large.
+func (store *Store) writeFile() {
+	store.mutex.Lock()
+	pending := store.pending
+	store.pending = nil
+	var records []*Record
+	if store.rewriteFile || store.fileRecords+len(pending) >= 2*store.maxRecords {
+		// The evicted records are still in file, rewrite the kept ones.
+		records = append(make([]*Record, 0, len(store.records)), store.records...)
+		store.rewriteFile = false
+	}
+	store.mutex.Unlock()
+
+	var err error
+	if records != nil {
+		err = store.rewrite(records)
+	} else if len(pending) > 0 {
+		err = store.append(pending)
+	}
+	if err != nil {
+		log.Printf("[x Write Dead Letter] %s", err.Error())
+	}
+}
+
+func (store *Store) append(records []*Record) error {
+	if store.file == nil {
+		file, err := os.OpenFile(store.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		store.file = file
+		store.writer = bufio.NewWriter(file)
+	}
+	for _, record := range records {
+		content, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if _, err = store.writer.Write(append(content, '\n')); err != nil {
+			return err
+		}
+		store.fileRecords++
+	}
+	return store.writer.Flush()
+}
+
+func (store *Store) closeFile() {
+	if store.file == nil {
+		return
+	}
+	if err := store.writer.Flush(); err != nil {
+		log.Printf("[x Write Dead Letter] %s", err.Error())
+	}
+	store.file.Close()
+	store.file, store.writer = nil, nil
+}
+
+// rewrite replaces the file with records, the file is opened again by the next append.
+func (store *Store) rewrite(records []*Record) error {
+	store.closeFile()
+	tmpPath := store.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err = encoder.Encode(record); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Rename(tmpPath, store.path); err != nil {
+		return err
+	}
+	store.fileRecords = len(records)
+	return nil
+}
+
+// Stop writes the pending records and closes the file.
+func (store *Store) Stop() {
+	if store.stopChan == nil {
+		return
+	}
+	close(store.stopChan)
+	store.wg.Wait()
+	store.writeFile()
+	store.closeFile()
+}
+
+// Query returns at most limit records matched by filter, the latest ones are returned first.
+func (store *Store) Query(filter *Filter, limit int) []*Record {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	result := make([]*Record, 0)
+	for i := len(store.records) - 1; i >= 0; i-- {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		if filter.match(store.records[i]) {
+			result = append(result, store.records[i])
+		}
+	}
+	return result
+}
+
+// Redrive removes the records matched by filter and sends them to the ingest path again,
+// they are recorded again with new ids if still rejected.
+func (store *Store) Redrive(filter *Filter) (int, error) {
+	store.mutex.Lock()
+	redrive := store.redrive
+	if redrive == nil {
+		store.mutex.Unlock()
+		return 0, errors.New("no redrive handler is set")
+	}
+	toRedrives := make([]*Record, 0)
+	kept := make([]*Record, 0, len(store.records))
+	for _, record := range store.records {
+		if filter.match(record) {
+			toRedrives = append(toRedrives, record)
+		} else {
+			kept = append(kept, record)
+		}
+	}
+	if len(toRedrives) > 0 {
+		store.records = kept
+		store.rewriteFile = store.path != ""
+	}
+	// Unlock before redrive, the rejected datas are added again.
+	store.mutex.Unlock()
+
+	for _, record := range toRedrives {
+		redrive(WithSource(context.Background(), record.Source), record.Group, []string{record.Data})
+	}
+	return len(toRedrives), nil
+}
+
+// Report records the rejected data when StoreInstance is set, the source is read from ctx.
+func Report(ctx context.Context, group string, data string, err error) {
+	if StoreInstance == nil {
+		return
+	}
+	StoreInstance.Add(group, SourceOf(ctx), err, data)
+}
+
+type sourceKey struct{}
+
+// WithSource sets the source of datas, which is used instead of the grpc peer.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceOf returns the source set by WithSource, or the host of grpc peer.
+func SourceOf(ctx context.Context) string {
+	if source, ok := ctx.Value(sourceKey{}).(string); ok {
+		return source
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+func TestStoreRedrive(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(&config.DeadLetterConfig{Dir: dir, MaxRecords: 2})
+	assert.NoError(t, err)
+
+	parseErr := errors.New("invalid json")
+	store.Add("span_trace", "10.0.0.1", parseErr, "a")
+	store.Add("flame_graph", "10.0.0.2", parseErr, "b")
+	store.Add("jvm_gc", "10.0.0.2", parseErr, "c")
+
+	records := store.Query(&Filter{}, 0)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "c", records[0].Data)
+		assert.Equal(t, "b", records[1].Data)
+		assert.Equal(t, "invalid json", records[1].Error)
+	}
+
+	// Reloaded from file after restart.
+	store.Stop()
+	store, err = NewStore(&config.DeadLetterConfig{Dir: dir, MaxRecords: 2})
+	assert.NoError(t, err)
+	assert.Len(t, store.Query(&Filter{Source: "10.0.0.2"}, 0), 2)
+
+	_, err = store.Redrive(&Filter{})
+	assert.Error(t, err)
+
+	redriven := make(map[string]string)
+	store.SetRedriveHandler(func(ctx context.Context, group string, datas []string) {
+		redriven[group] = SourceOf(ctx)
+		store.Add(group, SourceOf(ctx), parseErr, datas[0])
+	})
+	count, err := store.Redrive(&Filter{Group: "jvm_gc"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, map[string]string{"jvm_gc": "10.0.0.2"}, redriven)
+
+	records = store.Query(&Filter{Group: "jvm_gc"}, 0)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, uint64(4), records[0].Id)
+	}
+
+	// The file is rewritten with the records after redrive.
+	store.Stop()
+	store, err = NewStore(&config.DeadLetterConfig{Dir: dir, MaxRecords: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, store.fileRecords)
+	records = store.Query(&Filter{}, 0)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, uint64(4), records[0].Id)
+		assert.Equal(t, "b", records[1].Data)
+	}
+	store.Stop()
+}
@@ -0,0 +1,241 @@
+package k8sevent
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+	"github.com/CloudDetail/metadata/model/resource"
+	"github.com/CloudDetail/metadata/source/apiserver"
+)
+
+const (
+	// eventResType is the resource type of events in the metadata watchers.
+	eventResType resource.ResType = 0x0100
+
+	defaultLeaseName = "apo-receiver-k8s-event"
+	leaseDuration    = 15 * time.Second
+	renewDeadline    = 10 * time.Second
+	retryPeriod      = 2 * time.Second
+)
+
+// Watcher watches the k8s events from apiserver, each added or updated event is sent to handler.
+// When leader election is enabled, only the events watched by the leader of receivers are sent.
+type Watcher struct {
+	cfg       *config.K8sEventWatcherConfig
+	ctx       context.Context
+	clusterId string
+	handler   func(k8sEvent *grpc_model.K8SEvent)
+	// startTime is used to skip the history events listed when the watcher starts.
+	startTime time.Time
+	leading   atomic.Bool
+}
+
+func NewWatcher(cfg *config.K8sEventWatcherConfig, handler func(k8sEvent *grpc_model.K8SEvent)) *Watcher {
+	return &Watcher{
+		cfg:       cfg,
+		clusterId: cfg.ClusterID,
+		handler:   handler,
+	}
+}
+
+// Register watches the events with the metadata watchers, which share the client and informers with metadata.
+// It should be called before metadata is started, the events are watched until ctx is done.
+func (watcher *Watcher) Register(ctx context.Context) {
+	watcher.ctx = ctx
+	apiserver.K8sWatcher.Watchers[eventResType] = watcher
+	// The watchers without handlers are not started.
+	apiserver.K8sWatcher.WithHandler(eventResType, &clusterIdHandler{watcher: watcher})
+}
+
+// Init is called by the metadata watchers with the shared client and informer factory, which is started after.
+func (watcher *Watcher) Init(ctx context.Context, client *k8s.Clientset, factory informers.SharedInformerFactory, namespace string, handlersMap apiserver.ResourceHandlersMap) {
+	watcher.watch(client, factory)
+}
+
+func (watcher *Watcher) Run() {
+	log.Printf("Start to watch k8s events with metadata, ClusterId: %s", watcher.clusterId)
+}
+
+// Start creates its own client when metadata is not watched from apiserver, and watches the events until ctx is done.
+func (watcher *Watcher) Start(ctx context.Context) error {
+	authType := watcher.cfg.KubeAuthType
+	if authType == "" {
+		authType = string(apiserver.AuthTypeServiceAccount)
+	}
+	client, clusterId, err := apiserver.MakeClient(apiserver.APIConfig{
+		AuthType:     apiserver.AuthType(authType),
+		AuthFilePath: watcher.cfg.KubeAuthConfig,
+	})
+	if err != nil {
+		return err
+	}
+	if watcher.clusterId == "" {
+		watcher.clusterId = clusterId
+	}
+
+	watcher.ctx = ctx
+	factory := informers.NewSharedInformerFactory(client, 0)
+	informer := watcher.watch(client, factory)
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return errors.New("fail to sync k8s events")
+	}
+	log.Printf("Start to watch k8s events, ClusterId: %s", watcher.clusterId)
+	return nil
+}
+
+func (watcher *Watcher) watch(client *k8s.Clientset, factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+	watcher.startTime = time.Now()
+	informer := factory.Core().V1().Events().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: watcher.onEvent,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldEvent, oldOk := oldObj.(*corev1.Event)
+			newEvent, newOk := newObj.(*corev1.Event)
+			if oldOk && newOk && oldEvent.ResourceVersion == newEvent.ResourceVersion {
+				return
+			}
+			watcher.onEvent(newObj)
+		},
+	})
+	if watcher.cfg.LeaderElection {
+		go watcher.elect(client)
+	} else {
+		watcher.leading.Store(true)
+	}
+	return informer
+}
+
+// elect campaigns for the lease until ctx is done, the lease is released when ctx is done.
+func (watcher *Watcher) elect(client *k8s.Clientset) {
+	identity, _ := os.Hostname()
+	namespace := watcher.cfg.LeaseNamespace
+	if namespace == "" {
+		namespace = os.Getenv("POD_NAMESPACE")
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	name := watcher.cfg.LeaseName
+	if name == "" {
+		name = defaultLeaseName
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+	for watcher.ctx.Err() == nil {
+		leaderelection.RunOrDie(watcher.ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(ctx context.Context) {
+					watcher.leading.Store(true)
+					log.Printf("[K8s Event Watcher] %s is the leader of %s/%s", identity, namespace, name)
+				},
+				OnStoppedLeading: func() {
+					watcher.leading.Store(false)
+					log.Printf("[K8s Event Watcher] %s is no longer the leader of %s/%s", identity, namespace, name)
+				},
+			},
+		})
+	}
+}
+
+func (watcher *Watcher) onEvent(obj interface{}) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+	lastTime := getLastTime(event)
+	if !watcher.leading.Load() || lastTime.Before(watcher.startTime) {
+		return
+	}
+	watcher.handler(toK8sEvent(watcher.clusterId, event, lastTime))
+}
+
+// getLastTime returns the last time the event occurred, the events.k8s.io reporters only set EventTime and Series.
+func getLastTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if event.Series != nil && !event.Series.LastObservedTime.IsZero() {
+		return event.Series.LastObservedTime.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+func toK8sEvent(clusterId string, event *corev1.Event, lastTime time.Time) *grpc_model.K8SEvent {
+	firstTime := event.FirstTimestamp.Time
+	if event.FirstTimestamp.IsZero() {
+		firstTime = event.EventTime.Time
+	}
+	count := event.Count
+	if event.Series != nil && event.Series.Count > count {
+		count = event.Series.Count
+	}
+	sourceComponent := event.Source.Component
+	if sourceComponent == "" {
+		sourceComponent = event.ReportingController
+	}
+	k8sEvent := &grpc_model.K8SEvent{
+		Timestamp:        uint64(lastTime.UnixNano()),
+		ClusterId:        clusterId,
+		Name:             event.Name,
+		Uid:              string(event.UID),
+		Type:             event.Type,
+		Reason:           event.Reason,
+		Message:          event.Message,
+		Count:            count,
+		ObjectKind:       event.InvolvedObject.Kind,
+		ObjectNamespace:  event.InvolvedObject.Namespace,
+		ObjectName:       event.InvolvedObject.Name,
+		ObjectUid:        string(event.InvolvedObject.UID),
+		ObjectFieldPath:  event.InvolvedObject.FieldPath,
+		ObjectApiVersion: event.InvolvedObject.APIVersion,
+		SourceComponent:  sourceComponent,
+		SourceHost:       event.Source.Host,
+	}
+	if !firstTime.IsZero() {
+		k8sEvent.FirstTimestamp = uint64(firstTime.UnixNano())
+	}
+	return k8sEvent
+}
+
+// clusterIdHandler receives the cluster id from the metadata watchers, the resources are not used.
+type clusterIdHandler struct {
+	watcher *Watcher
+}
+
+func (h *clusterIdHandler) SetClusterID(clusterID string) {
+	if h.watcher.clusterId == "" {
+		h.watcher.clusterId = clusterID
+	}
+}
+
+func (h *clusterIdHandler) AddResource(res *resource.Resource)    {}
+func (h *clusterIdHandler) UpdateResource(res *resource.Resource) {}
+func (h *clusterIdHandler) DeleteResource(res *resource.Resource) {}
+func (h *clusterIdHandler) Reset(resList []*resource.Resource)    {}
+func (h *clusterIdHandler) SetExporter(resource.Exporter)         {}
@@ -54,7 +54,7 @@ func (signals *SingalsCache) CollectMetrics() {
 				if len(countMetrics) > 0 {
 					log.Printf("[Write Slow Report Metics] Count: %d", len(countMetrics))
 					for _, countMetric := range countMetrics {
-						global.CLICK_HOUSE.StoreReportMetric(countMetric)
+						global.SINK.StoreReportMetric(countMetric)
 					}
 				}
 				return true
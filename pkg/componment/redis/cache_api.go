@@ -1,9 +1,15 @@
 package redis
 
-import "github.com/CloudDetail/apo-module/model/v1"
+import (
+	"context"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+)
 
 type ExpirableCache interface {
 	Start()
+	// Stop writes the buffered journal, it is called after analyzer is stopped.
+	Stop()
 
 	IsLocal() bool
 
@@ -31,7 +37,8 @@ type ExpirableCache interface {
 
 	// Stream + ConsumeGroup
 	NotifyReportTraceId(traceId string)
-	SubscribeReportTraceId(subscriber Subscriber)
+	// SubscribeReportTraceId blocks until ctx is done.
+	SubscribeReportTraceId(ctx context.Context, subscriber Subscriber)
 
 	// Signal
 	StoreSignal(nodeIp string, json string)
@@ -40,6 +47,21 @@ type ExpirableCache interface {
 	StoreRelationTraceId(key string, traceId string)
 	GetRelationTraceId(key string) string
 
+	// MarkSpanTraceSent returns false if the span trace is already marked in expireSecond, shared by receivers.
+	MarkSpanTraceSent(traceId string, apmSpanId string, expireSecond int64) bool
+
+	// StreamSequence keeps the acked sequence of agent stream for expireSecond, shared by receivers.
+	GetStreamSequence(agentId string) uint64
+	SetStreamSequence(agentId string, sequence uint64, expireSecond int64)
+
+	// Journal keeps the pending works of analyzer by kind and key, value is overwritten by the same key.
+	PutJournal(kind string, key string, value string)
+	DeleteJournal(kind string, key string)
+	// RecoverJournal takes the journal left by the stopped receivers, eg. the previous process before restart.
+	RecoverJournal() []*JournalEntry
+	// IsJournalKept returns true if the journal is kept after stop and recovered later.
+	IsJournalKept() bool
+
 	// Sampler
 	GetSampleValue() int64
 	InitSampleValue(sampleValue int64, expirePeriod int64)
@@ -0,0 +1,321 @@
+package redis
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	journalFile = "analyzer.journal"
+	// journalCompactOps rewrites the journal file when the ops are much more than the live entries.
+	journalCompactOps = 10000
+
+	// journalFlushPeriod batches the journal ops, only the latest op of a key is written in each period.
+	journalFlushPeriod = time.Second
+
+	journalOpPut    = "put"
+	journalOpDelete = "delete"
+
+	// The spans are journaled in local cache, so the recovered works still find them after restart.
+	journalSpanTrace   = "span_trace"
+	journalOnOffMetric = "onoff_metric"
+)
+
+// JournalEntry is the pending work of analyzer, eg. the trace waiting for delay_duration or the task to retry.
+type JournalEntry struct {
+	Kind  string `json:"kind"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+type journalOp struct {
+	Op string `json:"op"`
+	JournalEntry
+}
+
+// journalBuffer keeps the latest op of each key until they are written in batch.
+type journalBuffer struct {
+	lock    sync.Mutex
+	pending map[string]*journalOp
+}
+
+func newJournalBuffer() *journalBuffer {
+	return &journalBuffer{
+		pending: make(map[string]*journalOp),
+	}
+}
+
+func (buffer *journalBuffer) add(op *journalOp) {
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+
+	buffer.pending[journalKey(op.Kind, op.Key)] = op
+}
+
+func (buffer *journalBuffer) take() []*journalOp {
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+
+	if len(buffer.pending) == 0 {
+		return nil
+	}
+	ops := make([]*journalOp, 0, len(buffer.pending))
+	for _, op := range buffer.pending {
+		ops = append(ops, op)
+	}
+	buffer.pending = make(map[string]*journalOp)
+	return ops
+}
+
+// fileJournal appends the journal ops to <dir>/analyzer.journal, the entries left by the previous process are recovered.
+// The ops are buffered and written by flush.
+type fileJournal struct {
+	lock      sync.Mutex // guards live, buffer and recovered
+	writeLock sync.Mutex // guards file and ops
+	path      string
+	file      *os.File
+	ops       int
+	buffer    *journalBuffer
+	live      map[string]*JournalEntry
+	recovered []*JournalEntry
+}
+
+func newFileJournal(dir string) (*fileJournal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	journal := &fileJournal{
+		path:   filepath.Join(dir, journalFile),
+		buffer: newJournalBuffer(),
+		live:   make(map[string]*JournalEntry),
+	}
+	left, err := journal.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range left {
+		journal.recovered = append(journal.recovered, entry)
+	}
+	// Keep the recovered entries in file until they are put again.
+	if err = journal.rewrite(journal.recovered); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+func journalKey(kind string, key string) string {
+	return kind + ":" + key
+}
+
+func (journal *fileJournal) load() (map[string]*JournalEntry, error) {
+	entries := make(map[string]*JournalEntry)
+	file, err := os.Open(journal.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		op := &journalOp{}
+		if err := json.Unmarshal(scanner.Bytes(), op); err != nil {
+			// The last line may be partially written when crashed.
+			continue
+		}
+		key := journalKey(op.Kind, op.Key)
+		if op.Op == journalOpPut {
+			entry := op.JournalEntry
+			entries[key] = &entry
+		} else {
+			delete(entries, key)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func (journal *fileJournal) put(kind string, key string, value string) {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	entry := &JournalEntry{Kind: kind, Key: key, Value: value}
+	journal.live[journalKey(kind, key)] = entry
+	journal.buffer.add(&journalOp{Op: journalOpPut, JournalEntry: *entry})
+}
+
+func (journal *fileJournal) delete(kind string, key string) {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	if _, found := journal.live[journalKey(kind, key)]; !found {
+		return
+	}
+	delete(journal.live, journalKey(kind, key))
+	journal.buffer.add(&journalOp{Op: journalOpDelete, JournalEntry: JournalEntry{Kind: kind, Key: key}})
+}
+
+// takeRecovered returns the entries left by the previous process only once.
+func (journal *fileJournal) takeRecovered() []*JournalEntry {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	recovered := journal.recovered
+	journal.recovered = nil
+	return recovered
+}
+
+// takeSpans returns the recovered spans ordered by their index in trace, the others are left to takeRecovered.
+func (journal *fileJournal) takeSpans() []*JournalEntry {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	spans := make([]*JournalEntry, 0)
+	others := make([]*JournalEntry, 0, len(journal.recovered))
+	for _, entry := range journal.recovered {
+		if entry.Kind == journalSpanTrace || entry.Kind == journalOnOffMetric {
+			spans = append(spans, entry)
+		} else {
+			others = append(others, entry)
+		}
+	}
+	journal.recovered = others
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].Kind != spans[j].Kind {
+			return spans[i].Kind < spans[j].Kind
+		}
+		traceI, indexI := parseSpanKey(spans[i].Key)
+		traceJ, indexJ := parseSpanKey(spans[j].Key)
+		if traceI != traceJ {
+			return traceI < traceJ
+		}
+		return indexI < indexJ
+	})
+	return spans
+}
+
+func spanKey(traceId string, index int) string {
+	return traceId + "/" + strconv.Itoa(index)
+}
+
+func parseSpanKey(key string) (string, int) {
+	separator := strings.LastIndexByte(key, '/')
+	if separator < 0 {
+		return key, 0
+	}
+	index, _ := strconv.Atoi(key[separator+1:])
+	return key[:separator], index
+}
+
+// flush writes the buffered ops, the file is rewritten with the live entries instead
+// when the ops are much more than them.
+func (journal *fileJournal) flush() {
+	journal.writeLock.Lock()
+	defer journal.writeLock.Unlock()
+
+	if journal.file == nil {
+		return
+	}
+	journal.lock.Lock()
+	ops := journal.buffer.take()
+	var live []*JournalEntry
+	total := journal.ops + len(ops)
+	compact := total >= journalCompactOps && total >= 2*len(journal.live)
+	if compact {
+		live = make([]*JournalEntry, 0, len(journal.live))
+		for _, entry := range journal.live {
+			live = append(live, entry)
+		}
+	}
+	journal.lock.Unlock()
+
+	if compact {
+		err := journal.rewrite(live)
+		if err == nil {
+			return
+		}
+		log.Printf("[x Compact Journal] %s", err.Error())
+	}
+	if len(ops) == 0 {
+		return
+	}
+	writer := bufio.NewWriter(journal.file)
+	encoder := json.NewEncoder(writer)
+	for _, op := range ops {
+		if err := encoder.Encode(op); err != nil {
+			log.Printf("[x Write Journal] %s", err.Error())
+			return
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		log.Printf("[x Write Journal] %s", err.Error())
+		return
+	}
+	if err := journal.file.Sync(); err != nil {
+		log.Printf("[x Sync Journal] %s", err.Error())
+	}
+	journal.ops += len(ops)
+}
+
+// close writes the buffered ops and closes the file.
+func (journal *fileJournal) close() {
+	journal.flush()
+
+	journal.writeLock.Lock()
+	defer journal.writeLock.Unlock()
+	if journal.file != nil {
+		journal.file.Close()
+		journal.file = nil
+	}
+}
+
+// rewrite replaces the file with the entries and reopens it to append.
+func (journal *fileJournal) rewrite(entries []*JournalEntry) error {
+	tmpPath := journal.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if err = encoder.Encode(&journalOp{Op: journalOpPut, JournalEntry: *entry}); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Rename(tmpPath, journal.path); err != nil {
+		return err
+	}
+	if journal.file != nil {
+		journal.file.Close()
+	}
+	if journal.file, err = os.OpenFile(journal.path, os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+		return err
+	}
+	journal.ops = len(entries)
+	return nil
+}
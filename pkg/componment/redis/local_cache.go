@@ -1,6 +1,9 @@
 package redis
 
 import (
+	"context"
+	"encoding/json"
+	"log"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,6 +17,9 @@ type LocalCache struct {
 	checkMissMap sync.Map // <traceId, ExpireData>
 	signalMap    sync.Map
 	relationMap  sync.Map
+	sentSpanMap  sync.Map // <traceId-apmSpanId, ExpirableData>
+	sequenceMap  sync.Map // <agentId, ExpirableData>
+	journal      *fileJournal
 	sampleValue  *atomic.Int64
 	sampleTime   *atomic.Int64
 
@@ -25,8 +31,17 @@ type LocalCache struct {
 	stopChan       chan bool
 }
 
-func NewLocalCache(expireTime int64) *LocalCache {
-	return &LocalCache{
+// NewLocalCache journals the pending works of analyzer and the cached spans in journalDir,
+// they are kept in memory only if journalDir is empty.
+func NewLocalCache(expireTime int64, journalDir string) (*LocalCache, error) {
+	var journal *fileJournal
+	if journalDir != "" {
+		var err error
+		if journal, err = newFileJournal(journalDir); err != nil {
+			return nil, err
+		}
+	}
+	cache := &LocalCache{
 		expireTime:     expireTime,
 		sampleValue:    &atomic.Int64{},
 		sampleTime:     &atomic.Int64{},
@@ -34,6 +49,37 @@ func NewLocalCache(expireTime int64) *LocalCache {
 		slowTraceIds:   make([]string, 0),
 		errorTraceIds:  make([]string, 0),
 		stopChan:       make(chan bool),
+		journal:        journal,
+	}
+	if journal != nil {
+		cache.recoverSpans()
+	}
+	return cache, nil
+}
+
+// recoverSpans caches the spans journaled by the previous process again, they are journaled with the same keys.
+func (cache *LocalCache) recoverSpans() {
+	spans := cache.journal.takeSpans()
+	for _, entry := range spans {
+		switch entry.Kind {
+		case journalSpanTrace:
+			trace := &model.Trace{}
+			if err := json.Unmarshal([]byte(entry.Value), trace); err != nil {
+				log.Printf("[x Recover Trace] %s", err.Error())
+				continue
+			}
+			cache.StoreTrace(trace, entry.Value)
+		case journalOnOffMetric:
+			metric := &model.OnOffMetricGroup{}
+			if err := json.Unmarshal([]byte(entry.Value), metric); err != nil {
+				log.Printf("[x Recover Metric] %s", err.Error())
+				continue
+			}
+			cache.StoreMetric(metric, entry.Value)
+		}
+	}
+	if len(spans) > 0 {
+		log.Printf("Recover %d spans from journal", len(spans))
 	}
 }
 
@@ -41,8 +87,16 @@ func (cache *LocalCache) Start() {
 	go cache.checkExpire()
 }
 
+// Stop writes the buffered journal and closes it.
 func (cache *LocalCache) Stop() {
 	close(cache.stopChan)
+	if cache.journal != nil {
+		cache.journal.close()
+	}
+}
+
+func (cache *LocalCache) IsJournalKept() bool {
+	return cache.journal != nil
 }
 
 func (cache *LocalCache) IsLocal() bool {
@@ -57,7 +111,8 @@ func (cache *LocalCache) StoreMetric(metric *model.OnOffMetricGroup, json string
 		expirableList = newExpirableList()
 		cache.traceMap.Store(metric.TraceId, expirableList)
 	}
-	expirableList.addMetric(cache.expireTime, metric)
+	index := expirableList.addMetric(cache.expireTime, metric)
+	cache.journalSpan(journalOnOffMetric, metric.TraceId, index, metric, json)
 }
 
 func (cache *LocalCache) GetMetricSize(traceId string) int {
@@ -78,7 +133,7 @@ func (cache *LocalCache) GetMetrics(traceId string) []*model.OnOffMetricGroup {
 	}
 }
 
-func (cache *LocalCache) StoreTrace(trace *model.Trace, _json string) {
+func (cache *LocalCache) StoreTrace(trace *model.Trace, json string) {
 	var expirableList *ExpirableList
 	if listInterface, ok := cache.traceMap.Load(trace.Labels.TraceId); ok {
 		expirableList = listInterface.(*ExpirableList)
@@ -86,7 +141,39 @@ func (cache *LocalCache) StoreTrace(trace *model.Trace, _json string) {
 		expirableList = newExpirableList()
 		cache.traceMap.Store(trace.Labels.TraceId, expirableList)
 	}
-	expirableList.addTrace(cache.expireTime, trace)
+	index := expirableList.addTrace(cache.expireTime, trace)
+	cache.journalSpan(journalSpanTrace, trace.Labels.TraceId, index, trace, json)
+}
+
+// journalSpan journals the span by its index in trace, value is marshaled when content is empty.
+func (cache *LocalCache) journalSpan(kind string, traceId string, index int, value any, content string) {
+	if cache.journal == nil {
+		return
+	}
+	if content == "" {
+		jsonBytes, err := json.Marshal(value)
+		if err != nil {
+			return
+		}
+		content = string(jsonBytes)
+	}
+	cache.journal.put(kind, spanKey(traceId, index), content)
+}
+
+// deleteSpans deletes the journaled spans of expired trace.
+func (cache *LocalCache) deleteSpans(traceId string, list *ExpirableList) {
+	if cache.journal == nil {
+		return
+	}
+	list.lock.Lock()
+	traceSize, metricSize := len(list.traces), len(list.metrics)
+	list.lock.Unlock()
+	for i := 0; i < traceSize; i++ {
+		cache.journal.delete(journalSpanTrace, spanKey(traceId, i))
+	}
+	for i := 0; i < metricSize; i++ {
+		cache.journal.delete(journalOnOffMetric, spanKey(traceId, i))
+	}
 }
 
 func (cache *LocalCache) GetTraceSize(traceId string) int {
@@ -148,10 +235,13 @@ func (cache *LocalCache) NotifySampledTraceIds(normalTraceIds []string, slowTrac
 	}
 }
 
-func (cache *LocalCache) SubscribeReportTraceId(subscriber Subscriber) {
+func (cache *LocalCache) SubscribeReportTraceId(ctx context.Context, subscriber Subscriber) {
 	timer := time.NewTicker(1 * time.Second)
+	defer timer.Stop()
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-timer.C:
 			if len(cache.reportTraceIds) > 0 {
 				cache.mutex.Lock()
@@ -162,7 +252,6 @@ func (cache *LocalCache) SubscribeReportTraceId(subscriber Subscriber) {
 				cache.mutex.Unlock()
 			}
 		case <-cache.stopChan:
-			timer.Stop()
 			return
 		}
 	}
@@ -224,6 +313,7 @@ func (cache *LocalCache) checkExpire() {
 			cache.traceMap.Range(func(k, v interface{}) bool {
 				sentTraces := v.(*ExpirableList)
 				if sentTraces.expireTime < checkTime {
+					cache.deleteSpans(k.(string), sentTraces)
 					cache.traceMap.Delete(k)
 				}
 				return true
@@ -236,6 +326,22 @@ func (cache *LocalCache) checkExpire() {
 				return true
 			})
 
+			cache.sentSpanMap.Range(func(k, v interface{}) bool {
+				sentSpan := v.(*ExpirableData[bool])
+				if sentSpan.expireTime < checkTime {
+					cache.sentSpanMap.Delete(k)
+				}
+				return true
+			})
+
+			cache.sequenceMap.Range(func(k, v interface{}) bool {
+				sequence := v.(*ExpirableData[uint64])
+				if sequence.expireTime < checkTime {
+					cache.sequenceMap.Delete(k)
+				}
+				return true
+			})
+
 			cache.checkMissMap.Range(func(k, v interface{}) bool {
 				value := v.(*ExpirableData[int64])
 				if value.expireTime < checkTime {
@@ -243,6 +349,9 @@ func (cache *LocalCache) checkExpire() {
 				}
 				return true
 			})
+			if cache.journal != nil {
+				cache.journal.flush()
+			}
 		case <-cache.stopChan:
 			timer.Stop()
 			return
@@ -283,6 +392,41 @@ func (cache *LocalCache) GetRelationTraceId(key string) string {
 	return ""
 }
 
+func (cache *LocalCache) MarkSpanTraceSent(traceId string, apmSpanId string, expireSecond int64) bool {
+	_, loaded := cache.sentSpanMap.LoadOrStore(traceId+"-"+apmSpanId, newExpirableData(expireSecond, true))
+	return !loaded
+}
+
+func (cache *LocalCache) GetStreamSequence(agentId string) uint64 {
+	if dataInterface, ok := cache.sequenceMap.Load(agentId); ok {
+		return dataInterface.(*ExpirableData[uint64]).data
+	}
+	return 0
+}
+
+func (cache *LocalCache) SetStreamSequence(agentId string, sequence uint64, expireSecond int64) {
+	cache.sequenceMap.Store(agentId, newExpirableData(expireSecond, sequence))
+}
+
+func (cache *LocalCache) PutJournal(kind string, key string, value string) {
+	if cache.journal != nil {
+		cache.journal.put(kind, key, value)
+	}
+}
+
+func (cache *LocalCache) DeleteJournal(kind string, key string) {
+	if cache.journal != nil {
+		cache.journal.delete(kind, key)
+	}
+}
+
+func (cache *LocalCache) RecoverJournal() []*JournalEntry {
+	if cache.journal == nil {
+		return nil
+	}
+	return cache.journal.takeRecovered()
+}
+
 // SampleValue
 func (cache *LocalCache) GetSampleValue() int64 {
 	return cache.sampleValue.Load()
@@ -317,18 +461,22 @@ func newExpirableList() *ExpirableList {
 	}
 }
 
-func (list *ExpirableList) addTrace(expireTime int64, trace *model.Trace) {
+// addTrace returns the index of trace in list.
+func (list *ExpirableList) addTrace(expireTime int64, trace *model.Trace) int {
 	list.lock.Lock()
 	defer list.lock.Unlock()
 	list.expireTime = time.Now().Unix() + expireTime
 	list.traces = append(list.traces, trace)
+	return len(list.traces) - 1
 }
 
-func (list *ExpirableList) addMetric(expireTime int64, metric *model.OnOffMetricGroup) {
+// addMetric returns the index of metric in list.
+func (list *ExpirableList) addMetric(expireTime int64, metric *model.OnOffMetricGroup) int {
 	list.lock.Lock()
 	defer list.lock.Unlock()
 	list.expireTime = time.Now().Unix() + expireTime
 	list.metrics = append(list.metrics, metric)
+	return len(list.metrics) - 1
 }
 
 type ExpirableData[T any] struct {
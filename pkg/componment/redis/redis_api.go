@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/CloudDetail/apo-module/model/v1"
@@ -20,8 +21,16 @@ const (
 
 	REDIS_KEY_SENT_RELATION = "kd-sent-relation-%s"
 
+	REDIS_KEY_SENT_SPAN_TRACE = "kd-sent-span-trace-%s-%s"
+
+	REDIS_KEY_STREAM_SEQUENCE = "kd-stream-sequence-%s"
+
 	REDIS_KEY_TRACE_INDEX = "kd-traceIndex"
 
+	REDIS_KEY_JOURNAL           = "kd-journal-%s"
+	REDIS_KEY_JOURNAL_ALIVE     = "kd-journal-alive-%s"
+	REDIS_KEY_JOURNAL_RECEIVERS = "kd-journal-receivers"
+
 	REDIS_CHANNEL_NORMAL = "kd-normalChannel"
 	REDIS_CHANNEL_SLOW   = "kd-slowChannel"
 	REDIS_CHANNEL_ERROR  = "kd-errorChannel"
@@ -36,6 +45,10 @@ const (
 
 var (
 	consumerName = fmt.Sprintf("consumer-%d-%d", time.Now().UnixNano(), rand.Intn(100))
+
+	// The journal of receiver is recovered by others when it is not alive in journalAliveTime.
+	journalAliveTime       = 30 * time.Second
+	journalHeartbeatPeriod = 10 * time.Second
 )
 
 func (client *RedisClient) Start() {
@@ -43,6 +56,19 @@ func (client *RedisClient) Start() {
 	if err != nil && err.Error() != "Consumer Group name already exists" {
 		log.Printf("Error create Consume Group: %v", err)
 	}
+	client.journalGroup.Add(2)
+	go client.heartbeatJournal()
+	go client.writeJournal()
+}
+
+// Stop writes the buffered journal, and marks this receiver not alive so that its journal is recovered by others at once.
+func (client *RedisClient) Stop() {
+	close(client.stopChan)
+	client.journalGroup.Wait()
+	client.flushJournal()
+	if err := client.rdb.Del(context.Background(), fmt.Sprintf(REDIS_KEY_JOURNAL_ALIVE, consumerName)).Err(); err != nil {
+		log.Printf("[x Stop Journal] %v", err)
+	}
 }
 
 func (cache *RedisClient) IsLocal() bool {
@@ -127,9 +153,9 @@ func (client *RedisClient) NotifyReportTraceId(traceId string) {
 	client.xAddChannel(REDIS_STREAM_REPORT, traceId)
 }
 
-func (client *RedisClient) SubscribeReportTraceId(subscriber Subscriber) {
-	for {
-		client.xReadGroup(REDIS_STREAM_GROUP, consumerName, REDIS_STREAM_REPORT, subscriber)
+func (client *RedisClient) SubscribeReportTraceId(ctx context.Context, subscriber Subscriber) {
+	for ctx.Err() == nil {
+		client.xReadGroup(ctx, REDIS_STREAM_GROUP, consumerName, REDIS_STREAM_REPORT, subscriber)
 	}
 }
 
@@ -189,6 +215,149 @@ func (client *RedisClient) GetRelationTraceId(key string) string {
 	return client.get(fmt.Sprintf(REDIS_KEY_SENT_RELATION, key))
 }
 
+// ========== SpanTrace ==========
+/*
+	kd-sent-span-trace-<traceId>-<apmSpanId>, ExpireTime: expireSecond
+*/
+func (client *RedisClient) MarkSpanTraceSent(traceId string, apmSpanId string, expireSecond int64) bool {
+	key := fmt.Sprintf(REDIS_KEY_SENT_SPAN_TRACE, traceId, apmSpanId)
+	success, err := client.rdb.SetNX(context.Background(), key, 1, time.Duration(expireSecond)*time.Second).Result()
+	if err != nil {
+		// Store the span trace rather than lose it.
+		log.Printf("[x Store %s] %v", key, err)
+		return true
+	}
+	return success
+}
+
+// ========== StreamSequence ==========
+/*
+	kd-stream-sequence-<agentId>, ExpireTime: expireSecond
+*/
+func (client *RedisClient) GetStreamSequence(agentId string) uint64 {
+	return uint64(client.getInt(fmt.Sprintf(REDIS_KEY_STREAM_SEQUENCE, agentId)))
+}
+
+func (client *RedisClient) SetStreamSequence(agentId string, sequence uint64, expireSecond int64) {
+	client.setIntWithExpireTime(fmt.Sprintf(REDIS_KEY_STREAM_SEQUENCE, agentId), int64(sequence), expireSecond)
+}
+
+// ========== Journal ==========
+/*
+	kd-journal-<consumerName>, Hash <kind:key, value>
+	kd-journal-alive-<consumerName>, ExpireTime: 30s
+	kd-journal-receivers, Set <consumerName>
+*/
+func (client *RedisClient) PutJournal(kind string, key string, value string) {
+	client.journal.add(&journalOp{Op: journalOpPut, JournalEntry: JournalEntry{Kind: kind, Key: key, Value: value}})
+}
+
+func (client *RedisClient) DeleteJournal(kind string, key string) {
+	client.journal.add(&journalOp{Op: journalOpDelete, JournalEntry: JournalEntry{Kind: kind, Key: key}})
+}
+
+func (client *RedisClient) IsJournalKept() bool {
+	return true
+}
+
+// flushJournal writes the buffered ops in one pipeline.
+func (client *RedisClient) flushJournal() {
+	ops := client.journal.take()
+	if len(ops) == 0 {
+		return
+	}
+	puts := make([]interface{}, 0)
+	deletes := make([]string, 0)
+	for _, op := range ops {
+		if op.Op == journalOpPut {
+			puts = append(puts, journalKey(op.Kind, op.Key), op.Value)
+		} else {
+			deletes = append(deletes, journalKey(op.Kind, op.Key))
+		}
+	}
+	ctx := context.Background()
+	journalName := fmt.Sprintf(REDIS_KEY_JOURNAL, consumerName)
+	pipe := client.rdb.Pipeline()
+	if len(puts) > 0 {
+		pipe.HSet(ctx, journalName, puts...)
+	}
+	if len(deletes) > 0 {
+		pipe.HDel(ctx, journalName, deletes...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[x Write Journal] %v", err)
+	}
+}
+
+func (client *RedisClient) writeJournal() {
+	defer client.journalGroup.Done()
+	timer := time.NewTicker(journalFlushPeriod)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			client.flushJournal()
+		case <-client.stopChan:
+			return
+		}
+	}
+}
+
+// RecoverJournal takes the journals of receivers not alive, the journal is renamed first so that it is recovered only once.
+func (client *RedisClient) RecoverJournal() []*JournalEntry {
+	ctx := context.Background()
+	receivers, err := client.rdb.SMembers(ctx, REDIS_KEY_JOURNAL_RECEIVERS).Result()
+	if err != nil {
+		log.Printf("[x Recover Journal] %v", err)
+		return nil
+	}
+	entries := make([]*JournalEntry, 0)
+	for _, receiver := range receivers {
+		if receiver == consumerName || client.has(fmt.Sprintf(REDIS_KEY_JOURNAL_ALIVE, receiver)) {
+			continue
+		}
+		recoverKey := fmt.Sprintf(REDIS_KEY_JOURNAL, consumerName+"-recover-"+receiver)
+		if err := client.rdb.Rename(ctx, fmt.Sprintf(REDIS_KEY_JOURNAL, receiver), recoverKey).Err(); err != nil {
+			// No journal left or already recovered by others.
+			client.rdb.SRem(ctx, REDIS_KEY_JOURNAL_RECEIVERS, receiver)
+			continue
+		}
+		values, err := client.rdb.HGetAll(ctx, recoverKey).Result()
+		if err != nil {
+			log.Printf("[x Recover Journal] %v", err)
+			continue
+		}
+		for field, value := range values {
+			kind, key, found := strings.Cut(field, ":")
+			if found {
+				entries = append(entries, &JournalEntry{Kind: kind, Key: key, Value: value})
+			}
+		}
+		client.rdb.Del(ctx, recoverKey)
+		client.rdb.SRem(ctx, REDIS_KEY_JOURNAL_RECEIVERS, receiver)
+	}
+	return entries
+}
+
+func (client *RedisClient) heartbeatJournal() {
+	defer client.journalGroup.Done()
+	timer := time.NewTicker(journalHeartbeatPeriod)
+	defer timer.Stop()
+	for {
+		ctx := context.Background()
+		if err := client.rdb.SAdd(ctx, REDIS_KEY_JOURNAL_RECEIVERS, consumerName).Err(); err != nil {
+			log.Printf("[x Heartbeat Journal] %v", err)
+		} else {
+			client.rdb.Set(ctx, fmt.Sprintf(REDIS_KEY_JOURNAL_ALIVE, consumerName), 1, journalAliveTime)
+		}
+		select {
+		case <-timer.C:
+		case <-client.stopChan:
+			return
+		}
+	}
+}
+
 // SampleValue
 func (client *RedisClient) GetSampleValue() int64 {
 	return client.getInt(REDIS_KEY_SAMPLE)
@@ -5,14 +5,18 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
 type RedisClient struct {
-	rdb        *redis.Client
-	expireTime time.Duration
+	rdb          *redis.Client
+	expireTime   time.Duration
+	journal      *journalBuffer
+	stopChan     chan bool
+	journalGroup sync.WaitGroup
 }
 
 func NewRedisClient(address string, password string, expireTime int64) (*RedisClient, error) {
@@ -31,6 +35,8 @@ func NewRedisClient(address string, password string, expireTime int64) (*RedisCl
 	return &RedisClient{
 		rdb:        rdb,
 		expireTime: time.Duration(expireTime * 1000000000),
+		journal:    newJournalBuffer(),
+		stopChan:   make(chan bool),
 	}, nil
 }
 
@@ -155,15 +161,19 @@ func (client *RedisClient) xAddChannel(streamName string, message string) error
 	}).Err()
 }
 
-func (client *RedisClient) xReadGroup(groupName string, consumerName string, streamName string, subscriber Subscriber) error {
-	messages, err := client.rdb.XReadGroup(context.Background(), &redis.XReadGroupArgs{
+// xReadGroup blocks for one second at most, so that the subscription is able to stop.
+func (client *RedisClient) xReadGroup(ctx context.Context, groupName string, consumerName string, streamName string, subscriber Subscriber) error {
+	messages, err := client.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
 		Group:    groupName,
 		Consumer: consumerName,
 		Streams:  []string{streamName, ">"},
-		Block:    0,
+		Block:    time.Second,
 		Count:    1,
 		NoAck:    false,
 	}).Result()
+	if err == redis.Nil {
+		return nil
+	}
 	if err != nil {
 		if strings.HasSuffix(err.Error(), "connection refused") {
 			time.Sleep(time.Second)
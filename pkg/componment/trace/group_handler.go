@@ -0,0 +1,156 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/deadletter"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+const (
+	// UnknownGroupReject returns InvalidArgument to agent.
+	UnknownGroupReject = "reject"
+	// UnknownGroupDeadLetter keeps the datas as dead letters, which can be redriven after the receiver is upgraded.
+	UnknownGroupDeadLetter = "dead_letter"
+	// UnknownGroupRaw stores the datas into raw_data_group without parsing.
+	UnknownGroupRaw = "raw"
+)
+
+// groupHandler handles the json datas of one data group sent by StoreDataGroups.
+type groupHandler interface {
+	handle(ctx context.Context, datas []string)
+}
+
+// dataGroupHandler declares the stages of a data group, the nil cache / persist is skipped.
+type dataGroupHandler[T any] struct {
+	name string
+	// parse converts the json data, the invalid data is reported as dead letter.
+	parse func(data string) (T, error)
+	// cache keeps the parsed data for analyzer, the json data is reused by redis.
+	cache func(parsed T, data string)
+	// persist stores the parsed data.
+	persist func(parsed T)
+}
+
+func (handler *dataGroupHandler[T]) handle(ctx context.Context, datas []string) {
+	for _, data := range datas {
+		parsed, err := handler.parse(data)
+		if err != nil {
+			log.Printf("[x Parse %s] Error: %s", handler.name, err.Error())
+			deadletter.Report(ctx, handler.name, data, err)
+			continue
+		}
+		if handler.cache != nil {
+			handler.cache(parsed, data)
+		}
+		if handler.persist != nil {
+			handler.persist(parsed)
+		}
+	}
+}
+
+// groupHandlers dispatches the data groups by name, a new data group only needs to register its handler here.
+type groupHandlers struct {
+	handlers      map[string]groupHandler
+	unknownPolicy string
+}
+
+func newGroupHandlers(reportAnalyzer *analyzer.ReportAnalyzer, unknownPolicy string) *groupHandlers {
+	switch unknownPolicy {
+	case UnknownGroupReject, UnknownGroupDeadLetter, UnknownGroupRaw:
+	case "":
+		unknownPolicy = UnknownGroupDeadLetter
+	default:
+		log.Printf("[x Unknown Group Policy] %s, use %s.", unknownPolicy, UnknownGroupDeadLetter)
+		unknownPolicy = UnknownGroupDeadLetter
+	}
+	handlers := &groupHandlers{
+		handlers:      make(map[string]groupHandler),
+		unknownPolicy: unknownPolicy,
+	}
+
+	registerHandler(handlers, report.SpanTraceGroup, parseJson(newTrace), reportAnalyzer.CacheTrace, nil)
+	registerHandler(handlers, report.OnOffMetricGroup, parseJson(func() *grpc_model.OnOffMetricGroup { return &grpc_model.OnOffMetricGroup{} }),
+		func(onOffMetric *grpc_model.OnOffMetricGroup, data string) {
+			reportAnalyzer.CacheMetric(toOnOffMetricGroup(onOffMetric), data)
+		},
+		func(onOffMetric *grpc_model.OnOffMetricGroup) { global.SINK.StoreOnOffMetric(onOffMetric) })
+	// Same with the structure of SpanTraceGroup but lacked trace labels,
+	// also saved as SpanTraceGroup. DesignatedProfilingSignal is used for TraceProfiling.
+	registerHandler(handlers, report.DesignatedProfilingSignal, parseJson(newTrace), nil, analyzer.StoreSpanTrace)
+	registerHandler(handlers, report.OriginxAgentEvent, parseJson(func() *model.AgentEvent { return &model.AgentEvent{} }), nil, reportAnalyzer.StoreEvent)
+	registerHandler(handlers, report.OriginxAppInfo, parseJson(func() *appinfo.AppInfo { return &appinfo.AppInfo{} }), nil, reportAnalyzer.StoreAppInfo)
+	registerHandler(handlers, report.LogGroup, parseJson(func() *grpc_model.LogRecord { return &grpc_model.LogRecord{} }), nil, reportAnalyzer.StoreLog)
+	registerHandler(handlers, report.K8sEventGroup, parseJson(func() *grpc_model.K8SEvent { return &grpc_model.K8SEvent{} }), nil, reportAnalyzer.StoreK8sEvent)
+	registerHandler(handlers, report.CameraEventGroup, parseJson(func() *grpc_model.ProfilingEvent { return &grpc_model.ProfilingEvent{} }), nil,
+		func(profilingEvent *grpc_model.ProfilingEvent) { global.SINK.StoreProfilingEvent(profilingEvent) })
+	registerHandler(handlers, report.FlameGraph, parseJson(func() *grpc_model.FlameGraph { return &grpc_model.FlameGraph{} }), nil,
+		func(flameGraph *grpc_model.FlameGraph) { global.SINK.StoreFlameGraph(flameGraph) })
+	registerHandler(handlers, report.JvmGc, parseJson(func() *grpc_model.JvmGc { return &grpc_model.JvmGc{} }), nil,
+		func(jvmGc *grpc_model.JvmGc) { global.SINK.StoreJvmGc(jvmGc) })
+	return handlers
+}
+
+func registerHandler[T any](handlers *groupHandlers, name string, parse func(data string) (T, error), cache func(parsed T, data string), persist func(parsed T)) {
+	handlers.handlers[name] = &dataGroupHandler[T]{
+		name:    name,
+		parse:   parse,
+		cache:   cache,
+		persist: persist,
+	}
+}
+
+func parseJson[T any](newData func() T) func(data string) (T, error) {
+	return func(data string) (T, error) {
+		parsed := newData()
+		err := json.Unmarshal([]byte(data), parsed)
+		return parsed, err
+	}
+}
+
+func newTrace() *model.Trace {
+	return &model.Trace{Labels: &model.TraceLabels{ThresholdMultiple: 1.0}}
+}
+
+// handle returns error only when the data group is unknown and rejected.
+func (handlers *groupHandlers) handle(ctx context.Context, name string, datas []string) error {
+	if handler, found := handlers.handlers[name]; found {
+		handler.handle(ctx, datas)
+		return nil
+	}
+
+	log.Printf("[x Unknown Data] %s, %s.", name, handlers.unknownPolicy)
+	switch handlers.unknownPolicy {
+	case UnknownGroupReject:
+		return status.Errorf(codes.InvalidArgument, "unknown data group %s", name)
+	case UnknownGroupRaw:
+		source := deadletter.SourceOf(ctx)
+		timestamp := time.Now().UnixMilli()
+		for _, data := range datas {
+			global.SINK.StoreRawData(&report.RawData{
+				Timestamp: timestamp,
+				Group:     name,
+				Source:    source,
+				Data:      data,
+			})
+		}
+	default:
+		// Sent by the agent of newer version, kept until the receiver is upgraded.
+		err := status.Errorf(codes.InvalidArgument, "unknown data group %s", name)
+		for _, data := range datas {
+			deadletter.Report(ctx, name, data, err)
+		}
+	}
+	return nil
+}
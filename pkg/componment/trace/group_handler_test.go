@@ -0,0 +1,165 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/deadletter"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
+)
+
+// memorySink keeps the stored datas, the other methods are not expected to be called.
+type memorySink struct {
+	sink.Sink
+	flameGraphs     []*grpc_model.FlameGraph
+	profilingEvents []*grpc_model.ProfilingEvent
+	jvmGcs          []*grpc_model.JvmGc
+	logs            []*grpc_model.LogRecord
+	k8sEvents       []*grpc_model.LogRecord
+	rawDatas        []*report.RawData
+}
+
+func (s *memorySink) StoreFlameGraph(flameGraph *grpc_model.FlameGraph) {
+	s.flameGraphs = append(s.flameGraphs, flameGraph)
+}
+
+func (s *memorySink) StoreProfilingEvent(profilingEvent *grpc_model.ProfilingEvent) {
+	s.profilingEvents = append(s.profilingEvents, profilingEvent)
+}
+
+func (s *memorySink) StoreJvmGc(jvmGc *grpc_model.JvmGc) {
+	s.jvmGcs = append(s.jvmGcs, jvmGc)
+}
+
+func (s *memorySink) StoreLog(logRecord *grpc_model.LogRecord) {
+	s.logs = append(s.logs, logRecord)
+}
+
+func (s *memorySink) StoreK8sEvent(k8sEvent *grpc_model.LogRecord) {
+	s.k8sEvents = append(s.k8sEvents, k8sEvent)
+}
+
+func (s *memorySink) StoreRawData(rawData *report.RawData) {
+	s.rawDatas = append(s.rawDatas, rawData)
+}
+
+func setupGroupHandlers(t *testing.T) *memorySink {
+	memory := &memorySink{}
+	oldSink, oldStore := global.SINK, deadletter.StoreInstance
+	global.SINK = memory
+	deadletter.StoreInstance, _ = deadletter.NewStore(&config.DeadLetterConfig{})
+	t.Cleanup(func() {
+		global.SINK, deadletter.StoreInstance = oldSink, oldStore
+	})
+	return memory
+}
+
+func TestGroupHandlersParseJson(t *testing.T) {
+	memory := setupGroupHandlers(t)
+	handlers := newGroupHandlers(nil, "")
+	ctx := context.Background()
+
+	assert.NoError(t, handlers.handle(ctx, report.FlameGraph, []string{
+		`{"pid":1,"tid":2,"container_id":"abc","ns_pid":3,"sample_type":"cpu","sample_rate":99,"flamebearer":"fb","start_time":10,"end_time":20}`,
+		`invalid`,
+	}))
+	if assert.Len(t, memory.flameGraphs, 1) {
+		assert.Equal(t, uint32(1), memory.flameGraphs[0].Pid)
+		assert.Equal(t, int32(3), memory.flameGraphs[0].NsPid)
+		assert.Equal(t, "fb", memory.flameGraphs[0].Flamebearer)
+		assert.Equal(t, uint64(20), memory.flameGraphs[0].EndTime)
+	}
+	records := deadletter.StoreInstance.Query(&deadletter.Filter{}, 0)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, report.FlameGraph, records[0].Group)
+		assert.Equal(t, "invalid", records[0].Data)
+	}
+
+	assert.NoError(t, handlers.handle(ctx, report.CameraEventGroup, []string{
+		`{"name":"camera_event_group","timestamp":1,"data_version":"v1","labels":{"cpuEvents":"ce","container_id":"abc","pid":1,"startTime":10,"threadName":"main","offset_ts":-5}}`,
+	}))
+	if assert.Len(t, memory.profilingEvents, 1) {
+		event := memory.profilingEvents[0]
+		assert.Equal(t, "v1", event.DataVersion)
+		assert.Equal(t, "ce", event.Labels.CpuEvents)
+		assert.Equal(t, "main", event.Labels.ThreadName)
+		assert.Equal(t, int64(-5), event.Labels.OffsetTs)
+	}
+
+	assert.NoError(t, handlers.handle(ctx, report.JvmGc, []string{
+		`{"pid":"1","node_name":"node","node_ip":"10.0.0.1","ygc":2,"fgc_span":3,"timestamp":4}`,
+	}))
+	if assert.Len(t, memory.jvmGcs, 1) {
+		assert.Equal(t, "1", memory.jvmGcs[0].Pid)
+		assert.Equal(t, int64(2), memory.jvmGcs[0].Ygc)
+		assert.Equal(t, int64(3), memory.jvmGcs[0].FgcSpan)
+	}
+}
+
+func TestGroupHandlersLog(t *testing.T) {
+	memory := setupGroupHandlers(t)
+	handlers := newGroupHandlers(nil, "")
+
+	assert.NoError(t, handlers.handle(context.Background(), report.LogGroup, []string{
+		`{"timestamp":10,"severity_text":"ERROR","body":"timeout","resource_attributes":{"service.name":"cart"},"log_attributes":{"traceId":"t","span_id":"s"}}`,
+	}))
+	if assert.Len(t, memory.logs, 1) {
+		logRecord := memory.logs[0]
+		assert.Equal(t, uint64(10), logRecord.Timestamp)
+		assert.Equal(t, "cart", logRecord.ServiceName)
+		assert.Equal(t, "t", logRecord.TraceId)
+		assert.Equal(t, "s", logRecord.SpanId)
+		assert.Equal(t, "timeout", logRecord.Body)
+	}
+}
+
+func TestGroupHandlersK8sEvent(t *testing.T) {
+	memory := setupGroupHandlers(t)
+	handlers := newGroupHandlers(nil, "")
+
+	assert.NoError(t, handlers.handle(context.Background(), report.K8sEventGroup, []string{
+		`{"timestamp":10,"type":"Warning","reason":"OOMKilling","message":"Memory cgroup out of memory","count":2,"object_kind":"Pod","object_namespace":"default","object_name":"cart-0","source_host":"node-1"}`,
+	}))
+	if assert.Len(t, memory.k8sEvents, 1) {
+		k8sEvent := memory.k8sEvents[0]
+		assert.Equal(t, uint64(10), k8sEvent.Timestamp)
+		assert.Equal(t, "Warning", k8sEvent.SeverityText)
+		assert.Equal(t, int32(13), k8sEvent.SeverityNumber)
+		assert.Equal(t, "Memory cgroup out of memory", k8sEvent.Body)
+		assert.Equal(t, "cart-0", k8sEvent.ResourceAttributes["k8s.pod.name"])
+		assert.Equal(t, "default", k8sEvent.ResourceAttributes["k8s.namespace.name"])
+		assert.Equal(t, "node-1", k8sEvent.ResourceAttributes["k8s.node.name"])
+		assert.Equal(t, "OOMKilling", k8sEvent.LogAttributes["k8s.event.reason"])
+		assert.Equal(t, "2", k8sEvent.LogAttributes["k8s.event.count"])
+	}
+}
+
+func TestGroupHandlersUnknownPolicy(t *testing.T) {
+	memory := setupGroupHandlers(t)
+	ctx := deadletter.WithSource(context.Background(), "10.0.0.1")
+	datas := []string{`{"id":1}`}
+
+	err := newGroupHandlers(nil, UnknownGroupReject).handle(ctx, "new_group", datas)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, newGroupHandlers(nil, UnknownGroupDeadLetter).handle(ctx, "new_group", datas))
+	records := deadletter.StoreInstance.Query(&deadletter.Filter{Group: "new_group"}, 0)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "10.0.0.1", records[0].Source)
+	}
+
+	assert.NoError(t, newGroupHandlers(nil, UnknownGroupRaw).handle(ctx, "new_group", datas))
+	if assert.Len(t, memory.rawDatas, 1) {
+		assert.Equal(t, "new_group", memory.rawDatas[0].Group)
+		assert.Equal(t, "10.0.0.1", memory.rawDatas[0].Source)
+		assert.Equal(t, `{"id":1}`, memory.rawDatas[0].Data)
+	}
+}
@@ -0,0 +1,195 @@
+package trace
+
+import (
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/threshold"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+const (
+	otlpDataSource  = "otlp"
+	otlpDataVersion = "v1"
+)
+
+// otlpConverter maps the OTLP entry spans into span traces.
+type otlpConverter struct {
+	apmType string
+	// defaultSlowThreshold is used when no threshold is found, Nanosecond.
+	defaultSlowThreshold float64
+	getSlowThreshold     func(contentKey string) *grpc_model.SlowThresholdData
+}
+
+// convert returns the span traces of the entry spans, eg. SERVER / CONSUMER spans and the root spans,
+// the other spans are only kept by the apm backend.
+func (c *otlpConverter) convert(td ptrace.Traces) []*model.Trace {
+	traces := make([]*model.Trace, 0)
+	resourceSpans := td.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		resourceSpan := resourceSpans.At(i)
+		resource := resourceSpan.Resource().Attributes()
+		scopeSpans := resourceSpan.ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				if !isEntrySpan(span) {
+					continue
+				}
+				traces = append(traces, c.convertSpan(resource, span))
+			}
+		}
+	}
+	return traces
+}
+
+func isEntrySpan(span ptrace.Span) bool {
+	switch span.Kind() {
+	case ptrace.SpanKindServer, ptrace.SpanKindConsumer:
+		return true
+	default:
+		return span.ParentSpanID().IsEmpty()
+	}
+}
+
+func (c *otlpConverter) convertSpan(resource pcommon.Map, span ptrace.Span) *model.Trace {
+	attributes := span.Attributes()
+	startTime := uint64(span.StartTimestamp())
+	endTime := uint64(span.EndTimestamp())
+	duration := uint64(0)
+	if endTime > startTime {
+		duration = endTime - startTime
+	}
+
+	labels := &model.TraceLabels{
+		Pid:               uint32(getIntAttr(resource, "process.pid")),
+		TopSpan:           span.ParentSpanID().IsEmpty(),
+		Protocol:          getProtocol(attributes),
+		ServiceName:       getStringAttr(resource, "service.name"),
+		Url:               span.Name(),
+		HttpUrl:           getStringAttr(attributes, "url.full", "http.url", "url.path", "http.target"),
+		IsServer:          span.Kind() == ptrace.SpanKindServer || span.Kind() == ptrace.SpanKindConsumer,
+		IsError:           isErrorSpan(span),
+		ThresholdMultiple: 1.0,
+		TraceId:           span.TraceID().String(),
+		ApmType:           c.apmType,
+		ApmSpanId:         span.SpanID().String(),
+		ContainerId:       getStringAttr(resource, "container.id"),
+		ContainerName:     getStringAttr(resource, "container.name", "k8s.container.name"),
+		StartTime:         startTime,
+		Duration:          duration,
+		EndTime:           endTime,
+		NodeName:          getStringAttr(resource, "k8s.node.name", "host.name"),
+		NodeIp:            getStringAttr(resource, "host.ip"),
+		ClusterID:         getStringAttr(resource, "k8s.cluster.name"),
+	}
+	c.fillSlow(labels)
+
+	return &model.Trace{
+		Name:         report.SpanTraceGroup,
+		Timestamp:    startTime,
+		Version:      otlpDataVersion,
+		Source:       otlpDataSource,
+		Labels:       labels,
+		WorkloadName: getStringAttr(resource, "k8s.deployment.name", "k8s.statefulset.name", "k8s.daemonset.name"),
+		WorkloadKind: getWorkloadKind(resource),
+		PodIp:        getStringAttr(resource, "k8s.pod.ip"),
+		PodName:      getStringAttr(resource, "k8s.pod.name"),
+		Namespace:    getStringAttr(resource, "k8s.namespace.name"),
+	}
+}
+
+// fillSlow compares the duration with the slow threshold of content key, which is also used by agents.
+func (c *otlpConverter) fillSlow(labels *model.TraceLabels) {
+	var slowThreshold *grpc_model.SlowThresholdData
+	if c.getSlowThreshold != nil {
+		slowThreshold = c.getSlowThreshold(labels.Url)
+	}
+	if slowThreshold == nil {
+		labels.ThresholdType = model.ThresholdType(threshold.P90)
+		labels.ThresholdRange = model.ThresholdRange(threshold.Default)
+		labels.ThresholdValue = c.defaultSlowThreshold
+	} else {
+		labels.ThresholdType = model.ThresholdType(slowThreshold.Type)
+		labels.ThresholdRange = model.ThresholdRange(slowThreshold.Range)
+		labels.ThresholdValue = slowThreshold.Value
+		if slowThreshold.Multiple > 0 {
+			labels.ThresholdMultiple = slowThreshold.Multiple
+		}
+	}
+	labels.IsSlow = float64(labels.Duration) > labels.ThresholdValue
+}
+
+func isErrorSpan(span ptrace.Span) bool {
+	if span.Status().Code() == ptrace.StatusCodeError {
+		return true
+	}
+	statusCode := getIntAttr(span.Attributes(), "http.response.status_code", "http.status_code")
+	return statusCode >= 500
+}
+
+func getProtocol(attributes pcommon.Map) string {
+	if system := getStringAttr(attributes, "rpc.system", "messaging.system", "db.system"); system != "" {
+		return system
+	}
+	if getStringAttr(attributes, "http.request.method", "http.method") != "" {
+		return "http"
+	}
+	return ""
+}
+
+func getWorkloadKind(resource pcommon.Map) string {
+	if _, ok := resource.Get("k8s.deployment.name"); ok {
+		return "Deployment"
+	}
+	if _, ok := resource.Get("k8s.statefulset.name"); ok {
+		return "StatefulSet"
+	}
+	if _, ok := resource.Get("k8s.daemonset.name"); ok {
+		return "DaemonSet"
+	}
+	return ""
+}
+
+// getStringAttr returns the first found value of keys, the first element is used for slice.
+func getStringAttr(attributes pcommon.Map, keys ...string) string {
+	for _, key := range keys {
+		value, ok := attributes.Get(key)
+		if !ok {
+			continue
+		}
+		if value.Type() == pcommon.ValueTypeSlice {
+			if value.Slice().Len() == 0 {
+				continue
+			}
+			value = value.Slice().At(0)
+		}
+		if str := value.AsString(); str != "" {
+			return str
+		}
+	}
+	return ""
+}
+
+func getIntAttr(attributes pcommon.Map, keys ...string) int64 {
+	for _, key := range keys {
+		value, ok := attributes.Get(key)
+		if !ok {
+			continue
+		}
+		switch value.Type() {
+		case pcommon.ValueTypeInt:
+			return value.Int()
+		case pcommon.ValueTypeStr:
+			if result, err := strconv.ParseInt(value.Str(), 10, 64); err == nil {
+				return result
+			}
+		}
+	}
+	return 0
+}
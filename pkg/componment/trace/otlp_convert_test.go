@@ -0,0 +1,93 @@
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+func TestOtlpConvert(t *testing.T) {
+	td := ptrace.NewTraces()
+	resourceSpan := td.ResourceSpans().AppendEmpty()
+	resource := resourceSpan.Resource().Attributes()
+	resource.PutStr("service.name", "cart")
+	resource.PutInt("process.pid", 123)
+	resource.PutStr("container.id", "abc")
+	resource.PutStr("k8s.pod.name", "cart-0")
+	resource.PutStr("k8s.deployment.name", "cart")
+	spans := resourceSpan.ScopeSpans().AppendEmpty().Spans()
+
+	start := time.Unix(100, 0)
+	traceId := pcommon.TraceID([16]byte{1, 2, 3})
+	root := spans.AppendEmpty()
+	root.SetName("GET /cart")
+	root.SetKind(ptrace.SpanKindServer)
+	root.SetTraceID(traceId)
+	root.SetSpanID(pcommon.SpanID([8]byte{1}))
+	root.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	root.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(2 * time.Second)))
+	root.Attributes().PutStr("http.request.method", "GET")
+	root.Attributes().PutInt("http.response.status_code", 503)
+
+	client := spans.AppendEmpty()
+	client.SetName("SELECT cart")
+	client.SetKind(ptrace.SpanKindClient)
+	client.SetTraceID(traceId)
+	client.SetSpanID(pcommon.SpanID([8]byte{2}))
+	client.SetParentSpanID(pcommon.SpanID([8]byte{1}))
+
+	consumer := spans.AppendEmpty()
+	consumer.SetName("orders process")
+	consumer.SetKind(ptrace.SpanKindConsumer)
+	consumer.SetTraceID(traceId)
+	consumer.SetSpanID(pcommon.SpanID([8]byte{3}))
+	consumer.SetParentSpanID(pcommon.SpanID([8]byte{2}))
+	consumer.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	consumer.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(200 * time.Millisecond)))
+	consumer.Attributes().PutStr("messaging.system", "kafka")
+
+	converter := &otlpConverter{
+		apmType:              "otel",
+		defaultSlowThreshold: float64(time.Second),
+		getSlowThreshold: func(contentKey string) *grpc_model.SlowThresholdData {
+			if contentKey == "orders process" {
+				return &grpc_model.SlowThresholdData{Url: contentKey, Value: float64(100 * time.Millisecond), Type: "LatencyP90", Range: "last1h", Multiple: 1.1}
+			}
+			return nil
+		},
+	}
+	traces := converter.convert(td)
+	if len(traces) != 2 {
+		t.Fatalf("want 2 entry spans, got %d", len(traces))
+	}
+
+	top := traces[0]
+	labels := top.Labels
+	if !labels.TopSpan || !labels.IsServer || !labels.IsError || !labels.IsSlow || labels.Protocol != "http" {
+		t.Errorf("unexpected flags of root span: %+v", labels)
+	}
+	if labels.TraceId != traceId.String() || labels.ApmSpanId != "0100000000000000" || labels.ApmType != "otel" {
+		t.Errorf("unexpected ids of root span: %+v", labels)
+	}
+	if labels.ServiceName != "cart" || labels.Url != "GET /cart" || labels.Pid != 123 || labels.ContainerId != "abc" {
+		t.Errorf("unexpected resource of root span: %+v", labels)
+	}
+	if labels.Duration != uint64(2*time.Second) || labels.StartTime != uint64(start.UnixNano()) || top.Timestamp != labels.StartTime {
+		t.Errorf("unexpected time of root span: %+v", labels)
+	}
+	if top.PodName != "cart-0" || top.WorkloadName != "cart" || top.WorkloadKind != "Deployment" {
+		t.Errorf("unexpected k8s metadata of root span: %+v", top)
+	}
+
+	labels = traces[1].Labels
+	if labels.TopSpan || labels.IsError || !labels.IsSlow || labels.Protocol != "kafka" {
+		t.Errorf("unexpected flags of consumer span: %+v", labels)
+	}
+	if string(labels.ThresholdRange) != "last1h" || labels.ThresholdMultiple != 1.1 {
+		t.Errorf("threshold of content key is not used: %+v", labels)
+	}
+}
@@ -0,0 +1,127 @@
+package trace
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/threshold"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+const (
+	otlpTraceType = "otlp_traces"
+
+	defaultOtlpApmType         = "otel"
+	defaultOtlpSlowThresholdMs = 1000
+	contentTypeProtobuf        = "application/x-protobuf"
+	contentTypeJson            = "application/json"
+	maxOtlpRequestBodyBytes    = 32 * 1024 * 1024
+)
+
+// OtlpTraceServer receives the traces exported by OTel SDKs or collectors by OTLP/gRPC and OTLP/HTTP,
+// the entry spans are analyzed as the span traces sent by agent.
+type OtlpTraceServer struct {
+	ptraceotlp.UnimplementedGRPCServer
+	analyzer  *analyzer.ReportAnalyzer
+	converter *otlpConverter
+}
+
+func NewOtlpTraceServer(cfg *config.OtlpConfig, analyzer *analyzer.ReportAnalyzer, thresholdCache *threshold.ThresholdCache) *OtlpTraceServer {
+	apmType := cfg.ApmType
+	if apmType == "" {
+		apmType = defaultOtlpApmType
+	}
+	slowThresholdMs := cfg.DefaultSlowThresholdMs
+	if slowThresholdMs <= 0 {
+		slowThresholdMs = defaultOtlpSlowThresholdMs
+	}
+	converter := &otlpConverter{
+		apmType:              apmType,
+		defaultSlowThreshold: float64(slowThresholdMs) * 1e6,
+	}
+	if thresholdCache != nil {
+		converter.getSlowThreshold = thresholdCache.GetSlowThreshold
+	}
+	return &OtlpTraceServer{
+		analyzer:  analyzer,
+		converter: converter,
+	}
+}
+
+func (server *OtlpTraceServer) Export(_ context.Context, request ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	server.consume(request)
+	return ptraceotlp.NewExportResponse(), nil
+}
+
+func (server *OtlpTraceServer) consume(request ptraceotlp.ExportRequest) {
+	traces := server.converter.convert(request.Traces())
+	for _, trace := range traces {
+		server.analyzer.CacheTrace(trace, "")
+	}
+	if len(traces) > 0 {
+		ReceiveMessageTotal.WithLabelValues(otlpTraceType).Inc()
+	}
+}
+
+// ServeHTTP handles POST /v1/traces, both binary protobuf and json are supported.
+func (server *OtlpTraceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType != contentTypeProtobuf && contentType != contentTypeJson {
+		http.Error(w, "unsupported content type: "+contentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxOtlpRequestBodyBytes)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	request := ptraceotlp.NewExportRequest()
+	if contentType == contentTypeProtobuf {
+		err = request.UnmarshalProto(content)
+	} else {
+		err = request.UnmarshalJSON(content)
+	}
+	if err != nil {
+		log.Printf("[x Parse OTLP Traces] Error: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	server.consume(request)
+
+	response := ptraceotlp.NewExportResponse()
+	var responseContent []byte
+	if contentType == contentTypeProtobuf {
+		responseContent, err = response.MarshalProto()
+	} else {
+		responseContent, err = response.MarshalJSON()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(responseContent)
+}
@@ -0,0 +1,280 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/CloudDetail/apo-receiver/pkg/componment/admission"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/deadletter"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/redis"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
+)
+
+const (
+	// streamSequenceExpire removes the acked sequence of agent not sending for a long time, eg. restarted with new agent id.
+	streamSequenceExpire = time.Hour
+	// streamDrainTimeout is how long the closed stream waits for the accepted batches to be stored before the last ack.
+	streamDrainTimeout = 10 * time.Second
+	streamDrainPeriod  = 100 * time.Millisecond
+)
+
+type ackedSequence struct {
+	sequence   uint64
+	updateTime time.Time
+}
+
+// streamSequences keeps the acked sequence of agents, so that the batches resent after reconnect are skipped.
+// The sequences are kept in cache if set, which is shared by receivers when the agent reconnects to another one.
+type streamSequences struct {
+	cache redis.ExpirableCache
+	lock  sync.Mutex
+	acked map[string]*ackedSequence
+}
+
+func newStreamSequences(cache redis.ExpirableCache) *streamSequences {
+	return &streamSequences{
+		cache: cache,
+		acked: make(map[string]*ackedSequence),
+	}
+}
+
+// get returns the acked sequence of agent and removes the expired ones.
+func (sequences *streamSequences) get(agentId string) uint64 {
+	if sequences.cache != nil {
+		return sequences.cache.GetStreamSequence(agentId)
+	}
+	sequences.lock.Lock()
+	defer sequences.lock.Unlock()
+
+	now := time.Now()
+	for id, acked := range sequences.acked {
+		if now.Sub(acked.updateTime) > streamSequenceExpire {
+			delete(sequences.acked, id)
+		}
+	}
+	if acked, found := sequences.acked[agentId]; found {
+		return acked.sequence
+	}
+	return 0
+}
+
+func (sequences *streamSequences) set(agentId string, sequence uint64) {
+	if sequences.cache != nil {
+		sequences.cache.SetStreamSequence(agentId, sequence, int64(streamSequenceExpire.Seconds()))
+		return
+	}
+	sequences.lock.Lock()
+	defer sequences.lock.Unlock()
+
+	if acked, found := sequences.acked[agentId]; found && acked.sequence >= sequence {
+		acked.updateTime = time.Now()
+		return
+	}
+	sequences.acked[agentId] = &ackedSequence{sequence: sequence, updateTime: time.Now()}
+}
+
+// acceptedBatch is the batch handled but not acked, it is acked after the sink writes the datas stored before acceptTime.
+type acceptedBatch struct {
+	sequence   uint64
+	acceptTime time.Time
+}
+
+// dataGroupsStream is the state of one StreamDataGroups stream.
+type dataGroupsStream struct {
+	server  *TraceServer
+	stream  grpc_model.TraceService_StreamDataGroupsServer
+	source  string
+	agentId string
+
+	// lock protects the state below and the Send of stream.
+	lock sync.Mutex
+	// accepted is the last handled sequence, acked is the last one whose datas are written by sink.
+	accepted  uint64
+	acked     uint64
+	sentAcked uint64
+	pendings  []acceptedBatch
+	// unacked is the count of batches received since the last ack.
+	unacked int
+	// resent means the batches acked before are received again, which are acked again.
+	resent bool
+	// rejected is the sequence rejected by admission, the later batches are skipped until it is resent.
+	rejected uint64
+}
+
+// StreamDataGroups handles the batches of agent in order, the acked sequence is sent every ackBatches or ackInterval.
+// The batch is accepted when it is handled as StoreDataGroups, and acked after its datas are written or spooled by sink,
+// the agent resends the batches not acked after reconnect.
+func (server *TraceServer) StreamDataGroups(stream grpc_model.TraceService_StreamDataGroupsServer) error {
+	ctx := stream.Context()
+	s := &dataGroupsStream{
+		server: server,
+		stream: stream,
+		source: deadletter.SourceOf(ctx),
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.ackPeriodically(ctx)
+
+	for {
+		batch, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			s.drain(ctx)
+			return s.ack(true)
+		}
+		if err != nil {
+			return err
+		}
+		if err = s.handle(ctx, batch); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *dataGroupsStream) ackPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(s.server.ackInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.ack(false); err != nil {
+				log.Printf("[x Ack Stream] Agent: %s, Error: %s", s.agentId, err.Error())
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *dataGroupsStream) handle(ctx context.Context, batch *grpc_model.DataGroupsBatch) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.agentId == "" {
+		s.agentId = batch.AgentId
+		if s.agentId == "" {
+			s.agentId = s.source
+		}
+		s.acked = s.server.sequences.get(s.agentId)
+		s.accepted, s.sentAcked = s.acked, s.acked
+	}
+	if batch.Sequence <= s.accepted {
+		// Resent after reconnect, ack it again.
+		s.resent = true
+		s.unacked++
+		return nil
+	}
+	if s.rejected > 0 && batch.Sequence != s.rejected {
+		// Sent before the agent knows the rejection, it is resent later.
+		return nil
+	}
+
+	usages := make(map[string]admission.Usage)
+	for _, dataGroups := range batch.DataGroups {
+		usage := usages[dataGroups.Name]
+		usage.Datas += len(dataGroups.Datas)
+		for _, data := range dataGroups.Datas {
+			usage.Bytes += len(data)
+		}
+		usages[dataGroups.Name] = usage
+	}
+	if err := s.server.admission.Admit(ctx, s.source, usages); err != nil {
+		retryAfter := admission.RetryAfter(err)
+		if retryAfter == 0 {
+			// Never accepted even if resent, drop it.
+			s.accept(batch.Sequence, time.Time{})
+			s.advance()
+			s.unacked, s.sentAcked = 0, s.acked
+			return s.stream.Send(&grpc_model.DataGroupsAck{AckedSequence: s.acked, Error: err.Error()})
+		}
+		s.rejected = batch.Sequence
+		s.advance()
+		s.sentAcked = s.acked
+		return s.stream.Send(&grpc_model.DataGroupsAck{
+			AckedSequence:     s.acked,
+			RejectedSequence:  batch.Sequence,
+			Error:             err.Error(),
+			RetryAfterSeconds: int32(math.Ceil(retryAfter.Seconds())),
+		})
+	}
+
+	for _, dataGroups := range batch.DataGroups {
+		if err := s.server.storeDataGroups(ctx, dataGroups.Name, dataGroups.Datas); err != nil {
+			// Not retryable, eg. unknown data group is rejected.
+			log.Printf("[x Stream DataGroups] Agent: %s, Sequence: %d, Error: %s", s.agentId, batch.Sequence, err.Error())
+		}
+	}
+	// Stored into the sink before acceptTime.
+	s.accept(batch.Sequence, time.Now())
+	if s.unacked >= s.server.ackBatches {
+		s.unacked = 0
+		return s.sendAck(false)
+	}
+	return nil
+}
+
+func (s *dataGroupsStream) accept(sequence uint64, acceptTime time.Time) {
+	s.rejected = 0
+	s.accepted = sequence
+	s.pendings = append(s.pendings, acceptedBatch{sequence: sequence, acceptTime: acceptTime})
+	s.unacked++
+}
+
+// advance acks the accepted batches whose datas are written by sink, the acked sequence is shared by receivers.
+func (s *dataGroupsStream) advance() {
+	syncedTime := sink.SyncedTime(global.SINK)
+	acked := 0
+	for acked < len(s.pendings) && s.pendings[acked].acceptTime.Before(syncedTime) {
+		acked++
+	}
+	if acked == 0 {
+		return
+	}
+	s.acked = s.pendings[acked-1].sequence
+	s.pendings = s.pendings[acked:]
+	s.server.sequences.set(s.agentId, s.acked)
+}
+
+// drain waits for the accepted batches to be acked within streamDrainTimeout.
+func (s *dataGroupsStream) drain(ctx context.Context) {
+	timer := time.NewTicker(streamDrainPeriod)
+	defer timer.Stop()
+	deadline := time.Now().Add(streamDrainTimeout)
+	for {
+		s.lock.Lock()
+		s.advance()
+		drained := len(s.pendings) == 0
+		s.lock.Unlock()
+		if drained || time.Now().After(deadline) {
+			return
+		}
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ack sends the acked sequence if it is changed or the acked batches are resent, or force is set.
+func (s *dataGroupsStream) ack(force bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sendAck(force)
+}
+
+func (s *dataGroupsStream) sendAck(force bool) error {
+	s.advance()
+	if !force && !s.resent && s.acked == s.sentAcked {
+		return nil
+	}
+	s.unacked, s.resent, s.sentAcked = 0, false, s.acked
+	return s.stream.Send(&grpc_model.DataGroupsAck{AckedSequence: s.acked})
+}
@@ -0,0 +1,125 @@
+package trace
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/admission"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+// fakeStream sends the batches in order and keeps the acks.
+type fakeStream struct {
+	grpc.ServerStream
+	batches []*grpc_model.DataGroupsBatch
+	acks    []*grpc_model.DataGroupsAck
+}
+
+func (s *fakeStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeStream) Recv() (*grpc_model.DataGroupsBatch, error) {
+	if len(s.batches) == 0 {
+		return nil, io.EOF
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	return batch, nil
+}
+
+func (s *fakeStream) Send(ack *grpc_model.DataGroupsAck) error {
+	s.acks = append(s.acks, ack)
+	return nil
+}
+
+func newJvmGcBatch(sequence uint64, pids ...string) *grpc_model.DataGroupsBatch {
+	datas := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		datas = append(datas, `{"pid":"`+pid+`"}`)
+	}
+	return &grpc_model.DataGroupsBatch{
+		AgentId:    "agent-1",
+		Sequence:   sequence,
+		DataGroups: []*grpc_model.DataGroups{{Name: report.JvmGc, Datas: datas}},
+	}
+}
+
+func TestStreamDataGroups(t *testing.T) {
+	memory := setupGroupHandlers(t)
+	server := NewTraceServer(nil, &config.ReceiverConfig{StreamAckBatches: 2}, admission.NewController(&config.AdmissionConfig{
+		Enable:    true,
+		NodeLimit: &config.AdmissionLimit{MaxBytes: 30},
+	}))
+
+	stream := &fakeStream{batches: []*grpc_model.DataGroupsBatch{
+		newJvmGcBatch(1, "1"),
+		newJvmGcBatch(2, "2"),
+		newJvmGcBatch(3, "3", "4", "5"),
+		newJvmGcBatch(4, "6"),
+	}}
+	assert.NoError(t, server.StreamDataGroups(stream))
+	if assert.Len(t, stream.acks, 3) {
+		assert.Equal(t, uint64(2), stream.acks[0].AckedSequence)
+		// Batch 3 exceeds max bytes and is dropped.
+		assert.Equal(t, uint64(3), stream.acks[1].AckedSequence)
+		assert.NotEmpty(t, stream.acks[1].Error)
+		assert.Equal(t, uint64(4), stream.acks[2].AckedSequence)
+	}
+	assert.Len(t, memory.jvmGcs, 3)
+
+	// Batch 4 is resent after reconnect.
+	stream = &fakeStream{batches: []*grpc_model.DataGroupsBatch{
+		newJvmGcBatch(4, "6"),
+		newJvmGcBatch(5, "7"),
+	}}
+	assert.NoError(t, server.StreamDataGroups(stream))
+	if assert.Len(t, stream.acks, 2) {
+		assert.Equal(t, uint64(5), stream.acks[0].AckedSequence)
+		// Acked again when the stream is closed.
+		assert.Equal(t, uint64(5), stream.acks[1].AckedSequence)
+	}
+	if assert.Len(t, memory.jvmGcs, 4) {
+		assert.Equal(t, "7", memory.jvmGcs[3].Pid)
+	}
+}
+
+// syncedSink writes the datas stored before syncedTime.
+type syncedSink struct {
+	*memorySink
+	syncedTime time.Time
+}
+
+func (s *syncedSink) SyncedTime() time.Time {
+	return s.syncedTime
+}
+
+func TestStreamAckAfterSynced(t *testing.T) {
+	synced := &syncedSink{memorySink: setupGroupHandlers(t)}
+	global.SINK = synced
+	server := NewTraceServer(nil, &config.ReceiverConfig{StreamAckBatches: 1}, nil)
+	stream := &fakeStream{}
+	s := &dataGroupsStream{server: server, stream: stream}
+
+	assert.NoError(t, s.handle(context.Background(), newJvmGcBatch(1, "1")))
+	assert.Empty(t, stream.acks, "batch is not acked before it is written")
+	assert.Equal(t, uint64(0), server.sequences.get("agent-1"))
+
+	synced.syncedTime = time.Now()
+	assert.NoError(t, s.ack(false))
+	if assert.Len(t, stream.acks, 1) {
+		assert.Equal(t, uint64(1), stream.acks[0].AckedSequence)
+	}
+	assert.Equal(t, uint64(1), server.sequences.get("agent-1"))
+	// Not sent again if unchanged.
+	assert.NoError(t, s.ack(false))
+	assert.Len(t, stream.acks, 1)
+}
@@ -2,15 +2,18 @@ package trace
 
 import (
 	"context"
-	"encoding/json"
 	"log"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 
-	"github.com/CloudDetail/apo-module/model/v1"
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer"
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/admission"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/deadletter"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
 	"github.com/CloudDetail/apo-receiver/pkg/global"
 	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
 )
@@ -31,55 +34,134 @@ func init() {
 
 type TraceServer struct {
 	grpc_model.UnimplementedTraceServiceServer
-	analyzer *analyzer.ReportAnalyzer
+	analyzer  *analyzer.ReportAnalyzer
+	handlers  *groupHandlers
+	admission *admission.Controller
+	sequences *streamSequences
+	// ackBatches and ackInterval control the acks of StreamDataGroups.
+	ackBatches  int
+	ackInterval time.Duration
 }
 
-func NewTraceServer(analyzer *analyzer.ReportAnalyzer) *TraceServer {
+func NewTraceServer(analyzer *analyzer.ReportAnalyzer, cfg *config.ReceiverConfig, admission *admission.Controller) *TraceServer {
+	ackBatches := cfg.StreamAckBatches
+	if ackBatches <= 0 {
+		ackBatches = 100
+	}
+	ackIntervalMs := cfg.StreamAckIntervalMs
+	if ackIntervalMs <= 0 {
+		ackIntervalMs = 1000
+	}
 	return &TraceServer{
-		analyzer: analyzer,
+		analyzer:    analyzer,
+		handlers:    newGroupHandlers(analyzer, cfg.UnknownGroupPolicy),
+		admission:   admission,
+		sequences:   newStreamSequences(global.CACHE),
+		ackBatches:  ackBatches,
+		ackInterval: time.Duration(ackIntervalMs) * time.Millisecond,
 	}
 }
 
-func (server *TraceServer) StoreDataGroups(_ context.Context, dataGroups *grpc_model.DataGroups) (*emptypb.Empty, error) {
-	if dataGroups.Name == report.OnOffMetricGroup {
-		for _, data := range dataGroups.Datas {
-			server.analyzer.CacheMetric(data)
-		}
-		// OnOffMetric
-		global.CLICK_HOUSE.BatchStore(dataGroups.Name, dataGroups.Datas)
-	} else if dataGroups.Name == report.SpanTraceGroup {
-		for _, data := range dataGroups.Datas {
-			server.analyzer.CacheTrace(data)
-		}
-	} else if dataGroups.Name == report.DesignatedProfilingSignal {
-		// Same with the structure of SpanTraceGroup but lacked trace labels,
-		// also saved as SpanTraceGroup. DesignatedProfilingSignal is used for TraceProfiling.
-		for _, data := range dataGroups.Datas {
-			signal := &model.Trace{Labels: &model.TraceLabels{ThresholdMultiple: 1.0}}
-			if err := json.Unmarshal([]byte(data), signal); err != nil {
-				log.Printf("[x Parse Profile Signal] Error: %s", err.Error())
-				continue
-			}
-			global.CLICK_HOUSE.StoreTraceGroup(signal)
-		}
-	} else if dataGroups.Name == report.OriginxAgentEvent {
-		for _, data := range dataGroups.Datas {
-			server.analyzer.StoreEvent(data)
+func (server *TraceServer) StoreDataGroups(ctx context.Context, dataGroups *grpc_model.DataGroups) (*emptypb.Empty, error) {
+	usage := admission.Usage{Datas: len(dataGroups.Datas)}
+	for _, data := range dataGroups.Datas {
+		usage.Bytes += len(data)
+	}
+	if err := server.admission.Admit(ctx, deadletter.SourceOf(ctx), map[string]admission.Usage{dataGroups.Name: usage}); err != nil {
+		return nil, err
+	}
+	if err := server.storeDataGroups(ctx, dataGroups.Name, dataGroups.Datas); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (server *TraceServer) storeDataGroups(ctx context.Context, name string, datas []string) error {
+	if err := server.handlers.handle(ctx, name, datas); err != nil {
+		return err
+	}
+	if len(datas) > 0 {
+		ReceiveMessageTotal.WithLabelValues(name).Inc()
+	}
+	return nil
+}
+
+// StoreDataGroupsV2 handles the typed datas, which skip the json parsing of StoreDataGroups.
+func (server *TraceServer) StoreDataGroupsV2(ctx context.Context, dataGroups *grpc_model.TypedDataGroups) (*emptypb.Empty, error) {
+	usages := make(map[string]admission.Usage)
+	for _, data := range dataGroups.Datas {
+		if name := typedDataGroup(data); name != "" {
+			usage := usages[name]
+			usage.Datas++
+			usage.Bytes += proto.Size(data)
+			usages[name] = usage
 		}
-	} else if dataGroups.Name == report.OriginxAppInfo {
-		for _, data := range dataGroups.Datas {
-			server.analyzer.StoreAppInfo(data)
+	}
+	if err := server.admission.Admit(ctx, deadletter.SourceOf(ctx), usages); err != nil {
+		return nil, err
+	}
+	for _, data := range dataGroups.Datas {
+		switch payload := data.Payload.(type) {
+		case *grpc_model.TypedData_SpanTrace:
+			server.analyzer.CacheTrace(toTrace(payload.SpanTrace), "")
+		case *grpc_model.TypedData_OnoffMetricGroup:
+			server.analyzer.CacheMetric(toOnOffMetricGroup(payload.OnoffMetricGroup), "")
+			global.SINK.StoreOnOffMetric(payload.OnoffMetricGroup)
+		case *grpc_model.TypedData_FlameGraph:
+			global.SINK.StoreFlameGraph(payload.FlameGraph)
+		case *grpc_model.TypedData_ProfilingEvent:
+			global.SINK.StoreProfilingEvent(payload.ProfilingEvent)
+		case *grpc_model.TypedData_JvmGc:
+			global.SINK.StoreJvmGc(payload.JvmGc)
+		case *grpc_model.TypedData_Log:
+			server.analyzer.StoreLog(payload.Log)
+		case *grpc_model.TypedData_K8SEvent:
+			server.analyzer.StoreK8sEvent(payload.K8SEvent)
+		case *grpc_model.TypedData_AgentEvent:
+			server.analyzer.StoreEvent(toAgentEvent(payload.AgentEvent))
+		case *grpc_model.TypedData_AppInfo:
+			server.analyzer.StoreAppInfo(toAppInfo(payload.AppInfo))
+		default:
+			log.Printf("[x Unknown Typed Data] %T, Skip.", data.Payload)
 		}
-	} else {
-		// Profile、Log
-		global.CLICK_HOUSE.BatchStore(dataGroups.Name, dataGroups.Datas)
 	}
-	if len(dataGroups.Datas) > 0 {
-		ReceiveMessageTotal.WithLabelValues(dataGroups.Name).Inc()
+	for name := range usages {
+		ReceiveMessageTotal.WithLabelValues(name).Inc()
 	}
 	return &emptypb.Empty{}, nil
 }
 
+// typedDataGroup returns the data group of typed data, empty if it is unknown.
+func typedDataGroup(data *grpc_model.TypedData) string {
+	switch data.Payload.(type) {
+	case *grpc_model.TypedData_SpanTrace:
+		return report.SpanTraceGroup
+	case *grpc_model.TypedData_OnoffMetricGroup:
+		return report.OnOffMetricGroup
+	case *grpc_model.TypedData_FlameGraph:
+		return report.FlameGraph
+	case *grpc_model.TypedData_ProfilingEvent:
+		return report.CameraEventGroup
+	case *grpc_model.TypedData_JvmGc:
+		return report.JvmGc
+	case *grpc_model.TypedData_Log:
+		return report.LogGroup
+	case *grpc_model.TypedData_K8SEvent:
+		return report.K8sEventGroup
+	case *grpc_model.TypedData_AgentEvent:
+		return report.OriginxAgentEvent
+	case *grpc_model.TypedData_AppInfo:
+		return report.OriginxAppInfo
+	default:
+		return ""
+	}
+}
+
+// Redrive sends the dead letters to StoreDataGroups again, which are not limited by admission.
+func (server *TraceServer) Redrive(ctx context.Context, group string, datas []string) {
+	_ = server.storeDataGroups(ctx, group, datas)
+}
+
 func (server *TraceServer) Start() {
 	server.analyzer.Start()
 }
@@ -0,0 +1,108 @@
+package trace
+
+import (
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+// The typed datas of StoreDataGroupsV2 are converted into the models shared with v1 json datas.
+
+func toTrace(spanTrace *grpc_model.SpanTrace) *model.Trace {
+	trace := &model.Trace{
+		Name:         spanTrace.Name,
+		Timestamp:    spanTrace.Timestamp,
+		Version:      spanTrace.DataVersion,
+		Source:       spanTrace.Source,
+		Labels:       &model.TraceLabels{ThresholdMultiple: 1.0},
+		WorkloadName: spanTrace.WorkloadName,
+		WorkloadKind: spanTrace.WorkloadKind,
+		PodIp:        spanTrace.PodIp,
+		PodName:      spanTrace.PodName,
+		Namespace:    spanTrace.Namespace,
+	}
+	labels := spanTrace.Labels
+	if labels == nil {
+		return trace
+	}
+	trace.Labels = &model.TraceLabels{
+		Pid:               labels.Pid,
+		Tid:               labels.Tid,
+		TopSpan:           labels.TopSpan,
+		Protocol:          labels.Protocol,
+		ServiceName:       labels.ServiceName,
+		Url:               labels.ContentKey,
+		HttpUrl:           labels.HttpUrl,
+		IsSilent:          labels.IsSilent,
+		IsSampled:         labels.IsSampled,
+		IsSlow:            labels.IsSlow,
+		IsServer:          labels.IsServer,
+		IsError:           labels.IsError,
+		IsProfiled:        labels.IsProfiled,
+		ReportType:        labels.ReportType,
+		ThresholdType:     model.ThresholdType(labels.ThresholdType),
+		ThresholdValue:    labels.ThresholdValue,
+		ThresholdRange:    model.ThresholdRange(labels.ThresholdRange),
+		ThresholdMultiple: labels.ThresholdMultiple,
+		TraceId:           labels.TraceId,
+		ApmType:           labels.ApmType,
+		ApmSpanId:         labels.ApmSpanId,
+		Attributes:        labels.Attributes,
+		ContainerId:       labels.ContainerId,
+		ContainerName:     labels.ContainerName,
+		StartTime:         labels.StartTime,
+		Duration:          labels.Duration,
+		EndTime:           labels.EndTime,
+		NodeName:          labels.NodeName,
+		NodeIp:            labels.NodeIp,
+		ClusterID:         labels.ClusterId,
+		OffsetTs:          labels.OffsetTs,
+	}
+	if trace.Labels.ThresholdMultiple == 0 {
+		// Same default with v1 when the multiple is not set.
+		trace.Labels.ThresholdMultiple = 1.0
+	}
+	return trace
+}
+
+func toOnOffMetricGroup(onOffMetric *grpc_model.OnOffMetricGroup) *model.OnOffMetricGroup {
+	return &model.OnOffMetricGroup{
+		Name:      onOffMetric.Name,
+		Timestamp: onOffMetric.Timestamp,
+		TraceId:   onOffMetric.TraceId,
+		SpanId:    onOffMetric.SpanId,
+		Metrics:   onOffMetric.Metrics,
+	}
+}
+
+func toAgentEvent(agentEvent *grpc_model.AgentEvent) *model.AgentEvent {
+	labels := agentEvent.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	return &model.AgentEvent{
+		Timestamp: agentEvent.Timestamp,
+		Name:      agentEvent.Name,
+		Pid:       agentEvent.Pid,
+		Labels:    labels,
+		Status:    agentEvent.Status,
+	}
+}
+
+func toAppInfo(appInfo *grpc_model.AppInfo) *appinfo.AppInfo {
+	labels := appInfo.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	return &appinfo.AppInfo{
+		Timestamp:     appInfo.Timestamp,
+		StartTime:     appInfo.StartTime,
+		HeartTime:     appInfo.HeartTime,
+		HeartFlag:     appInfo.HeartFlag,
+		AgentInstance: appInfo.AgentInstance,
+		HostPid:       appInfo.HostPid,
+		ContainerPid:  appInfo.ContainerPid,
+		ContainerId:   appInfo.ContainerId,
+		Labels:        labels,
+	}
+}
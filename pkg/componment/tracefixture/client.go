@@ -0,0 +1,123 @@
+package tracefixture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	apmmodel "github.com/CloudDetail/apo-module/apm/model/v1"
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+var ErrNotRecorded = errors.New("not recorded in fixture")
+
+// FixtureClient serves the apm responses recorded in fixtures, instead of calling apm adapter.
+// The calls of one trace are served in the recorded order, the last one is served again when they are used up.
+type FixtureClient struct {
+	fixtures       map[string]*Fixture
+	needDetailSpan map[string]bool
+	lock           sync.Mutex
+	served         map[string]int // <traceId-method, calls served>
+}
+
+func NewFixtureClient(fixtures ...*Fixture) *FixtureClient {
+	client := &FixtureClient{
+		fixtures:       make(map[string]*Fixture),
+		needDetailSpan: make(map[string]bool),
+		served:         make(map[string]int),
+	}
+	for _, fixture := range fixtures {
+		client.fixtures[fixture.TraceId] = fixture
+		for apmType, needDetailSpan := range fixture.NeedDetailSpan {
+			client.needDetailSpan[apmType] = needDetailSpan
+		}
+	}
+	return client
+}
+
+func (client *FixtureClient) QueryServices(ctx context.Context, clusterId string, apmType string, traceId string, rootTrace *model.TraceLabels) ([]*apmmodel.OtelServiceNode, error) {
+	call, err := client.nextCall(traceId, MethodQueryServices, func(call *Call) bool {
+		return call.Method == MethodQueryServices && call.ApmType == apmType
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Copied as the nodes are changed by analyzer.
+	nodes := make([]*apmmodel.OtelServiceNode, 0, len(call.Nodes))
+	if err := copyByJson(call.Nodes, &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, callError(call)
+}
+
+func (client *FixtureClient) NeedGetDetailSpan(ctx context.Context, apmType string) bool {
+	return client.needDetailSpan[apmType]
+}
+
+func (client *FixtureClient) FillMutatedSpan(ctx context.Context, clusterId string, apmType string, traceId string, node *apmmodel.OtelServiceNode) error {
+	spanId := entrySpanId(node)
+	call, err := client.nextCall(traceId, MethodFillMutatedSpan+"-"+spanId, func(call *Call) bool {
+		return call.Method == MethodFillMutatedSpan && call.ApmType == apmType && call.SpanId == spanId
+	})
+	if err != nil {
+		return err
+	}
+	if call.Node != nil && node != nil {
+		if err := copyByJson(call.Node, node); err != nil {
+			return err
+		}
+	}
+	return callError(call)
+}
+
+func (client *FixtureClient) QueryMutatedSlowTraceTree(ctx context.Context, clusterId string, traceId string, traces *model.Traces) (*model.TraceTreeNode, []*model.ClientCall, error) {
+	return nil, nil, ErrNotRecorded
+}
+
+func (client *FixtureClient) QueryErrorTraceTree(ctx context.Context, clusterId string, traceId string, traces *model.Traces) (*model.ErrorTreeNode, error) {
+	return nil, ErrNotRecorded
+}
+
+// nextCall returns the next call matched, the calls served are counted by servedKey.
+func (client *FixtureClient) nextCall(traceId string, servedKey string, match func(call *Call) bool) (*Call, error) {
+	fixture, found := client.fixtures[traceId]
+	if !found {
+		return nil, fmt.Errorf("trace[%s] is %w", traceId, ErrNotRecorded)
+	}
+	calls := make([]*Call, 0)
+	for _, call := range fixture.Calls {
+		if match(call) {
+			calls = append(calls, call)
+		}
+	}
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("%s of trace[%s] is %w", servedKey, traceId, ErrNotRecorded)
+	}
+
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	key := traceId + "-" + servedKey
+	index := client.served[key]
+	if index >= len(calls) {
+		index = len(calls) - 1
+	}
+	client.served[key] = index + 1
+	return calls[index], nil
+}
+
+func copyByJson(from interface{}, to interface{}) error {
+	jsonBytes, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, to)
+}
+
+func callError(call *Call) error {
+	if call.Error == "" {
+		return nil
+	}
+	return errors.New(call.Error)
+}
@@ -0,0 +1,104 @@
+package tracefixture
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+
+	apmmodel "github.com/CloudDetail/apo-module/apm/model/v1"
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+const (
+	MethodQueryServices   = "QueryServices"
+	MethodFillMutatedSpan = "FillMutatedSpan"
+)
+
+// Fixture keeps the inbound datas of one trace and the responses of apm adapter,
+// which are replayed to build the reports without apm adapter.
+type Fixture struct {
+	TraceId    string       `json:"trace_id"`
+	DataGroups []*DataGroup `json:"data_groups"`
+	// NeedDetailSpan is the result of NeedGetDetailSpan by apm type.
+	NeedDetailSpan map[string]bool `json:"need_detail_span"`
+	Calls          []*Call         `json:"calls"`
+}
+
+// DataGroup is the same with the data group sent by agent, one json data for each span trace / onoff metric.
+type DataGroup struct {
+	Name  string   `json:"name"`
+	Datas []string `json:"datas"`
+}
+
+// Call is one request to apm adapter in order.
+type Call struct {
+	Method  string `json:"method"`
+	ApmType string `json:"apm_type"`
+	// SpanId is the entry span of node filled by FillMutatedSpan.
+	SpanId string                      `json:"span_id,omitempty"`
+	Nodes  []*apmmodel.OtelServiceNode `json:"nodes,omitempty"`
+	// Node is the node after filled by FillMutatedSpan.
+	Node  *apmmodel.OtelServiceNode `json:"node,omitempty"`
+	Error string                    `json:"error,omitempty"`
+}
+
+func newFixture(traceId string) *Fixture {
+	return &Fixture{
+		TraceId:        traceId,
+		DataGroups:     make([]*DataGroup, 0),
+		NeedDetailSpan: make(map[string]bool),
+		Calls:          make([]*Call, 0),
+	}
+}
+
+// LoadFixture reads the fixture file written by Recorder.
+func LoadFixture(path string) (*Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{}
+	if err := json.Unmarshal(content, fixture); err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
+
+func (fixture *Fixture) save(path string) error {
+	content, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Traces rebuilds the traces analyzed from the span trace datas.
+func (fixture *Fixture) Traces() (*model.Traces, error) {
+	traces := model.NewTraces(fixture.TraceId)
+	for _, dataGroup := range fixture.DataGroups {
+		switch dataGroup.Name {
+		case report.SpanTraceGroup:
+			for _, data := range dataGroup.Datas {
+				trace := &model.Trace{}
+				if err := json.Unmarshal([]byte(data), trace); err != nil {
+					return nil, err
+				}
+				traces.AddTrace(trace)
+			}
+		case report.OnOffMetricGroup:
+			traces.MetricCount += len(dataGroup.Datas)
+		}
+	}
+	return traces, nil
+}
+
+func fixturePath(dir string, traceId string) string {
+	return filepath.Join(dir, strings.ReplaceAll(traceId, string(filepath.Separator), "_")+".json")
+}
@@ -0,0 +1,90 @@
+package tracefixture
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CloudDetail/apo-module/apm/client/v1/api"
+	apmmodel "github.com/CloudDetail/apo-module/apm/model/v1"
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+type fakeClient struct {
+	api.ApmTraceAPI
+	responses [][]*apmmodel.OtelServiceNode
+}
+
+func (client *fakeClient) QueryServices(ctx context.Context, clusterId string, apmType string, traceId string, rootTrace *model.TraceLabels) ([]*apmmodel.OtelServiceNode, error) {
+	if len(client.responses) == 0 {
+		return nil, errors.New("trace is not found")
+	}
+	nodes := client.responses[0]
+	client.responses = client.responses[1:]
+	return nodes, nil
+}
+
+func (client *fakeClient) NeedGetDetailSpan(ctx context.Context, apmType string) bool {
+	return apmType == "arms"
+}
+
+func (client *fakeClient) FillMutatedSpan(ctx context.Context, clusterId string, apmType string, traceId string, node *apmmodel.OtelServiceNode) error {
+	node.ErrorSpans = []*apmmodel.OtelSpan{{Name: "filled"}}
+	return nil
+}
+
+func TestRecordAndServe(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	recorder, err := NewRecorder(&fakeClient{
+		responses: [][]*apmmodel.OtelServiceNode{
+			{{StartTime: 1}},
+			{{StartTime: 1}, {StartTime: 2}},
+		},
+	}, dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	traces := model.NewTraces("trace-1")
+	traces.Traces = []*model.Trace{{Labels: &model.TraceLabels{TraceId: "trace-1", ApmSpanId: "span-1"}}}
+	recorder.RecordDataGroups(traces, []*model.OnOffMetricGroup{{TraceId: "trace-1", SpanId: "span-1"}})
+	for i := 0; i < 3; i++ {
+		_, _ = recorder.QueryServices(ctx, "", "arms", "trace-1", nil)
+	}
+	_ = recorder.FillMutatedSpan(ctx, "", "arms", "trace-1", &apmmodel.OtelServiceNode{})
+
+	fixture, err := LoadFixture(filepath.Join(dir, "trace-1.json"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, fixture.DataGroups, 2)
+	assert.Len(t, fixture.Calls, 4)
+	replayed, err := fixture.Traces()
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, replayed.MetricCount)
+	}
+
+	client := NewFixtureClient(fixture)
+	assert.True(t, client.NeedGetDetailSpan(ctx, "arms"))
+	nodes, err := client.QueryServices(ctx, "", "arms", "trace-1", nil)
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	nodes, err = client.QueryServices(ctx, "", "arms", "trace-1", nil)
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+	// The last call is served again.
+	for i := 0; i < 2; i++ {
+		_, err = client.QueryServices(ctx, "", "arms", "trace-1", nil)
+		assert.EqualError(t, err, "trace is not found")
+	}
+	node := &apmmodel.OtelServiceNode{}
+	if assert.NoError(t, client.FillMutatedSpan(ctx, "", "arms", "trace-1", node)) {
+		assert.Equal(t, "filled", node.ErrorSpans[0].Name)
+	}
+	_, err = client.QueryServices(ctx, "", "arms", "trace-2", nil)
+	assert.ErrorIs(t, err, ErrNotRecorded)
+}
@@ -0,0 +1,136 @@
+package tracefixture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+
+	"github.com/CloudDetail/apo-module/apm/client/v1/api"
+	apmmodel "github.com/CloudDetail/apo-module/apm/model/v1"
+	"github.com/CloudDetail/apo-module/model/v1"
+)
+
+// RecorderInstance records the fixtures of analyzed traces, nothing is recorded when it is not set.
+var RecorderInstance *Recorder
+
+// Recorder calls the apm adapter and records the responses in <dir>/<traceId>.json.
+type Recorder struct {
+	client api.ApmTraceAPI
+	dir    string
+	lock   sync.Mutex
+}
+
+func NewRecorder(client api.ApmTraceAPI, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		client: client,
+		dir:    dir,
+	}, nil
+}
+
+// RecordDataGroups records the span traces and onoff metrics of traces before they are analyzed,
+// the datas recorded before are replaced as the traces are merged by retry.
+func (recorder *Recorder) RecordDataGroups(traces *model.Traces, metrics []*model.OnOffMetricGroup) {
+	spanTraces := &DataGroup{Name: report.SpanTraceGroup, Datas: make([]string, 0, len(traces.Traces))}
+	for _, trace := range traces.Traces {
+		spanTraces.Datas = append(spanTraces.Datas, toJson(trace))
+	}
+	onOffMetrics := &DataGroup{Name: report.OnOffMetricGroup, Datas: make([]string, 0, len(metrics))}
+	for _, metric := range metrics {
+		onOffMetrics.Datas = append(onOffMetrics.Datas, toJson(metric))
+	}
+	recorder.update(traces.TraceId, func(fixture *Fixture) {
+		fixture.DataGroups = []*DataGroup{spanTraces, onOffMetrics}
+	})
+}
+
+func (recorder *Recorder) QueryServices(ctx context.Context, clusterId string, apmType string, traceId string, rootTrace *model.TraceLabels) ([]*apmmodel.OtelServiceNode, error) {
+	nodes, err := recorder.client.QueryServices(ctx, clusterId, apmType, traceId, rootTrace)
+	needDetailSpan := recorder.client.NeedGetDetailSpan(ctx, apmType)
+	recorder.update(traceId, func(fixture *Fixture) {
+		fixture.NeedDetailSpan[apmType] = needDetailSpan
+		fixture.Calls = append(fixture.Calls, &Call{
+			Method:  MethodQueryServices,
+			ApmType: apmType,
+			Nodes:   nodes,
+			Error:   errorMessage(err),
+		})
+	})
+	return nodes, err
+}
+
+func (recorder *Recorder) NeedGetDetailSpan(ctx context.Context, apmType string) bool {
+	return recorder.client.NeedGetDetailSpan(ctx, apmType)
+}
+
+func (recorder *Recorder) FillMutatedSpan(ctx context.Context, clusterId string, apmType string, traceId string, node *apmmodel.OtelServiceNode) error {
+	err := recorder.client.FillMutatedSpan(ctx, clusterId, apmType, traceId, node)
+	recorder.update(traceId, func(fixture *Fixture) {
+		fixture.Calls = append(fixture.Calls, &Call{
+			Method:  MethodFillMutatedSpan,
+			ApmType: apmType,
+			SpanId:  entrySpanId(node),
+			Node:    node,
+			Error:   errorMessage(err),
+		})
+	})
+	return err
+}
+
+func (recorder *Recorder) QueryMutatedSlowTraceTree(ctx context.Context, clusterId string, traceId string, traces *model.Traces) (*model.TraceTreeNode, []*model.ClientCall, error) {
+	return recorder.client.QueryMutatedSlowTraceTree(ctx, clusterId, traceId, traces)
+}
+
+func (recorder *Recorder) QueryErrorTraceTree(ctx context.Context, clusterId string, traceId string, traces *model.Traces) (*model.ErrorTreeNode, error) {
+	return recorder.client.QueryErrorTraceTree(ctx, clusterId, traceId, traces)
+}
+
+// update reads the fixture from file, so that the fixtures are not kept in memory.
+func (recorder *Recorder) update(traceId string, fn func(fixture *Fixture)) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	path := fixturePath(recorder.dir, traceId)
+	fixture, err := LoadFixture(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fixture, err = newFixture(traceId), nil
+	}
+	if err != nil {
+		log.Printf("[x Record Fixture] TraceId: %s, Error: %s", traceId, err.Error())
+		return
+	}
+	fn(fixture)
+	if err := fixture.save(path); err != nil {
+		log.Printf("[x Record Fixture] TraceId: %s, Error: %s", traceId, err.Error())
+	}
+}
+
+func toJson(value interface{}) string {
+	jsonBytes, _ := json.Marshal(value)
+	return string(jsonBytes)
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func entrySpanId(node *apmmodel.OtelServiceNode) string {
+	if node == nil {
+		return ""
+	}
+	if entrySpan := node.GetEntrySpan(); entrySpan != nil {
+		return entrySpan.SpanId
+	}
+	return ""
+}
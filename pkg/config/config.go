@@ -8,12 +8,18 @@ import (
 
 type Config struct {
 	ReceiverCfg   *ReceiverConfig
+	SampleCfg     *SampleConfig
 	ProfileCfg    *ProfileConfig
 	PrometheusCfg *PrometheusConfig
 	ClickHouseCfg *ClickHouseConfig
 	AnalyzerCfg   *AnalyzerConfig
 	RedisCfg      *RedisConfig
 	K8sCfg        *K8sConfig
+	SinkCfg       *SinkConfig
+	HeartbeatCfg  *AppHeartbeatConfig
+	OtlpCfg       *OtlpConfig
+	DeadLetterCfg *DeadLetterConfig
+	AdmissionCfg  *AdmissionConfig
 }
 
 type ReceiverConfig struct {
@@ -23,6 +29,67 @@ type ReceiverConfig struct {
 	PortalAddress   string `mapstructure:"portal_address"`
 	ClusterId       string `mapstructure:"cluster_id"`
 	DingDingWH      string `mapstructure:"ding_ding_wh"`
+	// ShutdownTimeout is the seconds to drain the analyzer tasks when shutting down, If Not set will be set to 15.
+	ShutdownTimeout int `mapstructure:"shutdown_timeout"`
+	// UnknownGroupPolicy handles the data groups not known by receiver, reject / dead_letter / raw.
+	// If Not set will be set to dead_letter.
+	UnknownGroupPolicy string `mapstructure:"unknown_group_policy"`
+	// StreamAckBatches acks the stream of StreamDataGroups after N batches are accepted. If Not set will be set to 100.
+	StreamAckBatches int `mapstructure:"stream_ack_batches"`
+	// StreamAckIntervalMs acks the accepted batches of stream every N milliseconds. If Not set will be set to 1000.
+	StreamAckIntervalMs int `mapstructure:"stream_ack_interval_ms"`
+}
+
+type OtlpConfig struct {
+	// Enable receives OTLP traces by the TraceService of grpc_port and POST /v1/traces of http_port.
+	Enable bool `mapstructure:"enable"`
+	// ApmType is the apm type used to query the trace tree of OTLP traces. If Not set will be set to otel.
+	ApmType string `mapstructure:"apm_type"`
+	// DefaultSlowThresholdMs marks the span slow when no slow threshold is found by its content key.
+	// If Not set will be set to 1000.
+	DefaultSlowThresholdMs int `mapstructure:"default_slow_threshold_ms"`
+}
+
+type DeadLetterConfig struct {
+	// Dir keeps the rejected datas in <dir>/deadletter.ndjson, empty means they are kept in memory only.
+	Dir string `mapstructure:"dir"`
+	// MaxRecords keeps the latest N rejected datas. If Not set will be set to 10000.
+	MaxRecords int `mapstructure:"max_records"`
+}
+
+type AdmissionConfig struct {
+	// Enable limits the datas sent by StoreDataGroups, the rejected requests are returned ResourceExhausted.
+	Enable bool `mapstructure:"enable"`
+	// NodeLimit limits the datas sent by each agent node.
+	NodeLimit *AdmissionLimit `mapstructure:"node_limit"`
+	// GroupLimits limits the datas of data group sent by all the agent nodes, keyed by data group, eg. span_trace_group.
+	GroupLimits map[string]*AdmissionLimit `mapstructure:"group_limits"`
+	// HighWaterMark rejects all the datas when the usage of ClickHouse cache or analyzer tasks reaches it.
+	// If Not set will be set to 0.9.
+	HighWaterMark float64 `mapstructure:"high_water_mark"`
+	// MaxAnalyzerTasks is the pending analyzer tasks regarded as full usage. If Not set will be set to 10000.
+	MaxAnalyzerTasks int `mapstructure:"max_analyzer_tasks"`
+	// RetryAfterSeconds is the hint for agents to retry when the usage reaches high water mark. If Not set will be set to 5.
+	RetryAfterSeconds int `mapstructure:"retry_after_seconds"`
+}
+
+type AdmissionLimit struct {
+	// Rate is the datas accepted per second, 0 means unlimited.
+	Rate float64 `mapstructure:"rate"`
+	// Burst is the max datas accepted at once, also the max datas of one request. If Not set will be set to Rate.
+	Burst int `mapstructure:"burst"`
+	// MaxBytes is the max bytes of datas in one request, 0 means unlimited.
+	MaxBytes int `mapstructure:"max_bytes"`
+}
+
+type AppHeartbeatConfig struct {
+	// MissSeconds marks the app miss when no heartbeat is received in N seconds. If Not set will be set to 180.
+	MissSeconds int `mapstructure:"miss_seconds"`
+	// DeadSeconds marks the app dead when no heartbeat is received in N seconds, it must be greater than MissSeconds.
+	// If Not set will be set to 600.
+	DeadSeconds int `mapstructure:"dead_seconds"`
+	// SweepSeconds checks the heartbeats every N seconds. If Not set will be set to 30.
+	SweepSeconds int `mapstructure:"sweep_seconds"`
 }
 
 type SampleConfig struct {
@@ -78,9 +145,63 @@ type ClickHouseConfig struct {
 	// TTLConfigs
 	TTLConfig  []*TTLConfig  `mapstructure:"ttl_config"`
 	HashConfig []*HashConfig `mapstructure:"hash_config"`
+	// ScriptDir overrides the embedded sql templates with the files of same relative path under it,
+	// eg. <script_dir>/create_table/span_trace.tmp.sql, empty means using the embedded ones.
+	ScriptDir string `mapstructure:"script_dir"`
+	// SkipMigrate disables applying the pending migrations on start, run `apo-receiver migrate up` instead.
+	SkipMigrate bool `mapstructure:"skip_migrate"`
+	// MigrateLockWaitSeconds is the max time to wait for the migration lock held by another receiver,
+	// the receiver starts without migrating after it, and `migrate up` fails. Negative means no limit. If Not set will be set to 60.
+	MigrateLockWaitSeconds int64 `mapstructure:"migrate_lock_wait_seconds"`
 	// If Not set will be set to 5.
-	FlushSeconds        uint `mapstructure:"flush_seconds"`
+	FlushSeconds uint `mapstructure:"flush_seconds"`
+	// MaxBatchSize is the max rows written in one batch, 0 means no limit.
+	MaxBatchSize        int  `mapstructure:"max_batch_size"`
 	ExportServiceClient bool `mapstructure:"export_service_client"`
+	// SpoolDir is the local directory to keep the batches failed to write, empty means disabled.
+	SpoolDir string `mapstructure:"spool_dir"`
+	// SpoolMaxMB is the max size of SpoolDir, the oldest batches are evicted when exceeded.
+	// If Not set will be set to 1024.
+	SpoolMaxMB uint `mapstructure:"spool_max_mb"`
+	// CacheMaxRows is the max rows of each table cached before flush, negative means no limit.
+	// If Not set will be set to 100000.
+	CacheMaxRows int `mapstructure:"cache_max_rows"`
+	// CacheMaxMB is the max size of each table cached before flush, 0 means no limit.
+	CacheMaxMB int `mapstructure:"cache_max_mb"`
+	// CacheOverflowPolicy is drop_oldest / drop_newest / block, If Not set will be set to drop_oldest.
+	CacheOverflowPolicy string `mapstructure:"cache_overflow_policy"`
+	// CacheConfig overwrites the cache limits and writer settings of tables,
+	// error_propagation and service_client use the settings of error_report and service_relationship.
+	CacheConfig []*CacheConfig `mapstructure:"cache_config"`
+}
+
+type CacheConfig struct {
+	Tables         []string `mapstructure:"tables"`
+	MaxRows        int      `mapstructure:"max_rows"`
+	MaxMB          int      `mapstructure:"max_mb"`
+	OverflowPolicy string   `mapstructure:"overflow_policy"`
+	FlushSeconds   uint     `mapstructure:"flush_seconds"`
+	MaxBatchSize   int      `mapstructure:"max_batch_size"`
+}
+
+type SinkConfig struct {
+	// Targets are clickhouse / ndjson / parquet, the datas are written to all of them.
+	// If Not set will be set to [clickhouse].
+	Targets []string        `mapstructure:"targets"`
+	File    *FileSinkConfig `mapstructure:"file"`
+}
+
+type FileSinkConfig struct {
+	// Dir is the directory to write files, each table has its own sub directory. If Not set will be set to data.
+	Dir string `mapstructure:"dir"`
+	// RotateMB rotates the file when N MB records are written. If Not set will be set to 128.
+	RotateMB int `mapstructure:"rotate_mb"`
+	// RotateSeconds rotates the file after N seconds. If Not set will be set to 3600.
+	RotateSeconds int `mapstructure:"rotate_seconds"`
+	// MaxFiles is the max files kept of each table, 0 means no limit.
+	MaxFiles int `mapstructure:"max_files"`
+	// FlushSeconds flushes the buffered records to files. If Not set will be set to 5.
+	FlushSeconds int `mapstructure:"flush_seconds"`
 }
 
 type TTLConfig struct {
@@ -107,6 +228,50 @@ type AnalyzerConfig struct {
 	Timeout        int64    `mapstructure:"timeout"`
 	GetDetailTypes []string `mapstructure:"get_detail_types"`
 	HttpParser     string   `mapstructure:"http_parser"`
+	// SpanTraceDedupSeconds skips the span trace stored in N seconds by trace_id and apm_span_id, negative disables it.
+	// If Not set will be set to 600.
+	SpanTraceDedupSeconds int64 `mapstructure:"span_trace_dedup_seconds"`
+	// TaskQueueSize is the max pending tasks of analyzer, the normal tasks are shed first when it is full.
+	// If Not set will be set to 10000.
+	TaskQueueSize int `mapstructure:"task_queue_size"`
+	// RecordFixtureDir records the analyzed traces and the responses of apm adapter in <dir>/<traceId>.json,
+	// which are replayed by tests. If Not set nothing is recorded.
+	RecordFixtureDir string `mapstructure:"record_fixture_dir"`
+	// ApmPolicies overrides the wait and retry settings by apm type, eg. skywalking.
+	// nbs3 and cw wait 60 seconds and cw is not retried, the configured fields are layered over them.
+	ApmPolicies map[string]*ApmPolicyConfig `mapstructure:"apm_policies"`
+	// ApmQuery protects the apm adapter from the queries of analyzer.
+	ApmQuery *ApmQueryConfig `mapstructure:"apm_query"`
+}
+
+type ApmQueryConfig struct {
+	// CacheSeconds keeps the services of trace queried from apm adapter, which are shared by report types and retries.
+	// If Not set will be set to 60, negative disables it.
+	CacheSeconds int64 `mapstructure:"cache_seconds"`
+	// CacheEntries is the max traces cached, the entry expired first is evicted when full. If Not set will be set to 10000.
+	CacheEntries int `mapstructure:"cache_entries"`
+	// MaxConcurrency is the max queries sent to apm adapter at once. If Not set will be set to thread_count.
+	MaxConcurrency int `mapstructure:"max_concurrency"`
+	// FailureThreshold opens the circuit of apm type after N failures in a row, negative disables it.
+	// If Not set will be set to 5.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// OpenSeconds rejects the queries of apm type in N seconds after the circuit is opened, then one query is tried.
+	// If Not set will be set to 30.
+	OpenSeconds int64 `mapstructure:"open_seconds"`
+}
+
+// ApmPolicyConfig is the wait and retry policy of one apm type, the field not set falls back to the analyzer settings.
+type ApmPolicyConfig struct {
+	// WaitTime is the seconds to wait for the spans after the top span is received. If Not set will be set to delay_duration.
+	WaitTime int64 `mapstructure:"wait_time"`
+	// RetryTimes is the max retries when the apm trace is not ready. If Not set will be set to retry_times.
+	RetryTimes int `mapstructure:"retry_times"`
+	// RetryBackoff is the seconds between retries. If Not set will be set to retry_duration.
+	RetryBackoff int64 `mapstructure:"retry_backoff"`
+	// MissTopTime is the seconds to wait for the top span, negative disables it. If Not set will be set to miss_top_time.
+	MissTopTime int64 `mapstructure:"miss_top_time"`
+	// AllowRetry is false to drop the report without retry. If Not set will be set to true.
+	AllowRetry *bool `mapstructure:"allow_retry"`
 }
 
 type RedisConfig struct {
@@ -114,6 +279,9 @@ type RedisConfig struct {
 	Address    string `mapstructure:"address"`
 	Password   string `mapstructure:"password"`
 	ExpireTime int64  `mapstructure:"expire_time"`
+	// JournalDir keeps the pending works of analyzer and the cached spans in <dir>/analyzer.journal when redis is not enabled,
+	// they are recovered after restart. If Not set the pending works are kept in memory only.
+	JournalDir string `mapstructure:"journal_dir"`
 }
 
 type K8sConfig struct {
@@ -121,4 +289,24 @@ type K8sConfig struct {
 	APIType string `mapstructure:"api_type"` // meta_server
 
 	MetaServerConfig *metaconfigs.MetaSourceConfig `mapstructure:"meta_server_config"`
+	// EventWatcher watches the k8s events from apiserver and stores them as k8s_event_group.
+	EventWatcher *K8sEventWatcherConfig `mapstructure:"event_watcher"`
+}
+
+// K8sEventWatcherConfig shares the client and informers of metadata when kube_source of meta_server_config is set,
+// otherwise the watcher creates its own client by KubeAuthType.
+type K8sEventWatcherConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// serviceAccount / kubeConfig / none, same with kube_source of meta_server_config. If Not set will be set to serviceAccount.
+	KubeAuthType string `mapstructure:"kube_auth_type"`
+	// The kubeconfig file used by kubeConfig. If Not set will be set to ~/.kube/config.
+	KubeAuthConfig string `mapstructure:"kube_auth_config"`
+	// If Not set will be set to the cluster id of metadata, or the fingerprint of apiserver certificate.
+	ClusterID string `mapstructure:"cluster_id"`
+	// LeaderElection stores the events only by the leader of receivers, set true if multiple receivers are deployed.
+	LeaderElection bool `mapstructure:"leader_election"`
+	// The namespace of lease used by leader election. If Not set will be set to env POD_NAMESPACE or default.
+	LeaseNamespace string `mapstructure:"lease_namespace"`
+	// If Not set will be set to apo-receiver-k8s-event.
+	LeaseName string `mapstructure:"lease_name"`
 }
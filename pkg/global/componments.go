@@ -1,14 +1,17 @@
 package global
 
 import (
-	"github.com/CloudDetail/apo-receiver/pkg/clickhouse"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/appregistry"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/redis"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
 
 	"github.com/CloudDetail/apo-module/apm/client/v1/api"
 )
 
 var (
-	CLICK_HOUSE  *clickhouse.ClickHouseClient
+	SINK         sink.Sink
+	QUERIER      sink.Querier
+	APP_REGISTRY *appregistry.Registry
 	TRACE_CLIENT api.ApmTraceAPI
 	CACHE        redis.ExpirableCache
 	PROM_RANGE   string
@@ -2,31 +2,47 @@ package httpserver
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"os"
-	"os/signal"
+	"net/http"
 	"strconv"
-	"syscall"
+	"time"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/middleware/pprof"
 
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/deadletter"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/threshold"
 	"github.com/CloudDetail/apo-receiver/pkg/global"
 	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
 
 	slomodel "github.com/CloudDetail/apo-module/slo/api/v1/model"
 	sloconfig "github.com/CloudDetail/apo-module/slo/sdk/v1/config"
 )
 
-func StartHttpServer(port int, openMetricsApi bool) {
+// shutdownTimeout bounds the wait for the requests in flight when ctx is done.
+const shutdownTimeout = 5 * time.Second
+
+// StartHttpServer serves until ctx is done, it returns after the server is shut down.
+func StartHttpServer(ctx context.Context, port int, openMetricsApi bool, otlpHandler http.Handler) {
 	app := iris.Default()
 
 	if openMetricsApi {
 		app.Get("/metrics", getPromMetrics)
 	}
+	if otlpHandler != nil {
+		app.Post("/v1/traces", iris.FromStd(otlpHandler))
+	}
 	app.Post("/config/slo", setSLOConfig)
 	app.Get("/debug/thresholds", getThresholds)
+	app.Get("/debug/apps", getAppCounts)
+	app.Get("/deadletter", getDeadLetters)
+	app.Post("/deadletter/redrive", redriveDeadLetters)
+	app.Post("/replay", replayReports)
 	app.Get("/realtimereport/slow/{traceId:string}", realtimeSlowReport)
 	app.Get("/realtimereport/error/{traceId:string}", realtimeErrorReport)
 
@@ -35,18 +51,24 @@ func StartHttpServer(port int, openMetricsApi bool) {
 	app.Any("/debug/pprof/{action:path}", p)
 
 	// Graceful shutdown
+	shutdownDone := make(chan struct{})
 	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		<-c
+		<-ctx.Done()
 		log.Println("Shutting down HTTP server...")
-		_ = app.Shutdown(context.Background())
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = app.Shutdown(shutdownCtx)
+		close(shutdownDone)
 	}()
 
-	err := app.Listen(":" + strconv.Itoa(port))
+	// Shutdown is coordinated by receiver, not the interrupt handler of iris.
+	err := app.Listen(":"+strconv.Itoa(port), iris.WithoutInterruptHandler, iris.WithoutServerError(iris.ErrServerClosed))
 	if err != nil {
 		log.Fatalf("Failed to start the http server %v", err)
 	}
+	// Return after the requests in flight are done, eg. the OTLP traces being cached.
+	<-shutdownDone
+	log.Println("HTTP server closed.")
 }
 
 type BasicStatus string
@@ -98,11 +120,136 @@ func getThresholds(ctx iris.Context) {
 	})
 }
 
+func getAppCounts(ctx iris.Context) {
+	_ = ctx.JSON(BasicResponse{
+		Status: Success,
+		Data:   global.APP_REGISTRY.QueryNodeCounts(),
+	})
+}
+
+// getDeadLetters lists the latest dead letters, filtered by the query params group and source.
+func getDeadLetters(ctx iris.Context) {
+	filter := &deadletter.Filter{
+		Group:  ctx.URLParam("group"),
+		Source: ctx.URLParam("source"),
+	}
+	_ = ctx.JSON(BasicResponse{
+		Status: Success,
+		Data:   deadletter.StoreInstance.Query(filter, ctx.URLParamIntDefault("limit", 100)),
+	})
+}
+
+// redriveDeadLetters sends the dead letters matched by the ids / group / source to ingest again.
+func redriveDeadLetters(ctx iris.Context) {
+	filter := &deadletter.Filter{}
+	if err := ctx.ReadJSON(filter); err != nil && !iris.IsErrEmptyJSON(err) {
+		ctx.StopWithStatus(iris.StatusBadRequest)
+		_ = ctx.JSON(BasicResponse{
+			Status:  Failure,
+			Message: err.Error(),
+		})
+		return
+	}
+	count, err := deadletter.StoreInstance.Redrive(filter)
+	if err != nil {
+		ctx.StopWithStatus(iris.StatusInternalServerError)
+		_ = ctx.JSON(BasicResponse{
+			Status:  Failure,
+			Message: err.Error(),
+		})
+		return
+	}
+	_ = ctx.JSON(BasicResponse{
+		Status: Success,
+		Data:   count,
+	})
+}
+
+type ReplayRequest struct {
+	TraceId string `json:"traceId"`
+	// Start and End is the window in RFC3339, End is now if not set.
+	Start          string `json:"start"`
+	End            string `json:"end"`
+	Limit          int    `json:"limit"`
+	MutateNodeMode string `json:"mutateNodeMode"`
+	// Output is stdout to return the results, or shadow to write them to replay_report.
+	Output string `json:"output"`
+}
+
+// replayReports rebuilds the reports of the trace or the traces in window, the production tables are not changed.
+func replayReports(ctx iris.Context) {
+	request := &ReplayRequest{}
+	var startTime, endTime time.Time
+	err := ctx.ReadJSON(request)
+	if err == nil {
+		err = checkReplayRequest(request)
+	}
+	if err == nil && request.TraceId == "" {
+		startTime, endTime, err = analyzer.ParseReplayWindow(request.Start, request.End)
+	}
+	if err != nil {
+		ctx.StopWithStatus(iris.StatusBadRequest)
+		_ = ctx.JSON(BasicResponse{
+			Status:  Failure,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var results []*report.ReplayResult
+	if request.TraceId != "" {
+		results, err = analyzer.ReplayerInstance.ReplayTrace(ctx, request.TraceId, request.MutateNodeMode)
+	} else {
+		results, err = analyzer.ReplayerInstance.ReplayTime(ctx, startTime, endTime, request.Limit, request.MutateNodeMode)
+	}
+	if err == nil && request.Output == analyzer.ReplayOutputShadow {
+		err = analyzer.ReplayerInstance.Store(ctx, results)
+	}
+	if err != nil {
+		ctx.StopWithStatus(iris.StatusInternalServerError)
+		_ = ctx.JSON(BasicResponse{
+			Status:  Failure,
+			Message: err.Error(),
+		})
+		return
+	}
+	if request.Output == analyzer.ReplayOutputShadow {
+		// Only the count is returned, the results are read from replay_report.
+		_ = ctx.JSON(BasicResponse{
+			Status: Success,
+			Data:   len(results),
+		})
+		return
+	}
+	_ = ctx.JSON(BasicResponse{
+		Status: Success,
+		Data:   results,
+	})
+}
+
+func checkReplayRequest(request *ReplayRequest) error {
+	if analyzer.ReplayerInstance == nil {
+		return sink.ErrNotQueryable
+	}
+	if request.TraceId == "" && request.Start == "" {
+		return errors.New("either traceId or start must be set")
+	}
+	if request.Output == "" {
+		request.Output = analyzer.ReplayOutputStdout
+	} else if request.Output != analyzer.ReplayOutputStdout && request.Output != analyzer.ReplayOutputShadow {
+		return fmt.Errorf("unknown output %q", request.Output)
+	}
+	if request.Limit <= 0 {
+		request.Limit = 100
+	}
+	return nil
+}
+
 func realtimeSlowReport(ctx iris.Context) {
 	traceId := ctx.Params().GetString("traceId")
 	clusterID := ctx.Params().GetString("clusterId")
 
-	traces, err := global.CLICK_HOUSE.QueryTraces(ctx, traceId)
+	traces, err := global.QUERIER.QueryTraces(ctx, traceId)
 	if err != nil {
 		responseWithError(ctx, err)
 		return
@@ -124,7 +271,7 @@ func realtimeErrorReport(ctx iris.Context) {
 	traceId := ctx.Params().GetString("traceId")
 	clusterID := ctx.Params().GetString("clusterId")
 
-	traces, err := global.CLICK_HOUSE.QueryTraces(ctx, traceId)
+	traces, err := global.QUERIER.QueryTraces(ctx, traceId)
 	if err != nil {
 		responseWithError(ctx, err)
 		return
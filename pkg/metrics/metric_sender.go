@@ -16,7 +16,9 @@ type Sender interface {
 	SendMetrics(ctx context.Context) error
 }
 
-func InitMetricSend(url string, interval int, promType string) error {
+// InitMetricSend sends the metrics periodically until ctx is done,
+// the returned Sender can be used to send the metrics for the last time.
+func InitMetricSend(ctx context.Context, url string, interval int, promType string) (Sender, error) {
 	var (
 		sender Sender
 		err    error
@@ -35,9 +37,9 @@ func InitMetricSend(url string, interval int, promType string) error {
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return InitMetricSendWithOptions(context.Background(), time.Duration(interval)*time.Second, sender)
+	return sender, InitMetricSendWithOptions(ctx, time.Duration(interval)*time.Second, sender)
 }
 
 func InitMetricSendWithOptions(ctx context.Context, interval time.Duration, sender Sender) error {
@@ -46,6 +46,118 @@ var (
 			"node_name", "node_ip", "pid", "container_id", "is_hit",
 		},
 	}
+
+	MetricClickHouseSpoolSegments = &MetricDef{
+		Name: "originx_sr_clickhouse_spool_segments",
+		Help: "A gauge of the batches spooled on disk which wait to replay",
+		Type: MetricGauge,
+		Keys: []string{"table"},
+	}
+
+	MetricClickHouseSpoolBytes = &MetricDef{
+		Name: "originx_sr_clickhouse_spool_bytes",
+		Help: "A gauge of the bytes spooled on disk which wait to replay",
+		Type: MetricGauge,
+		Keys: []string{"table"},
+	}
+
+	MetricClickHouseSpoolReplayedCount = &MetricDef{
+		Name: "originx_sr_clickhouse_spool_replayed_count",
+		Help: "A counter of the spooled batches replayed into clickhouse",
+		Type: MetricCounter,
+		Keys: []string{"table"},
+	}
+
+	MetricClickHouseSpoolEvictedCount = &MetricDef{
+		Name: "originx_sr_clickhouse_spool_evicted_count",
+		Help: "A counter of the spooled batches evicted by the spool size limit",
+		Type: MetricCounter,
+		Keys: []string{"table"},
+	}
+
+	MetricClickHouseCacheDroppedCount = &MetricDef{
+		Name: "originx_sr_clickhouse_cache_dropped_count",
+		Help: "A counter of the datas dropped by the cache limit before write to clickhouse",
+		Type: MetricCounter,
+		Keys: []string{"table", "policy"},
+	}
+
+	MetricAppHeartbeatApps = &MetricDef{
+		Name: "originx_sr_app_heartbeat_apps",
+		Help: "A gauge of the monitored apps of each node by heart flag alive / miss / dead",
+		Type: MetricGauge,
+		Keys: []string{"node_ip", "node_name", "heart_flag"},
+	}
+
+	MetricAdmissionRejectedCount = &MetricDef{
+		Name: "originx_sr_admission_rejected_count",
+		Help: "A counter of the datas rejected by admission control, reason is high_water / max_bytes / rate",
+		Type: MetricCounter,
+		Keys: []string{"node", "group", "reason"},
+	}
+
+	MetricSpanTraceDuplicateCount = &MetricDef{
+		Name: "originx_sr_span_trace_duplicate_count",
+		Help: "A counter of the duplicate span traces which are not stored again",
+		Type: MetricCounter,
+		Keys: []string{"apm_type"},
+	}
+
+	MetricAnalyzerRecoveredCount = &MetricDef{
+		Name: "originx_sr_analyzer_recovered_count",
+		Help: "A counter of the pending works of analyzer recovered from journal, kind is wait / check_miss / task",
+		Type: MetricCounter,
+		Keys: []string{"kind"},
+	}
+
+	MetricAnalyzerTaskQueueDepth = &MetricDef{
+		Name: "originx_sr_analyzer_task_queue_depth",
+		Help: "A gauge of the pending tasks of analyzer by priority error / slow / normal, including those to retry",
+		Type: MetricGauge,
+		Keys: []string{"priority"},
+	}
+
+	MetricAnalyzerTaskShedCount = &MetricDef{
+		Name: "originx_sr_analyzer_task_shed_count",
+		Help: "A counter of the analyzer tasks shed when the task queue is full",
+		Type: MetricCounter,
+		Keys: []string{"priority"},
+	}
+
+	MetricDropReportCount = &MetricDef{
+		Name: "originx_sr_drop_report_count",
+		Help: "A counter of the dropped slow / error reports by drop reason code and entry service",
+		Type: MetricCounter,
+		Keys: []string{"report_type", "reason", "entry_service"},
+	}
+
+	MetricApmQueryDuration = &MetricDef{
+		Name: "originx_sr_apm_query_duration_nanoseconds",
+		Help: "A histogram of the queries sent to apm adapter by method QueryServices / FillMutatedSpan",
+		Type: MetricHistogram,
+		Keys: []string{"method", "apm_type", "is_error"},
+	}
+
+	MetricApmQueryCacheCount = &MetricDef{
+		Name: "originx_sr_apm_query_cache_count",
+		Help: "A counter of the services of trace queried by analyzer, is_hit is true if they are found in cache",
+		Type: MetricCounter,
+		Keys: []string{"apm_type", "is_hit"},
+	}
+
+	MetricApmCircuitRejectedCount = &MetricDef{
+		Name: "originx_sr_apm_circuit_rejected_count",
+		Help: "A counter of the queries rejected by the open circuit of apm type",
+		Type: MetricCounter,
+		Keys: []string{"apm_type"},
+	}
+
+	MetricDeadLetterCount = &MetricDef{
+		Name: "originx_sr_dead_letter_count",
+		Help: "A counter of the datas failed to parse or rejected, which are kept as dead letters",
+		Type: MetricCounter,
+		Keys: []string{"group"},
+	}
 )
 
 const (
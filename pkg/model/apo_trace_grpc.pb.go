@@ -20,7 +20,9 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	TraceService_StoreDataGroups_FullMethodName = "/kindling.TraceService/StoreDataGroups"
+	TraceService_StoreDataGroups_FullMethodName   = "/kindling.TraceService/StoreDataGroups"
+	TraceService_StoreDataGroupsV2_FullMethodName = "/kindling.TraceService/StoreDataGroupsV2"
+	TraceService_StreamDataGroups_FullMethodName  = "/kindling.TraceService/StreamDataGroups"
 )
 
 // TraceServiceClient is the client API for TraceService service.
@@ -28,6 +30,10 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TraceServiceClient interface {
 	StoreDataGroups(ctx context.Context, in *DataGroups, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// StoreDataGroupsV2 receives the typed datas instead of json strings.
+	StoreDataGroupsV2(ctx context.Context, in *TypedDataGroups, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// StreamDataGroups keeps one stream for the agent, the accepted batches are acked periodically.
+	StreamDataGroups(ctx context.Context, opts ...grpc.CallOption) (TraceService_StreamDataGroupsClient, error)
 }
 
 type traceServiceClient struct {
@@ -47,11 +53,55 @@ func (c *traceServiceClient) StoreDataGroups(ctx context.Context, in *DataGroups
 	return out, nil
 }
 
+func (c *traceServiceClient) StoreDataGroupsV2(ctx context.Context, in *TypedDataGroups, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TraceService_StoreDataGroupsV2_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traceServiceClient) StreamDataGroups(ctx context.Context, opts ...grpc.CallOption) (TraceService_StreamDataGroupsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TraceService_ServiceDesc.Streams[0], TraceService_StreamDataGroups_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &traceServiceStreamDataGroupsClient{stream}
+	return x, nil
+}
+
+type TraceService_StreamDataGroupsClient interface {
+	Send(*DataGroupsBatch) error
+	Recv() (*DataGroupsAck, error)
+	grpc.ClientStream
+}
+
+type traceServiceStreamDataGroupsClient struct {
+	grpc.ClientStream
+}
+
+func (x *traceServiceStreamDataGroupsClient) Send(m *DataGroupsBatch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *traceServiceStreamDataGroupsClient) Recv() (*DataGroupsAck, error) {
+	m := new(DataGroupsAck)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TraceServiceServer is the server API for TraceService service.
 // All implementations must embed UnimplementedTraceServiceServer
 // for forward compatibility
 type TraceServiceServer interface {
 	StoreDataGroups(context.Context, *DataGroups) (*emptypb.Empty, error)
+	// StoreDataGroupsV2 receives the typed datas instead of json strings.
+	StoreDataGroupsV2(context.Context, *TypedDataGroups) (*emptypb.Empty, error)
+	// StreamDataGroups keeps one stream for the agent, the accepted batches are acked periodically.
+	StreamDataGroups(TraceService_StreamDataGroupsServer) error
 	mustEmbedUnimplementedTraceServiceServer()
 }
 
@@ -62,6 +112,12 @@ type UnimplementedTraceServiceServer struct {
 func (UnimplementedTraceServiceServer) StoreDataGroups(context.Context, *DataGroups) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StoreDataGroups not implemented")
 }
+func (UnimplementedTraceServiceServer) StoreDataGroupsV2(context.Context, *TypedDataGroups) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StoreDataGroupsV2 not implemented")
+}
+func (UnimplementedTraceServiceServer) StreamDataGroups(TraceService_StreamDataGroupsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDataGroups not implemented")
+}
 func (UnimplementedTraceServiceServer) mustEmbedUnimplementedTraceServiceServer() {}
 
 // UnsafeTraceServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -93,6 +149,50 @@ func _TraceService_StoreDataGroups_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TraceService_StoreDataGroupsV2_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TypedDataGroups)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraceServiceServer).StoreDataGroupsV2(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraceService_StoreDataGroupsV2_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraceServiceServer).StoreDataGroupsV2(ctx, req.(*TypedDataGroups))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraceService_StreamDataGroups_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TraceServiceServer).StreamDataGroups(&traceServiceStreamDataGroupsServer{stream})
+}
+
+type TraceService_StreamDataGroupsServer interface {
+	Send(*DataGroupsAck) error
+	Recv() (*DataGroupsBatch, error)
+	grpc.ServerStream
+}
+
+type traceServiceStreamDataGroupsServer struct {
+	grpc.ServerStream
+}
+
+func (x *traceServiceStreamDataGroupsServer) Send(m *DataGroupsAck) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *traceServiceStreamDataGroupsServer) Recv() (*DataGroupsBatch, error) {
+	m := new(DataGroupsBatch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TraceService_ServiceDesc is the grpc.ServiceDesc for TraceService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -104,7 +204,18 @@ var TraceService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StoreDataGroups",
 			Handler:    _TraceService_StoreDataGroups_Handler,
 		},
+		{
+			MethodName: "StoreDataGroupsV2",
+			Handler:    _TraceService_StoreDataGroupsV2_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDataGroups",
+			Handler:       _TraceService_StreamDataGroups_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/model/apo_trace.proto",
 }
@@ -0,0 +1,24 @@
+package receiver
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/CloudDetail/apo-receiver/pkg/clickhouse"
+)
+
+// DumpDDL runs `apo-receiver ddl -config=...`, which prints the sql to create tables rendered by the config.
+func DumpDDL(args []string) error {
+	flags := flag.NewFlagSet("ddl", flag.ExitOnError)
+	configPath := flags.String("config", "receiver-config.yml", "Configuration file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readInConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("fail to read configuration: %w", err)
+	}
+	return clickhouse.DumpDDL(cfg.ClickHouseCfg, os.Stdout)
+}
@@ -0,0 +1,36 @@
+package receiver
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/CloudDetail/apo-receiver/pkg/clickhouse"
+)
+
+// Migrate runs `apo-receiver migrate [up|status|dry-run] -config=...`, the action is up if not set.
+func Migrate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	configPath := flags.String("config", "receiver-config.yml", "Configuration file")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: apo-receiver migrate [%s|%s|%s] [-config=receiver-config.yml]\n",
+			clickhouse.MigrateActionUp, clickhouse.MigrateActionStatus, clickhouse.MigrateActionDryRun)
+		flags.PrintDefaults()
+	}
+
+	action := clickhouse.MigrateActionUp
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		action = args[0]
+		args = args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readInConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("fail to read configuration: %w", err)
+	}
+	return clickhouse.RunMigrate(ctx, cfg.ClickHouseCfg, action, os.Stdout)
+}
@@ -7,13 +7,16 @@ import (
 	"fmt"
 	"log"
 	"net"
-	"os"
-	"os/signal"
+	"net/http"
 	"strconv"
 	"sync"
-	"syscall"
+	"time"
 
+	"github.com/CloudDetail/apo-receiver/pkg/componment/admission"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/agentmonitor"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/appregistry"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/deadletter"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/k8sevent"
 
 	"github.com/CloudDetail/apo-receiver/pkg/componment/ebpffile"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/redis"
@@ -24,6 +27,7 @@ import (
 	"github.com/prometheus/client_golang/api"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"google.golang.org/grpc"
 
 	"github.com/CloudDetail/apo-receiver/pkg/analyzer"
@@ -32,11 +36,14 @@ import (
 	"github.com/CloudDetail/apo-receiver/pkg/componment/profile"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/threshold"
 	"github.com/CloudDetail/apo-receiver/pkg/componment/trace"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/tracefixture"
 	"github.com/CloudDetail/apo-receiver/pkg/config"
 	"github.com/CloudDetail/apo-receiver/pkg/global"
 	"github.com/CloudDetail/apo-receiver/pkg/model"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
 
 	"github.com/CloudDetail/apo-module/apm/client/v1"
+	apmapi "github.com/CloudDetail/apo-module/apm/client/v1/api"
 	sloconfig "github.com/CloudDetail/apo-module/slo/sdk/v1/config"
 	slomanager "github.com/CloudDetail/apo-module/slo/sdk/v1/manager"
 	"github.com/CloudDetail/metadata/source"
@@ -46,10 +53,12 @@ func Run(ctx context.Context) error {
 	// Initialize flags
 	configPath := flag.String("config", "receiver-config.yml", "Configuration file")
 	flag.Parse()
-	receiverCfg, sampleCfg, profileCfg, prometheusCfg, clickHouseCfg, analyzerCfg, redisCfg, k8sCfg, err := readInConfig(*configPath)
+	cfg, err := readInConfig(*configPath)
 	if err != nil {
 		return fmt.Errorf("fail to read configuration: %w", err)
 	}
+	receiverCfg, sampleCfg, profileCfg, prometheusCfg, analyzerCfg, redisCfg, k8sCfg :=
+		cfg.ReceiverCfg, cfg.SampleCfg, cfg.ProfileCfg, cfg.PrometheusCfg, cfg.AnalyzerCfg, cfg.RedisCfg, cfg.K8sCfg
 
 	if redisCfg.Enable {
 		redisClient, err := redis.NewRedisClient(redisCfg.Address, redisCfg.Password, redisCfg.ExpireTime)
@@ -58,23 +67,47 @@ func Run(ctx context.Context) error {
 		}
 		global.CACHE = redisClient
 	} else {
-		global.CACHE = redis.NewLocalCache(redisCfg.ExpireTime)
+		localCache, err := redis.NewLocalCache(redisCfg.ExpireTime, redisCfg.JournalDir)
+		if err != nil {
+			return fmt.Errorf("fail to create local cache: %w", err)
+		}
+		global.CACHE = localCache
 	}
 	global.CACHE.Start()
 
-	global.TRACE_CLIENT = client.NewApmTraceClient(
-		analyzerCfg.TraceAddress,
-		analyzerCfg.Timeout,
-		analyzerCfg.RatioThreshold,
-		analyzerCfg.MuateNodeMode,
-		analyzerCfg.GetDetailTypes)
+	global.TRACE_CLIENT = newTraceClient(analyzerCfg)
+	if analyzerCfg.RecordFixtureDir != "" {
+		if tracefixture.RecorderInstance, err = tracefixture.NewRecorder(global.TRACE_CLIENT, analyzerCfg.RecordFixtureDir); err != nil {
+			return fmt.Errorf("fail to create fixture recorder: %w", err)
+		}
+		global.TRACE_CLIENT = tracefixture.RecorderInstance
+		log.Printf("Record the trace fixtures in %s", analyzerCfg.RecordFixtureDir)
+	}
+
+	if deadletter.StoreInstance, err = deadletter.NewStore(cfg.DeadLetterCfg); err != nil {
+		return fmt.Errorf("fail to create dead letter store: %w", err)
+	}
 
-	clickHouseClient, err := clickhouse.NewClickHouseClient(ctx, clickHouseCfg, prometheusCfg.GenerateClientMetric, prometheusCfg.ClientMetricWithUrl)
+	storeSink, clickHouseClient, err := newSink(ctx, cfg.SinkCfg, cfg.ClickHouseCfg, prometheusCfg)
 	if err != nil {
-		return fmt.Errorf("fail to create ClickHouse client: %w", err)
+		return err
+	}
+	global.SINK = storeSink
+	storeSink.Start()
+	var heartbeatStore appregistry.HeartbeatStore
+	if clickHouseClient != nil {
+		global.QUERIER = clickHouseClient
+		heartbeatStore = clickHouseClient
+		analyzer.ReplayerInstance = analyzer.NewReplayer(analyzerCfg, clickHouseClient, clickHouseClient)
+	} else {
+		global.QUERIER = sink.NoopQuerier{}
+	}
+	admissionController := admission.NewController(cfg.AdmissionCfg)
+	if clickHouseClient != nil {
+		admissionController.RegisterUsage("clickhouse_cache", clickHouseClient.CacheUsage)
 	}
-	global.CLICK_HOUSE = clickHouseClient
-	clickHouseClient.Start()
+	global.APP_REGISTRY = appregistry.NewRegistry(heartbeatStore, cfg.HeartbeatCfg, storeSink.StoreAgentEvent)
+	global.APP_REGISTRY.Start(ctx)
 
 	if len(prometheusCfg.LatencyHistogramBuckets) == 0 && prometheusCfg.Storage == "prom" && prometheusCfg.GenerateClientMetric {
 		return errors.New("miss latency_histogram_buckets for promethues")
@@ -99,6 +132,23 @@ func Run(ctx context.Context) error {
 	onoffmetric.CacheInstance = onoffmetric.NewMetricCache(prometheusV1Api)
 	onoffmetric.CacheInstance.Start()
 
+	listen, err := net.Listen("tcp", ":"+strconv.Itoa(receiverCfg.GrpcPort))
+	if err != nil {
+		return fmt.Errorf("fail to listen Grpc Port: %w", err)
+	}
+	grpcServer, reportAnalyzer := newGrpcServer(receiverCfg, sampleCfg, profileCfg, analyzerCfg, threshold.CacheInstance, prometheusV1Api, admissionController)
+	admissionController.RegisterAnalyzerTasks(reportAnalyzer.PendingTasks)
+	admissionController.Start(ctx)
+	var otlpHandler http.Handler
+	if cfg.OtlpCfg.Enable {
+		otlpServer := trace.NewOtlpTraceServer(cfg.OtlpCfg, reportAnalyzer, threshold.CacheInstance)
+		ptraceotlp.RegisterGRPCServer(grpcServer, otlpServer)
+		otlpHandler = otlpServer
+		log.Printf("Receive OTLP traces by grpc port %d and http port %d", receiverCfg.GrpcPort, receiverCfg.HttpPort)
+	}
+
+	// Registered before metadata is started to share its client and informers.
+	startK8sEventWatcher(ctx, k8sCfg, reportAnalyzer)
 	startMetadataFetch(k8sCfg)
 
 	// Start gRPC server
@@ -106,14 +156,22 @@ func Run(ctx context.Context) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		startGrpcServer(receiverCfg, sampleCfg, profileCfg, analyzerCfg, threshold.CacheInstance, prometheusV1Api)
+		log.Printf("Start Grpc Server: %d", receiverCfg.GrpcPort)
+		if err := grpcServer.Serve(listen); err != nil {
+			log.Fatalf("Fail to start server: %v", err)
+		}
 	}()
 	// Start HTTP server
+	httpCtx, stopHttpServer := context.WithCancel(ctx)
+	httpStopped := make(chan struct{})
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		httpserver.StartHttpServer(receiverCfg.HttpPort, prometheusCfg.OpenApiMetrics)
+		defer close(httpStopped)
+		httpserver.StartHttpServer(httpCtx, receiverCfg.HttpPort, prometheusCfg.OpenApiMetrics, otlpHandler)
 	}()
+	metricCtx, stopMetricSend := context.WithCancel(ctx)
+	var metricSender metrics.Sender
 	if prometheusCfg.SendApi != "" && prometheusCfg.SendInterval > 0 {
 		promSendAddress := prometheusCfg.SendAddress
 		if promSendAddress == "" {
@@ -125,23 +183,45 @@ func Run(ctx context.Context) error {
 			// fix for earlier version.
 			promRemoteWriteType = prometheusCfg.Storage
 		}
-		if err := metrics.InitMetricSend(fmt.Sprintf("%s%s", promSendAddress, prometheusCfg.SendApi), prometheusCfg.SendInterval, promRemoteWriteType); err != nil {
+		if metricSender, err = metrics.InitMetricSend(metricCtx, fmt.Sprintf("%s%s", promSendAddress, prometheusCfg.SendApi), prometheusCfg.SendInterval, promRemoteWriteType); err != nil {
+			stopMetricSend()
+			stopHttpServer()
 			return err
 		}
 	}
 
+	shutdownTimeout := defaultShutdownTimeout
+	if receiverCfg.ShutdownTimeout > 0 {
+		shutdownTimeout = time.Duration(receiverCfg.ShutdownTimeout) * time.Second
+	}
+	shutdown := &gracefulShutdown{
+		timeout:        shutdownTimeout,
+		grpcServer:     grpcServer,
+		reportAnalyzer: reportAnalyzer,
+		cache:          global.CACHE,
+		sink:           storeSink,
+		deadLetters:    deadletter.StoreInstance,
+		metricSender:   metricSender,
+		stopMetricSend: stopMetricSend,
+		stopHttpServer: func() {
+			stopHttpServer()
+			<-httpStopped
+		},
+	}
+	shutdown.waitSignal(ctx)
+
 	// Wait for the two servers shutting down
 	wg.Wait()
 	log.Println("All servers shut down gracefully")
 	return nil
 }
 
-func readInConfig(path string) (*config.ReceiverConfig, *config.SampleConfig, *config.ProfileConfig, *config.PrometheusConfig, *config.ClickHouseConfig, *config.AnalyzerConfig, *config.RedisConfig, *config.K8sConfig, error) {
+func readInConfig(path string) (*config.Config, error) {
 	viper := viper.New()
 	viper.SetConfigFile(path)
 	err := viper.ReadInConfig()
 	if err != nil { // Handle errors reading the config file
-		return nil, nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("error happened while reading config file: %w", err)
+		return nil, fmt.Errorf("error happened while reading config file: %w", err)
 	}
 	receiverCfg := &config.ReceiverConfig{}
 	_ = viper.UnmarshalKey("receiver", receiverCfg)
@@ -167,21 +247,85 @@ func readInConfig(path string) (*config.ReceiverConfig, *config.SampleConfig, *c
 	k8sCfg := &config.K8sConfig{}
 	_ = viper.UnmarshalKey("k8s", k8sCfg)
 
-	return receiverCfg, sampleCfg, profileCfg, prometheusCfg, clickHouseCfg, analyzerCfg, redisCfg, k8sCfg, nil
+	sinkCfg := &config.SinkConfig{}
+	_ = viper.UnmarshalKey("sink", sinkCfg)
+
+	heartbeatCfg := &config.AppHeartbeatConfig{}
+	_ = viper.UnmarshalKey("app_heartbeat", heartbeatCfg)
+
+	otlpCfg := &config.OtlpConfig{}
+	_ = viper.UnmarshalKey("otlp", otlpCfg)
+
+	deadLetterCfg := &config.DeadLetterConfig{}
+	_ = viper.UnmarshalKey("dead_letter", deadLetterCfg)
+
+	admissionCfg := &config.AdmissionConfig{}
+	_ = viper.UnmarshalKey("admission", admissionCfg)
+
+	return &config.Config{
+		ReceiverCfg:   receiverCfg,
+		SampleCfg:     sampleCfg,
+		ProfileCfg:    profileCfg,
+		PrometheusCfg: prometheusCfg,
+		ClickHouseCfg: clickHouseCfg,
+		AnalyzerCfg:   analyzerCfg,
+		RedisCfg:      redisCfg,
+		K8sCfg:        k8sCfg,
+		SinkCfg:       sinkCfg,
+		HeartbeatCfg:  heartbeatCfg,
+		OtlpCfg:       otlpCfg,
+		DeadLetterCfg: deadLetterCfg,
+		AdmissionCfg:  admissionCfg,
+	}, nil
+}
+
+func newTraceClient(analyzerCfg *config.AnalyzerConfig) apmapi.ApmTraceAPI {
+	return client.NewApmTraceClient(
+		analyzerCfg.TraceAddress,
+		analyzerCfg.Timeout,
+		analyzerCfg.RatioThreshold,
+		analyzerCfg.MuateNodeMode,
+		analyzerCfg.GetDetailTypes)
+}
+
+// newSink creates the sinks of targets, the datas are written to all of them.
+// The ClickHouse client is also returned if it is one of the targets.
+func newSink(ctx context.Context, sinkCfg *config.SinkConfig, clickHouseCfg *config.ClickHouseConfig, prometheusCfg *config.PrometheusConfig) (sink.Sink, *clickhouse.ClickHouseClient, error) {
+	targets := sinkCfg.Targets
+	if len(targets) == 0 {
+		targets = []string{sink.TargetClickHouse}
+	}
+
+	var clickHouseClient *clickhouse.ClickHouseClient
+	sinks := make([]sink.Sink, 0, len(targets))
+	for _, target := range targets {
+		switch target {
+		case sink.TargetClickHouse:
+			var err error
+			if clickHouseClient, err = clickhouse.NewClickHouseClient(ctx, clickHouseCfg, prometheusCfg.GenerateClientMetric, prometheusCfg.ClientMetricWithUrl); err != nil {
+				return nil, nil, fmt.Errorf("fail to create ClickHouse client: %w", err)
+			}
+			sinks = append(sinks, clickHouseClient)
+		case sink.TargetNdjson:
+			sinks = append(sinks, sink.NewNdjsonSink(sinkCfg.File))
+		case sink.TargetParquet:
+			sinks = append(sinks, sink.NewParquetSink(sinkCfg.File))
+		default:
+			return nil, nil, fmt.Errorf("unknown sink target %q", target)
+		}
+		log.Printf("Use the sink target %s", target)
+	}
+	return sink.NewFanOutSink(sinks...), clickHouseClient, nil
 }
 
-func startGrpcServer(
+func newGrpcServer(
 	receiverCfg *config.ReceiverConfig,
 	sampleCfg *config.SampleConfig,
 	profileCfg *config.ProfileConfig,
 	analyzerCfg *config.AnalyzerConfig,
 	thresholdCache *threshold.ThresholdCache,
-	promClient v1.API) {
-	listen, err := net.Listen("tcp", ":"+strconv.Itoa(receiverCfg.GrpcPort))
-	if err != nil {
-		log.Fatalf("Fail to listen Grpc Port: %v\n", err)
-	}
-
+	promClient v1.API,
+	admissionController *admission.Controller) (*grpc.Server, *analyzer.ReportAnalyzer) {
 	server := grpc.NewServer()
 
 	sampleServer := trace.NewSampleServer(sampleCfg.Enable, sampleCfg.MinSample, sampleCfg.InitSample, sampleCfg.MaxSample, sampleCfg.ResetSamplePeriod)
@@ -198,9 +342,10 @@ func startGrpcServer(
 
 	analyzer := analyzer.NewReportAnalyzer(analyzerCfg, profileServer.SignalsCache)
 
-	traceServer := trace.NewTraceServer(analyzer)
+	traceServer := trace.NewTraceServer(analyzer, receiverCfg, admissionController)
 	model.RegisterTraceServiceServer(server, traceServer)
 	traceServer.Start()
+	deadletter.StoreInstance.SetRedriveHandler(traceServer.Redrive)
 
 	monitedAppServer := agentmonitor.NewMonitedAppServer()
 	model.RegisterAppServiceServer(server, monitedAppServer)
@@ -211,23 +356,7 @@ func startGrpcServer(
 	agentMonitorReceiver := agentmonitor.NewAgentMonitorServer(receiverCfg.ClusterId, promClient, receiverCfg.DingDingWH)
 	model.RegisterAgentMonitorServiceServer(server, agentMonitorReceiver)
 
-	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-
-		<-c
-		log.Println("Shutting down gRPC server...")
-
-		server.GracefulStop()
-
-		_ = listen.Close()
-		log.Println("gRPC server closed.")
-	}()
-
-	log.Printf("Start Grpc Server: %d", receiverCfg.GrpcPort)
-	if err := server.Serve(listen); err != nil {
-		log.Fatalf("Fail to start server: %v", err)
-	}
+	return server, analyzer
 }
 
 func startMetadataFetch(k8sCfg *config.K8sConfig) {
@@ -242,3 +371,19 @@ func startMetadataFetch(k8sCfg *config.K8sConfig) {
 		log.Printf("Fail to start metadata fetch: %v", err)
 	}
 }
+
+func startK8sEventWatcher(ctx context.Context, k8sCfg *config.K8sConfig, reportAnalyzer *analyzer.ReportAnalyzer) {
+	if k8sCfg.EventWatcher == nil || !k8sCfg.EventWatcher.Enable {
+		return
+	}
+	watcher := k8sevent.NewWatcher(k8sCfg.EventWatcher, reportAnalyzer.StoreK8sEvent)
+	if k8sCfg.Enable && k8sCfg.MetaServerConfig != nil && k8sCfg.MetaServerConfig.KubeSource != nil {
+		watcher.Register(ctx)
+		return
+	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			log.Printf("Fail to start k8s event watcher: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,88 @@
+package receiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/clickhouse"
+	"github.com/CloudDetail/apo-receiver/pkg/global"
+)
+
+// Replay runs `apo-receiver replay -trace-id=... | -start=... [-end=...] -config=...`,
+// which rebuilds the slow / error reports from the span traces stored in ClickHouse.
+// The results are printed to stdout or written to the shadow table replay_report.
+func Replay(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	configPath := flags.String("config", "receiver-config.yml", "Configuration file")
+	traceId := flags.String("trace-id", "", "Trace to replay")
+	start := flags.String("start", "", "Start time(RFC3339) of the window, the traces which have slow or error span are replayed")
+	end := flags.String("end", "", "End time(RFC3339) of the window, now if not set")
+	limit := flags.Int("limit", 100, "Max traces to replay in the window")
+	mutateNodeMode := flags.String("mutate-node-mode", "", "Mode to find the mutated node, analyzer.mutate_node_mode if not set")
+	output := flags.String("output", analyzer.ReplayOutputStdout, "Output of the results, stdout or shadow")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *traceId == "" && *start == "" {
+		return errors.New("either -trace-id or -start must be set")
+	}
+	if *output != analyzer.ReplayOutputStdout && *output != analyzer.ReplayOutputShadow {
+		return fmt.Errorf("unknown output %q", *output)
+	}
+
+	cfg, err := readInConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("fail to read configuration: %w", err)
+	}
+	// Only replay_report is created for the shadow output, the other tables are read only.
+	clickHouseClient, err := clickhouse.NewReplayClient(ctx, cfg.ClickHouseCfg, *output == analyzer.ReplayOutputShadow)
+	if err != nil {
+		return fmt.Errorf("fail to create ClickHouse client: %w", err)
+	}
+	defer clickHouseClient.Close()
+	global.TRACE_CLIENT = newTraceClient(cfg.AnalyzerCfg)
+	replayer := analyzer.NewReplayer(cfg.AnalyzerCfg, clickHouseClient, clickHouseClient)
+
+	var results []*report.ReplayResult
+	if *traceId != "" {
+		results, err = replayer.ReplayTrace(ctx, *traceId, *mutateNodeMode)
+	} else {
+		var startTime, endTime time.Time
+		if startTime, endTime, err = analyzer.ParseReplayWindow(*start, *end); err != nil {
+			return err
+		}
+		results, err = replayer.ReplayTime(ctx, startTime, endTime, *limit, *mutateNodeMode)
+	}
+	if err != nil {
+		return fmt.Errorf("fail to replay: %w", err)
+	}
+
+	if *output == analyzer.ReplayOutputShadow {
+		if err := replayer.Store(ctx, results); err != nil {
+			return fmt.Errorf("fail to store replay results: %w", err)
+		}
+		log.Printf("[Replay] %d results are written to replay_report", len(results))
+		return nil
+	}
+	return printReplayResults(os.Stdout, results)
+}
+
+// printReplayResults prints one result as json per line.
+func printReplayResults(w io.Writer, results []*report.ReplayResult) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
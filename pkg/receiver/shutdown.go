@@ -0,0 +1,89 @@
+package receiver
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/deadletter"
+	"github.com/CloudDetail/apo-receiver/pkg/componment/redis"
+	"github.com/CloudDetail/apo-receiver/pkg/metrics"
+	"github.com/CloudDetail/apo-receiver/pkg/sink"
+)
+
+const (
+	defaultShutdownTimeout = 15 * time.Second
+	finalFlushTimeout      = 10 * time.Second
+)
+
+type gracefulShutdown struct {
+	timeout        time.Duration
+	grpcServer     *grpc.Server
+	reportAnalyzer *analyzer.ReportAnalyzer
+	cache          redis.ExpirableCache
+	sink           sink.Sink
+	deadLetters    *deadletter.Store
+	metricSender   metrics.Sender
+	stopMetricSend context.CancelFunc
+	stopHttpServer func() // returns after the http server is shut down
+}
+
+// waitSignal shuts down when the signal is received or ctx is done.
+func (s *gracefulShutdown) waitSignal(ctx context.Context) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(c)
+	select {
+	case sig := <-c:
+		log.Printf("Receive %s, shutting down...", sig)
+	case <-ctx.Done():
+		log.Printf("Context is done, shutting down...")
+	}
+	s.shutdown()
+}
+
+// shutdown stops ingest, drains the analyzer tasks within timeout and writes the journal of tasks left,
+// flushes the sinks and pushes the metrics for the last time.
+// The http server is stopped with gRPC as it also receives OTLP traces and redrives dead letters,
+// so /metrics is not served while draining and the metrics are only pushed.
+func (s *gracefulShutdown) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	log.Println("Shutting down gRPC server...")
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+	log.Println("gRPC server closed.")
+	s.stopHttpServer()
+
+	s.reportAnalyzer.Stop(ctx)
+	if s.cache != nil {
+		s.cache.Stop()
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), finalFlushTimeout)
+	defer cancelFlush()
+	s.sink.Stop(flushCtx)
+	s.deadLetters.Stop()
+
+	s.stopMetricSend()
+	if s.metricSender != nil {
+		if err := s.metricSender.SendMetrics(flushCtx); err != nil {
+			log.Printf("[x Send Metrics] %s", err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package sink
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	profile_model "github.com/CloudDetail/apo-receiver/pkg/componment/profile/model"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+// FanOutSink writes the datas to every sink in order.
+type FanOutSink struct {
+	sinks []Sink
+}
+
+// NewFanOutSink returns the sink itself if there is only one.
+func NewFanOutSink(sinks ...Sink) Sink {
+	if len(sinks) == 1 {
+		return sinks[0]
+	}
+	return &FanOutSink{sinks: sinks}
+}
+
+func (f *FanOutSink) StoreOnOffMetric(onOffMetric *grpc_model.OnOffMetricGroup) {
+	for _, sink := range f.sinks {
+		sink.StoreOnOffMetric(onOffMetric)
+	}
+}
+
+func (f *FanOutSink) StoreFlameGraph(flameGraph *grpc_model.FlameGraph) {
+	for _, sink := range f.sinks {
+		sink.StoreFlameGraph(flameGraph)
+	}
+}
+
+func (f *FanOutSink) StoreProfilingEvent(profilingEvent *grpc_model.ProfilingEvent) {
+	for _, sink := range f.sinks {
+		sink.StoreProfilingEvent(profilingEvent)
+	}
+}
+
+func (f *FanOutSink) StoreJvmGc(jvmGc *grpc_model.JvmGc) {
+	for _, sink := range f.sinks {
+		sink.StoreJvmGc(jvmGc)
+	}
+}
+
+func (f *FanOutSink) StoreLog(logRecord *grpc_model.LogRecord) {
+	for _, sink := range f.sinks {
+		sink.StoreLog(logRecord)
+	}
+}
+
+func (f *FanOutSink) StoreK8sEvent(k8sEvent *grpc_model.LogRecord) {
+	for _, sink := range f.sinks {
+		sink.StoreK8sEvent(k8sEvent)
+	}
+}
+
+func (f *FanOutSink) StoreRawData(rawData *report.RawData) {
+	for _, sink := range f.sinks {
+		sink.StoreRawData(rawData)
+	}
+}
+
+func (f *FanOutSink) StoreTraceGroup(trace *model.Trace) {
+	for _, sink := range f.sinks {
+		sink.StoreTraceGroup(trace)
+	}
+}
+
+func (f *FanOutSink) StoreNodeReport(nodeReport *report.NodeReport) {
+	for _, sink := range f.sinks {
+		sink.StoreNodeReport(nodeReport)
+	}
+}
+
+func (f *FanOutSink) StoreErrorReport(errorReport *report.ErrorReport) {
+	for _, sink := range f.sinks {
+		sink.StoreErrorReport(errorReport)
+	}
+}
+
+func (f *FanOutSink) StoreReportMetric(reportMetric *profile_model.SlowReportCountMetric) {
+	for _, sink := range f.sinks {
+		sink.StoreReportMetric(reportMetric)
+	}
+}
+
+func (f *FanOutSink) StoreRelation(relation *report.Relation) {
+	for _, sink := range f.sinks {
+		sink.StoreRelation(relation)
+	}
+}
+
+func (f *FanOutSink) StoreAgentEvent(agentEvent *model.AgentEvent) {
+	for _, sink := range f.sinks {
+		sink.StoreAgentEvent(agentEvent)
+	}
+}
+
+func (f *FanOutSink) StoreAppInfo(appInfo *appinfo.AppInfo) {
+	for _, sink := range f.sinks {
+		sink.StoreAppInfo(appInfo)
+	}
+}
+
+func (f *FanOutSink) Start() {
+	for _, sink := range f.sinks {
+		sink.Start()
+	}
+}
+
+func (f *FanOutSink) SyncedTime() time.Time {
+	var syncedTime time.Time
+	for i, sink := range f.sinks {
+		if sinkTime := SyncedTime(sink); i == 0 || sinkTime.Before(syncedTime) {
+			syncedTime = sinkTime
+		}
+	}
+	return syncedTime
+}
+
+func (f *FanOutSink) Stop(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sink := range f.sinks {
+		wg.Add(1)
+		go func(sink Sink) {
+			defer wg.Done()
+			sink.Stop(ctx)
+		}(sink)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,329 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	profile_model "github.com/CloudDetail/apo-receiver/pkg/componment/profile/model"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+const (
+	defaultFileDir          = "data"
+	defaultFileRotateMB     = 128
+	defaultFileRotateSecond = 3600
+	defaultFileFlushSecond  = 5
+
+	inProgressSuffix = ".inprogress"
+)
+
+// recordWriter encodes the records of one file.
+type recordWriter interface {
+	// write returns the bytes of the encoded record.
+	write(record any) (int, error)
+	// flush writes the buffered records to the file if the format supports.
+	flush() error
+	close() error
+}
+
+type fileFormat struct {
+	name      string
+	ext       string
+	newWriter func(w io.Writer) recordWriter
+}
+
+// FileSink writes each table into its own rotating files under <dir>/<table>/,
+// the file being written has suffix .inprogress and is renamed when rotated.
+// The tables are written in parallel, each is locked only by its own writes.
+type FileSink struct {
+	format       *fileFormat
+	dir          string
+	rotateBytes  int64
+	rotatePeriod time.Duration
+	maxFiles     int
+	flushPeriod  time.Duration
+
+	lock   sync.Mutex // guards tables
+	seq    atomic.Uint64
+	tables map[string]*tableFile
+	// synced is the start time of the last flush.
+	synced   atomic.Int64
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// tableFile keeps the file being written of one table.
+type tableFile struct {
+	table string
+	lock  sync.Mutex
+	file  *rotatingFile
+}
+
+type rotatingFile struct {
+	path     string
+	file     *os.File
+	writer   recordWriter
+	openTime time.Time
+	bytes    int64
+}
+
+func newFileSink(format *fileFormat, cfg *config.FileSinkConfig) *FileSink {
+	if cfg == nil {
+		cfg = &config.FileSinkConfig{}
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = defaultFileDir
+	}
+	rotateMB := cfg.RotateMB
+	if rotateMB <= 0 {
+		rotateMB = defaultFileRotateMB
+	}
+	rotateSeconds := cfg.RotateSeconds
+	if rotateSeconds <= 0 {
+		rotateSeconds = defaultFileRotateSecond
+	}
+	flushSeconds := cfg.FlushSeconds
+	if flushSeconds <= 0 {
+		flushSeconds = defaultFileFlushSecond
+	}
+	return &FileSink{
+		format:       format,
+		dir:          dir,
+		rotateBytes:  int64(rotateMB) * 1024 * 1024,
+		rotatePeriod: time.Duration(rotateSeconds) * time.Second,
+		maxFiles:     cfg.MaxFiles,
+		flushPeriod:  time.Duration(flushSeconds) * time.Second,
+		tables:       make(map[string]*tableFile),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func (s *FileSink) StoreOnOffMetric(onOffMetric *grpc_model.OnOffMetricGroup) {
+	s.write(TableOnOffMetric, onOffMetric)
+}
+
+func (s *FileSink) StoreFlameGraph(flameGraph *grpc_model.FlameGraph) {
+	s.write(TableFlameGraph, flameGraph)
+}
+
+func (s *FileSink) StoreProfilingEvent(profilingEvent *grpc_model.ProfilingEvent) {
+	s.write(TableProfilingEvent, profilingEvent)
+}
+
+func (s *FileSink) StoreJvmGc(jvmGc *grpc_model.JvmGc) {
+	s.write(TableJvmGc, jvmGc)
+}
+
+func (s *FileSink) StoreLog(logRecord *grpc_model.LogRecord) {
+	s.write(TableIlogtailLogs, logRecord)
+}
+
+func (s *FileSink) StoreK8sEvent(k8sEvent *grpc_model.LogRecord) {
+	s.write(TableK8sEvents, k8sEvent)
+}
+
+func (s *FileSink) StoreRawData(rawData *report.RawData) {
+	s.write(TableRawDataGroup, rawData)
+}
+
+func (s *FileSink) StoreTraceGroup(trace *model.Trace) {
+	s.write(TableSpanTrace, trace)
+}
+
+func (s *FileSink) StoreNodeReport(nodeReport *report.NodeReport) {
+	s.write(TableSlowReport, nodeReport)
+}
+
+func (s *FileSink) StoreErrorReport(errorReport *report.ErrorReport) {
+	s.write(TableErrorReport, errorReport)
+}
+
+func (s *FileSink) StoreReportMetric(reportMetric *profile_model.SlowReportCountMetric) {
+	s.write(TableReportMetric, reportMetric)
+}
+
+func (s *FileSink) StoreRelation(relation *report.Relation) {
+	s.write(TableServiceRelation, relation)
+}
+
+func (s *FileSink) StoreAgentEvent(agentEvent *model.AgentEvent) {
+	s.write(TableOriginxAgentEvent, agentEvent)
+}
+
+func (s *FileSink) StoreAppInfo(appInfo *appinfo.AppInfo) {
+	s.write(TableOriginxAppInfo, appInfo)
+}
+
+func (s *FileSink) write(table string, record any) {
+	tf := s.getTable(table)
+	tf.lock.Lock()
+	defer tf.lock.Unlock()
+
+	file, err := s.getFile(tf, time.Now())
+	if err != nil {
+		log.Printf("[x Open %s File] %s", s.format.name, err.Error())
+		return
+	}
+	size, err := file.writer.write(record)
+	if err != nil {
+		log.Printf("[x Write %s File] %s: %s", s.format.name, file.path, err.Error())
+		return
+	}
+	file.bytes += int64(size)
+}
+
+func (s *FileSink) getTable(table string) *tableFile {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tf, exist := s.tables[table]
+	if !exist {
+		tf = &tableFile{table: table}
+		s.tables[table] = tf
+	}
+	return tf
+}
+
+func (s *FileSink) listTables() []*tableFile {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tables := make([]*tableFile, 0, len(s.tables))
+	for _, tf := range s.tables {
+		tables = append(tables, tf)
+	}
+	return tables
+}
+
+// getFile returns the file to write the table, the full or expired file is rotated.
+func (s *FileSink) getFile(tf *tableFile, now time.Time) (*rotatingFile, error) {
+	if file := tf.file; file != nil {
+		if file.bytes < s.rotateBytes && now.Sub(file.openTime) < s.rotatePeriod {
+			return file, nil
+		}
+		s.rotate(tf)
+	}
+
+	table := tf.table
+	tableDir := filepath.Join(s.dir, table)
+	if err := os.MkdirAll(tableDir, 0755); err != nil {
+		return nil, err
+	}
+	seq := s.seq.Add(1)
+	path := filepath.Join(tableDir, fmt.Sprintf("%s-%s-%06d%s", table, now.Format("20060102T150405.000"), seq%1000000, s.format.ext))
+	f, err := os.OpenFile(path+inProgressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	file := &rotatingFile{
+		path:     path,
+		file:     f,
+		writer:   s.format.newWriter(f),
+		openTime: now,
+	}
+	tf.file = file
+	return file, nil
+}
+
+// rotate closes the file and removes the oldest files of the table if exceeds maxFiles.
+func (s *FileSink) rotate(tf *tableFile) {
+	table, file := tf.table, tf.file
+	tf.file = nil
+	if err := file.writer.close(); err != nil {
+		log.Printf("[x Close %s File] %s: %s", s.format.name, file.path, err.Error())
+	}
+	if err := file.file.Close(); err != nil {
+		log.Printf("[x Close %s File] %s: %s", s.format.name, file.path, err.Error())
+	}
+	if err := os.Rename(file.path+inProgressSuffix, file.path); err != nil {
+		log.Printf("[x Rotate %s File] %s: %s", s.format.name, file.path, err.Error())
+	}
+
+	if s.maxFiles <= 0 {
+		return
+	}
+	paths, err := filepath.Glob(filepath.Join(s.dir, table, "*"+s.format.ext))
+	if err != nil {
+		return
+	}
+	// Named by the open time, so sorted from the oldest.
+	sort.Strings(paths)
+	for len(paths) > s.maxFiles {
+		if err := os.Remove(paths[0]); err != nil {
+			log.Printf("[x Remove %s File] %s: %s", s.format.name, paths[0], err.Error())
+		}
+		paths = paths[1:]
+	}
+}
+
+func (s *FileSink) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		timer := time.NewTicker(s.flushPeriod)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				s.flush()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// flush writes the buffered records and rotates the expired files which have no new records.
+func (s *FileSink) flush() {
+	startTime := time.Now()
+	for _, tf := range s.listTables() {
+		s.flushTable(tf, time.Now())
+	}
+	s.synced.Store(startTime.UnixNano())
+}
+
+func (s *FileSink) SyncedTime() time.Time {
+	return time.Unix(0, s.synced.Load())
+}
+
+func (s *FileSink) flushTable(tf *tableFile, now time.Time) {
+	tf.lock.Lock()
+	defer tf.lock.Unlock()
+	file := tf.file
+	if file == nil {
+		return
+	}
+	if now.Sub(file.openTime) >= s.rotatePeriod {
+		s.rotate(tf)
+		return
+	}
+	if err := file.writer.flush(); err != nil {
+		log.Printf("[x Flush %s File] %s: %s", s.format.name, file.path, err.Error())
+	}
+}
+
+func (s *FileSink) Stop(ctx context.Context) {
+	close(s.stopChan)
+	s.wg.Wait()
+
+	for _, tf := range s.listTables() {
+		tf.lock.Lock()
+		if tf.file != nil {
+			s.rotate(tf)
+		}
+		tf.lock.Unlock()
+	}
+	log.Printf("[Stop %s Sink] Files are closed under %s", strings.ToUpper(s.format.name), s.dir)
+}
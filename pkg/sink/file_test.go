@@ -0,0 +1,110 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+func TestNdjsonSinkRotate(t *testing.T) {
+	dir := t.TempDir()
+	s := NewNdjsonSink(&config.FileSinkConfig{Dir: dir, MaxFiles: 2})
+	// Rotate after each record.
+	s.rotateBytes = 1
+
+	s.StoreJvmGc(&grpc_model.JvmGc{Pid: "1"})
+	s.StoreJvmGc(&grpc_model.JvmGc{Pid: "2"})
+	s.StoreJvmGc(&grpc_model.JvmGc{Pid: "3"})
+	s.Stop(context.Background())
+
+	paths, _ := filepath.Glob(filepath.Join(dir, TableJvmGc, "*.ndjson"))
+	if len(paths) != 2 {
+		t.Fatalf("want 2 files kept, got %v", paths)
+	}
+	if lines := readLines(t, paths[1]); len(lines) != 1 || lines[0] != `{"pid":"3"}` {
+		t.Errorf("unexpected lines of the newest file: %v", lines)
+	}
+	if inProgress, _ := filepath.Glob(filepath.Join(dir, TableJvmGc, "*"+inProgressSuffix)); len(inProgress) != 0 {
+		t.Errorf("in progress files are left: %v", inProgress)
+	}
+}
+
+func TestParquetSink(t *testing.T) {
+	dir := t.TempDir()
+	s := NewParquetSink(&config.FileSinkConfig{Dir: dir})
+	s.StoreFlameGraph(&grpc_model.FlameGraph{Pid: 1})
+	// The buffered rows are written as a row group.
+	s.flush()
+	s.StoreFlameGraph(&grpc_model.FlameGraph{Pid: 2})
+	s.Stop(context.Background())
+
+	paths, _ := filepath.Glob(filepath.Join(dir, TableFlameGraph, "*.parquet"))
+	if len(paths) != 1 {
+		t.Fatalf("want 1 file, got %v", paths)
+	}
+	f, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stat, _ := f.Stat()
+	file, err := parquet.OpenFile(f, stat.Size())
+	if err != nil {
+		t.Fatalf("read parquet: %v", err)
+	}
+	pid, found := file.Schema().Lookup("pid")
+	if !found {
+		t.Fatalf("pid column is missing: %v", file.Schema().Columns())
+	}
+	receivedAt, _ := file.Schema().Lookup(columnReceivedAt)
+	if len(file.RowGroups()) != 2 {
+		t.Fatalf("want 2 row groups, got %d", len(file.RowGroups()))
+	}
+	for i, rowGroup := range file.RowGroups() {
+		rows := make([]parquet.Row, 2)
+		n, _ := rowGroup.Rows().ReadRows(rows)
+		if n != 1 || rows[0][pid.ColumnIndex].Uint64() != uint64(i+1) || rows[0][receivedAt.ColumnIndex].Int64() == 0 {
+			t.Errorf("unexpected rows of row group %d: %v", i, rows[:n])
+		}
+	}
+}
+
+func TestFanOutSink(t *testing.T) {
+	ndjsonDir, parquetDir := t.TempDir(), t.TempDir()
+	s := NewFanOutSink(
+		NewNdjsonSink(&config.FileSinkConfig{Dir: ndjsonDir}),
+		NewParquetSink(&config.FileSinkConfig{Dir: parquetDir}),
+	)
+	s.Start()
+	s.StoreNodeReport(&report.NodeReport{})
+	s.Stop(context.Background())
+
+	if paths, _ := filepath.Glob(filepath.Join(ndjsonDir, TableSlowReport, "*.ndjson")); len(paths) != 1 {
+		t.Errorf("want 1 ndjson file, got %v", paths)
+	}
+	if paths, _ := filepath.Glob(filepath.Join(parquetDir, TableSlowReport, "*.parquet")); len(paths) != 1 {
+		t.Errorf("want 1 parquet file, got %v", paths)
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
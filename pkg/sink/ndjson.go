@@ -0,0 +1,45 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+var ndjsonFormat = &fileFormat{
+	name: TargetNdjson,
+	ext:  ".ndjson",
+	newWriter: func(w io.Writer) recordWriter {
+		return &ndjsonWriter{writer: bufio.NewWriterSize(w, 256*1024)}
+	},
+}
+
+// NewNdjsonSink writes one json record per line.
+func NewNdjsonSink(cfg *config.FileSinkConfig) *FileSink {
+	return newFileSink(ndjsonFormat, cfg)
+}
+
+type ndjsonWriter struct {
+	writer *bufio.Writer
+}
+
+func (w *ndjsonWriter) write(record any) (int, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.writer.Write(data); err != nil {
+		return 0, err
+	}
+	return len(data) + 1, w.writer.WriteByte('\n')
+}
+
+func (w *ndjsonWriter) flush() error {
+	return w.writer.Flush()
+}
+
+func (w *ndjsonWriter) close() error {
+	return w.writer.Flush()
+}
@@ -0,0 +1,243 @@
+package sink
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/CloudDetail/apo-receiver/pkg/config"
+)
+
+const columnReceivedAt = "received_at"
+
+var parquetFormat = &fileFormat{
+	name: TargetParquet,
+	ext:  ".parquet",
+	newWriter: func(w io.Writer) recordWriter {
+		return &parquetWriter{output: w}
+	},
+}
+
+// NewParquetSink writes the records as parquet, a row group is written for each flush
+// and a file is readable only after it is rotated.
+func NewParquetSink(cfg *config.FileSinkConfig) *FileSink {
+	return newFileSink(parquetFormat, cfg)
+}
+
+// parquetTable maps the top level fields of a record type to columns named by the json tags,
+// the scalar fields are kept as typed columns and the others are stored as json.
+type parquetTable struct {
+	schema     *parquet.Schema
+	columns    []parquetColumn // ordered by the column index
+	receivedAt int
+}
+
+type parquetColumn struct {
+	field   []int // index of the field, embedded structs are flattened as json does
+	kind    reflect.Kind
+	isJson  bool
+	isBytes bool
+}
+
+// parquetTables caches the tables by record type.
+var parquetTables sync.Map // <reflect.Type, *parquetTable>
+
+func parquetTableOf(recordType reflect.Type) *parquetTable {
+	if table, found := parquetTables.Load(recordType); found {
+		return table.(*parquetTable)
+	}
+
+	fields := make(map[string]parquetColumn)
+	group := parquet.Group{
+		columnReceivedAt: parquet.Timestamp(parquet.Millisecond),
+	}
+	collectParquetFields(recordType, nil, fields, group)
+
+	table := &parquetTable{
+		schema: parquet.NewSchema(recordType.Name(), group),
+	}
+	table.columns = make([]parquetColumn, len(table.schema.Columns()))
+	for _, path := range table.schema.Columns() {
+		leaf, _ := table.schema.Lookup(path...)
+		if path[0] == columnReceivedAt {
+			table.receivedAt = leaf.ColumnIndex
+			continue
+		}
+		table.columns[leaf.ColumnIndex] = fields[path[0]]
+	}
+	actual, _ := parquetTables.LoadOrStore(recordType, table)
+	return actual.(*parquetTable)
+}
+
+func collectParquetFields(structType reflect.Type, parent []int, fields map[string]parquetColumn, group parquet.Group) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		index := append(append([]int{}, parent...), i)
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectParquetFields(embedded, index, fields, group)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exist := group[name]; exist {
+			continue
+		}
+
+		column := parquetColumn{field: index, kind: field.Type.Kind()}
+		var node parquet.Node
+		switch column.kind {
+		case reflect.String:
+			node = parquet.String()
+		case reflect.Bool:
+			node = parquet.Leaf(parquet.BooleanType)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			node = parquet.Int(64)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			node = parquet.Uint(64)
+		case reflect.Float32, reflect.Float64:
+			node = parquet.Leaf(parquet.DoubleType)
+		default:
+			if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Uint8 {
+				column.isBytes = true
+				node = parquet.Leaf(parquet.ByteArrayType)
+			} else {
+				column.isJson = true
+				node = parquet.JSON()
+			}
+		}
+		fields[name] = column
+		group[name] = parquet.Compressed(node, &parquet.Zstd)
+	}
+}
+
+// row converts the record to a row of table, returns the row and the bytes of its values.
+func (table *parquetTable) row(record reflect.Value, receivedAt time.Time) (parquet.Row, int, error) {
+	row := make(parquet.Row, len(table.columns))
+	size := 0
+	for i, column := range table.columns {
+		var value parquet.Value
+		if i == table.receivedAt {
+			value = parquet.Int64Value(receivedAt.UnixMilli())
+		} else {
+			field, err := record.FieldByIndexErr(column.field)
+			switch {
+			case err != nil:
+				// Nil embedded struct.
+				value = parquet.ValueOf(zeroOf(column))
+			case column.isJson:
+				content, err := json.Marshal(field.Interface())
+				if err != nil {
+					return nil, 0, err
+				}
+				value = parquet.ByteArrayValue(content)
+			case column.isBytes:
+				value = parquet.ByteArrayValue(field.Bytes())
+			default:
+				value = scalarValue(field, column.kind)
+			}
+		}
+		row[i] = value.Level(0, 0, i)
+		if value.Kind() == parquet.ByteArray {
+			size += len(value.ByteArray())
+		} else {
+			size += 8
+		}
+	}
+	return row, size, nil
+}
+
+func scalarValue(field reflect.Value, kind reflect.Kind) parquet.Value {
+	switch kind {
+	case reflect.String:
+		return parquet.ByteArrayValue([]byte(field.String()))
+	case reflect.Bool:
+		return parquet.BooleanValue(field.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return parquet.Int64Value(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return parquet.Int64Value(int64(field.Uint()))
+	default:
+		return parquet.DoubleValue(field.Float())
+	}
+}
+
+func zeroOf(column parquetColumn) any {
+	switch {
+	case column.isJson:
+		return []byte("null")
+	case column.isBytes:
+		return []byte{}
+	}
+	switch column.kind {
+	case reflect.String:
+		return ""
+	case reflect.Bool:
+		return false
+	case reflect.Float32, reflect.Float64:
+		return float64(0)
+	default:
+		return int64(0)
+	}
+}
+
+// parquetWriter creates the schema by the first record, the buffered rows are written as a row group when flushed.
+type parquetWriter struct {
+	output io.Writer
+	table  *parquetTable
+	writer *parquet.Writer
+}
+
+func (w *parquetWriter) write(record any) (int, error) {
+	value := reflect.ValueOf(record)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return 0, nil
+		}
+		value = value.Elem()
+	}
+	if w.writer == nil {
+		w.table = parquetTableOf(value.Type())
+		w.writer = parquet.NewWriter(w.output, w.table.schema)
+	}
+	row, size, err := w.table.row(value, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.writer.WriteRows([]parquet.Row{row}); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (w *parquetWriter) flush() error {
+	if w.writer == nil {
+		return nil
+	}
+	return w.writer.Flush()
+}
+
+func (w *parquetWriter) close() error {
+	if w.writer == nil {
+		return nil
+	}
+	return w.writer.Close()
+}
@@ -0,0 +1,100 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/CloudDetail/apo-module/model/v1"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/appinfo"
+	"github.com/CloudDetail/apo-receiver/pkg/analyzer/report"
+	profile_model "github.com/CloudDetail/apo-receiver/pkg/componment/profile/model"
+	grpc_model "github.com/CloudDetail/apo-receiver/pkg/model"
+)
+
+const (
+	TargetClickHouse = "clickhouse"
+	TargetNdjson     = "ndjson"
+	TargetParquet    = "parquet"
+
+	TableProfilingEvent    = "profiling_event"
+	TableFlameGraph        = "flame_graph"
+	TableJvmGc             = "jvm_gc"
+	TableOnOffMetric       = "onoff_metric"
+	TableSpanTrace         = "span_trace"
+	TableSlowReport        = "slow_report"
+	TableErrorReport       = "error_report"
+	TableReportMetric      = "report_metric"
+	TableServiceRelation   = "service_relationship"
+	TableOriginxAgentEvent = "originx_agent_event"
+	TableOriginxAppInfo    = "originx_app_info"
+	TableRawDataGroup      = "raw_data_group"
+	TableIlogtailLogs      = "ilogtail_logs"
+	TableK8sEvents         = "k8s_events"
+)
+
+var (
+	ErrNotQueryable  = errors.New("no queryable sink is configured")
+	ErrNoReplayStore = errors.New("no sink is configured to store the replay results")
+)
+
+// Sink stores the datas received from agents and the reports generated by analyzer.
+type Sink interface {
+	StoreOnOffMetric(onOffMetric *grpc_model.OnOffMetricGroup)
+	StoreFlameGraph(flameGraph *grpc_model.FlameGraph)
+	StoreProfilingEvent(profilingEvent *grpc_model.ProfilingEvent)
+	StoreJvmGc(jvmGc *grpc_model.JvmGc)
+	StoreLog(logRecord *grpc_model.LogRecord)
+	// StoreK8sEvent stores the k8s event which is normalized as log.
+	StoreK8sEvent(k8sEvent *grpc_model.LogRecord)
+	// StoreRawData stores the data of unknown data group without parsing.
+	StoreRawData(rawData *report.RawData)
+	StoreTraceGroup(trace *model.Trace)
+	StoreNodeReport(nodeReport *report.NodeReport)
+	StoreErrorReport(errorReport *report.ErrorReport)
+	StoreReportMetric(reportMetric *profile_model.SlowReportCountMetric)
+	StoreRelation(relation *report.Relation)
+	StoreAgentEvent(agentEvent *model.AgentEvent)
+	StoreAppInfo(appInfo *appinfo.AppInfo)
+
+	Start()
+	// Stop writes the cached datas for the last time before ctx is done.
+	Stop(ctx context.Context)
+}
+
+// Syncer is implemented by the sinks which buffer the datas before writing.
+type Syncer interface {
+	// SyncedTime returns the time before which the stored datas are written, or spooled to be written later.
+	SyncedTime() time.Time
+}
+
+// SyncedTime returns now if sink writes the datas at once.
+func SyncedTime(sink Sink) time.Time {
+	if syncer, ok := sink.(Syncer); ok {
+		return syncer.SyncedTime()
+	}
+	return time.Now()
+}
+
+// Querier reads the stored datas, only the sink backed by a database implements it.
+type Querier interface {
+	QueryTraces(ctx context.Context, traceId string) (*model.Traces, error)
+	// QueryTracesByTime queries the traces which have slow or error span in [startTime, endTime).
+	QueryTracesByTime(ctx context.Context, startTime time.Time, endTime time.Time, limit int) ([]*model.Traces, error)
+}
+
+// ReplayStore writes the replayed reports to the shadow table, which is separated from the production reports.
+type ReplayStore interface {
+	StoreReplayResults(ctx context.Context, results []*report.ReplayResult) error
+}
+
+// NoopQuerier is used when all the sinks are write only, eg. running without ClickHouse.
+type NoopQuerier struct{}
+
+func (NoopQuerier) QueryTraces(ctx context.Context, traceId string) (*model.Traces, error) {
+	return nil, ErrNotQueryable
+}
+
+func (NoopQuerier) QueryTracesByTime(ctx context.Context, startTime time.Time, endTime time.Time, limit int) ([]*model.Traces, error) {
+	return nil, ErrNotQueryable
+}
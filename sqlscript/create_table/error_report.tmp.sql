@@ -6,6 +6,7 @@ CREATE TABLE IF NOT EXISTS error_report{{if .Cluster}}_local ON CLUSTER {{.Clust
     duration UInt64 CODEC(ZSTD(1)),
     end_time UInt64 CODEC(ZSTD(1)),
     drop_reason String CODEC(ZSTD(1)),
+    drop_reason_code LowCardinality(String) CODEC(ZSTD(1)),
     labels Map(LowCardinality(String), String) CODEC(ZSTD(1)),
     cause String CODEC(ZSTD(1)),
     cause_message String CODEC(ZSTD(1)),
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS originx_app_heartbeat{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}}
+(
+  node_ip LowCardinality(String) CODEC(ZSTD(1)),
+  node_name LowCardinality(String) CODEC(ZSTD(1)),
+  host_pid UInt32,
+  start_time UInt64,
+  heart_time UInt64,
+  heart_flag UInt32,
+  update_time UInt64
+) ENGINE {{if .Replication}}ReplicatedReplacingMergeTree{{else}}ReplacingMergeTree{{end}}(update_time)
+    ORDER BY (node_ip, node_name, host_pid, start_time)
+    TTL toDateTime(heart_time) + toIntervalDay({{.TTLDay}})
+    SETTINGS index_granularity=8192
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS raw_data_group{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}}
+(
+    timestamp DateTime64(3) CODEC(Delta, ZSTD(1)),
+    data_group LowCardinality(String) CODEC(ZSTD(1)),
+    source LowCardinality(String) CODEC(ZSTD(1)),
+    data String CODEC(ZSTD(1))
+) ENGINE {{if .Replication}}ReplicatedMergeTree{{else}}MergeTree(){{end}}
+    PARTITION BY toDate(timestamp)
+    ORDER BY (data_group, toUnixTimestamp(timestamp))
+    TTL toDateTime(timestamp) + toIntervalDay({{.TTLDay}})
+    SETTINGS index_granularity=8192, ttl_only_drop_parts = 1
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS replay_report{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}}
+(
+    timestamp DateTime64(9) CODEC(Delta, ZSTD(1)),
+    trace_id String CODEC(ZSTD(1)),
+    report_type LowCardinality(String) CODEC(ZSTD(1)),
+    mutate_node_mode LowCardinality(String) CODEC(ZSTD(1)),
+    is_drop Bool,
+    retry Bool,
+    drop_reason String CODEC(ZSTD(1)),
+    drop_reason_code LowCardinality(String) CODEC(ZSTD(1)),
+    node_reports String CODEC(ZSTD(1)),
+    error_reports String CODEC(ZSTD(1)),
+    relations String CODEC(ZSTD(1)),
+    INDEX idx_trace_id trace_id TYPE bloom_filter(0.01) GRANULARITY 1
+) ENGINE {{if .Replication}}ReplicatedMergeTree{{else}}MergeTree(){{end}}
+    PARTITION BY toDate(timestamp)
+    ORDER BY (toUnixTimestamp(timestamp), trace_id)
+    TTL toDateTime(timestamp) + toIntervalDay({{.TTLDay}})
+    SETTINGS index_granularity=8192, ttl_only_drop_parts = 1
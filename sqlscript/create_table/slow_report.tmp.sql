@@ -6,6 +6,7 @@ CREATE TABLE IF NOT EXISTS slow_report{{if .Cluster}}_local ON CLUSTER {{.Cluste
     duration UInt64 CODEC(ZSTD(1)),
     end_time UInt64 CODEC(ZSTD(1)),
     drop_reason String CODEC(ZSTD(1)),
+    drop_reason_code LowCardinality(String) CODEC(ZSTD(1)),
     cause String CODEC(ZSTD(1)),
     relation_trees String CODEC(ZSTD(1)),
     otel_client_calls String CODEC(ZSTD(1)),
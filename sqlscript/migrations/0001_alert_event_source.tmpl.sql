@@ -0,0 +1,11 @@
+-- 1.3.0
+ALTER TABLE alert_event{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `alert_id` String CODEC(ZSTD(1));
+ALTER TABLE alert_event{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `raw_tags` Map(LowCardinality(String), String) CODEC(ZSTD(1));
+ALTER TABLE alert_event{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `source_id` LowCardinality(String) CODEC(ZSTD(1));
+ALTER TABLE alert_event{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} MODIFY COLUMN IF EXISTS `tags` Map(LowCardinality(String), String) CODEC(ZSTD(1));
+{{if .Cluster}}
+DROP TABLE IF EXISTS alert_event ON CLUSTER {{.Cluster}};
+CREATE TABLE IF NOT EXISTS alert_event
+    ON CLUSTER {{.Cluster}} AS {{.Database}}.alert_event_local
+ENGINE = Distributed('{{.Cluster}}', '{{.Database}}', 'alert_event_local', cityHash64(alert_id));
+{{end}}
@@ -0,0 +1,8 @@
+-- 1.5.0
+ALTER TABLE workflow_records{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `rounded_time` DateTime64(3);
+{{if .Cluster}}
+DROP TABLE IF EXISTS workflow_records ON CLUSTER {{.Cluster}};
+CREATE TABLE IF NOT EXISTS workflow_records
+    ON CLUSTER {{.Cluster}} AS {{.Database}}.workflow_records_local
+ENGINE = Distributed('{{.Cluster}}', '{{.Database}}', 'workflow_records_local', cityHash64(ref));
+{{end}}
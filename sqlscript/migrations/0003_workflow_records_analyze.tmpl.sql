@@ -0,0 +1,10 @@
+-- 1.9.0
+ALTER TABLE workflow_records{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `alert_direction` String;
+ALTER TABLE workflow_records{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `analyze_run_id` String;
+ALTER TABLE workflow_records{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `analyze_err` String;
+{{if .Cluster}}
+DROP TABLE IF EXISTS workflow_records ON CLUSTER {{.Cluster}};
+CREATE TABLE IF NOT EXISTS workflow_records
+    ON CLUSTER {{.Cluster}} AS {{.Database}}.workflow_records_local
+ENGINE = Distributed('{{.Cluster}}', '{{.Database}}', 'workflow_records_local', cityHash64(ref));
+{{end}}
@@ -0,0 +1,8 @@
+-- 1.11.0
+ALTER TABLE alert_event{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `event_id` String DEFAULT toString(id);
+{{if .Cluster}}
+DROP TABLE IF EXISTS alert_event ON CLUSTER {{.Cluster}};
+CREATE TABLE IF NOT EXISTS alert_event
+    ON CLUSTER {{.Cluster}} AS {{.Database}}.alert_event_local
+ENGINE = Distributed('{{.Cluster}}', '{{.Database}}', 'alert_event_local', cityHash64(alert_id));
+{{end}}
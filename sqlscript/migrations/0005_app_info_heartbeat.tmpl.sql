@@ -0,0 +1,5 @@
+-- 1.11.1
+ALTER TABLE originx_app_info{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} REMOVE TTL;
+ALTER TABLE originx_app_info{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `heart_time` UInt64;
+ALTER TABLE originx_app_info{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `heart_flag` UInt32;
+ALTER TABLE originx_app_info{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} DELETE WHERE heart_time=0;
@@ -0,0 +1,7 @@
+-- 1.12.0
+ALTER TABLE slow_report{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `drop_reason_code` LowCardinality(String) CODEC(ZSTD(1)) AFTER drop_reason;
+ALTER TABLE error_report{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}} ADD COLUMN IF NOT EXISTS `drop_reason_code` LowCardinality(String) CODEC(ZSTD(1)) AFTER drop_reason;
+{{if .Cluster}}
+ALTER TABLE slow_report ON CLUSTER {{.Cluster}} ADD COLUMN IF NOT EXISTS `drop_reason_code` LowCardinality(String) CODEC(ZSTD(1)) AFTER drop_reason;
+ALTER TABLE error_report ON CLUSTER {{.Cluster}} ADD COLUMN IF NOT EXISTS `drop_reason_code` LowCardinality(String) CODEC(ZSTD(1)) AFTER drop_reason;
+{{end}}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS schema_migrations{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}}
+(
+  version UInt32,
+  name String,
+  checksum String,
+  applied_at DateTime64(3),
+  duration_ms UInt64,
+  hostname LowCardinality(String)
+) ENGINE {{if .Replication}}ReplicatedMergeTree{{else}}MergeTree(){{end}}
+    ORDER BY (version, applied_at)
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS schema_migrations_lock{{if .Cluster}}_local ON CLUSTER {{.Cluster}}{{end}}
+(
+  owner String,
+  acquired_at DateTime64(9),
+  expire_at DateTime64(9)
+) ENGINE {{if .Replication}}ReplicatedMergeTree{{else}}MergeTree(){{end}}
+    ORDER BY (acquired_at, owner)
+    TTL toDateTime(expire_at) + toIntervalDay(1)
@@ -0,0 +1,8 @@
+// Package sqlscript embeds the sql templates to create and migrate the clickhouse tables,
+// so the receiver does not depend on its working directory.
+package sqlscript
+
+import "embed"
+
+//go:embed create_table/*.sql migrations/*.sql *.sql
+var Scripts embed.FS